@@ -20,6 +20,7 @@ import (
 	"github.com/jaypipes/ghw/pkg/option"
 	"github.com/jaypipes/ghw/pkg/pci"
 	pciaddress "github.com/jaypipes/ghw/pkg/pci/address"
+	"github.com/jaypipes/ghw/pkg/pmem"
 	"github.com/jaypipes/ghw/pkg/product"
 	"github.com/jaypipes/ghw/pkg/topology"
 	"github.com/jaypipes/ghw/pkg/tpm"
@@ -34,14 +35,20 @@ var (
 	// DEPRECATED: Please use WithLogger
 	WithAlerter = option.WithAlerter
 	// DEPRECATED: Please use WithDisableWarnings
-	WithNullAlerter     = option.WithNullAlerter
-	WithDisableWarnings = config.WithDisableWarnings
-	WithDisableTools    = config.WithDisableTools
-	WithDisableTopology = config.WithDisableTopology
-	WithPathOverrides   = config.WithPathOverrides
-	WithLogLevel        = config.WithLogLevel
-	WithDebug           = config.WithDebug
-	WithLogger          = config.WithLogger
+	WithNullAlerter           = option.WithNullAlerter
+	WithDisableWarnings       = config.WithDisableWarnings
+	WithDisableTools          = config.WithDisableTools
+	WithDisableTopology       = config.WithDisableTopology
+	WithEnableDiskHealth      = config.WithEnableDiskHealth
+	WithSnapshotNetAddressing = config.WithSnapshotNetAddressing
+	WithIncludeBlockDevices   = config.WithIncludeBlockDevices
+	WithExcludeBlockDevices   = config.WithExcludeBlockDevices
+	WithPathOverrides         = config.WithPathOverrides
+	WithPCIDB                 = config.WithPCIDB
+	WithCgroup                = config.WithCgroup
+	WithLogLevel              = config.WithLogLevel
+	WithDebug                 = config.WithDebug
+	WithLogger                = config.WithLogger
 )
 
 type Modifier = config.Modifier
@@ -83,9 +90,45 @@ var (
 type BlockInfo = block.Info
 type Disk = block.Disk
 type Partition = block.Partition
+type NVMeController = block.NVMeController
+type NVMeNamespace = block.NVMeNamespace
+type NVMeNamespacePath = block.NVMeNamespacePath
+type DeviceMapper = block.DeviceMapper
+type MDRaid = block.MDRaid
+type MDRaidMember = block.MDRaidMember
+type Mount = block.Mount
+type FilesystemUsage = block.FilesystemUsage
+type PartitionTable = block.PartitionTable
+type PartitionTableEntry = block.PartitionTableEntry
+type DiskQueue = block.DiskQueue
+type DiskHealth = block.DiskHealth
+type SCSIAddress = block.SCSIAddress
+type SCSIDevice = block.SCSIDevice
+type SCSIHost = block.SCSIHost
+type SASExpander = block.SASExpander
+type Enclosure = block.Enclosure
+type EnclosureSlot = block.EnclosureSlot
+type BlockDeviceClass = block.DeviceClass
+type BlockDeviceClassification = block.Classification
+
+const (
+	BlockDeviceLoop      = block.DeviceClassLoop
+	BlockDeviceRAM       = block.DeviceClassRAM
+	BlockDeviceZRAM      = block.DeviceClassZRAM
+	BlockDeviceDM        = block.DeviceClassDM
+	BlockDeviceMD        = block.DeviceClassMD
+	BlockDeviceRemovable = block.DeviceClassRemovable
+	BlockDeviceZeroSize  = block.DeviceClassZeroSize
+)
+
+type BlockStatsInfo = block.Stats
+type BlockDeviceStats = block.DeviceStats
+type BlockDeviceRates = block.DeviceRates
 
 var (
-	Block = block.New
+	Block      = block.New
+	BlockStats = block.NewStats
+	BlockRates = block.Rates
 )
 
 type DriveType = block.DriveType
@@ -106,6 +149,11 @@ const (
 	DriveTypeSSD   = block.DriveTypeSSD
 	// DEPRECATED: Please use DriveTypeSSD
 	DRIVE_TYPE_SSD = block.DRIVE_TYPE_SSD
+
+	DriveTypeVirtual = block.DriveTypeVirtual
+	DriveTypeSMR     = block.DriveTypeSMR
+	DriveTypeZNS     = block.DriveTypeZNS
+	DriveTypeRAM     = block.DriveTypeRAM
 )
 
 type StorageController = block.StorageController
@@ -129,14 +177,102 @@ const (
 	StorageControllerMMC      = block.StorageControllerMMC
 	// DEPRECATED: Please use StorageControllerMMC
 	STORAGE_CONTROLLER_MMC = block.STORAGE_CONTROLLER_MMC
+
+	StorageControllerLoop         = block.StorageControllerLoop
+	StorageControllerUSB          = block.StorageControllerUSB
+	StorageControllerSATA         = block.StorageControllerSATA
+	StorageControllerSAS          = block.StorageControllerSAS
+	StorageControllerXen          = block.StorageControllerXen
+	StorageControllerISCSI        = block.StorageControllerISCSI
+	StorageControllerNBD          = block.StorageControllerNBD
+	StorageControllerRBD          = block.StorageControllerRBD
+	StorageControllerZRAM         = block.StorageControllerZRAM
+	StorageControllerUBLK         = block.StorageControllerUBLK
+	StorageControllerRAM          = block.StorageControllerRAM
+	StorageControllerDeviceMapper = block.StorageControllerDeviceMapper
+	StorageControllerMD           = block.StorageControllerMD
+)
+
+type NVMeTransport = block.NVMeTransport
+
+const (
+	NVMeTransportUnknown = block.NVMeTransportUnknown
+	NVMeTransportPCIe    = block.NVMeTransportPCIe
+	NVMeTransportTCP     = block.NVMeTransportTCP
+	NVMeTransportRDMA    = block.NVMeTransportRDMA
+	NVMeTransportFC      = block.NVMeTransportFC
+	NVMeTransportLoop    = block.NVMeTransportLoop
+)
+
+type DeviceMapperKind = block.DeviceMapperKind
+
+const (
+	DeviceMapperKindUnknown   = block.DeviceMapperKindUnknown
+	DeviceMapperKindLVM       = block.DeviceMapperKindLVM
+	DeviceMapperKindLUKS      = block.DeviceMapperKindLUKS
+	DeviceMapperKindCrypt     = block.DeviceMapperKindCrypt
+	DeviceMapperKindMultipath = block.DeviceMapperKindMultipath
+	DeviceMapperKindPartition = block.DeviceMapperKindPartition
+)
+
+type PartitionTableType = block.PartitionTableType
+
+const (
+	PartitionTableTypeUnknown = block.PartitionTableTypeUnknown
+	PartitionTableTypeGPT     = block.PartitionTableTypeGPT
+	PartitionTableTypeMBR     = block.PartitionTableTypeMBR
+)
+
+type ZonedModel = block.ZonedModel
+
+const (
+	ZonedModelNone        = block.ZonedModelNone
+	ZonedModelHostAware   = block.ZonedModelHostAware
+	ZonedModelHostManaged = block.ZonedModelHostManaged
+)
+
+type HealthStatus = block.HealthStatus
+
+const (
+	HealthStatusUnknown = block.HealthStatusUnknown
+	HealthStatusPassed  = block.HealthStatusPassed
+	HealthStatusFailed  = block.HealthStatusFailed
 )
 
 type NetworkInfo = net.Info
 type NIC = net.NIC
 type NICCapability = net.NICCapability
+type NICAddress = net.NICAddress
+type NICSRIOV = net.NICSRIOV
+type NICVirtualFunction = net.NICVirtualFunction
+type NICBond = net.NICBond
+type NICBridge = net.NICBridge
+type NICVLAN = net.NICVLAN
+type NICVXLAN = net.NICVXLAN
+type NICKind = net.NICKind
+type NetworkStatsInfo = net.Stats
+type NICStats = net.NICStats
+type NICRates = net.NICRates
+
+const (
+	NICKindBond      = net.NICKindBond
+	NICKindBridge    = net.NICKindBridge
+	NICKindVLAN      = net.NICKindVLAN
+	NICKindMACVLAN   = net.NICKindMACVLAN
+	NICKindIPVLAN    = net.NICKindIPVLAN
+	NICKindVXLAN     = net.NICKindVXLAN
+	NICKindVeth      = net.NICKindVeth
+	NICKindTun       = net.NICKindTun
+	NICKindTap       = net.NICKindTap
+	NICKindTeam      = net.NICKindTeam
+	NICKindWireGuard = net.NICKindWireGuard
+	NICKindDummy     = net.NICKindDummy
+)
 
 var (
-	Network = net.New
+	Network      = net.New
+	NetworkStats = net.NewStats
+	NetworkRates = net.Rates
 )
 
 type BIOSInfo = bios.Info
@@ -159,6 +295,10 @@ var (
 
 type TopologyInfo = topology.Info
 type TopologyNode = topology.Node
+type TopologyNodeMemoryStats = topology.NodeMemoryStats
+type TopologyNodeNUMAStats = topology.NodeNUMAStats
+type TopologyNodeMemoryAccess = topology.NodeMemoryAccess
+type TopologyMemoryTier = topology.MemoryTier
 
 var (
 	Topology = topology.New
@@ -178,6 +318,8 @@ const (
 type PCIInfo = pci.Info
 type PCIAddress = pciaddress.Address
 type PCIDevice = pci.Device
+type PCISRIOV = pci.SRIOV
+type PCIVirtualFunction = pci.VirtualFunction
 
 var (
 	PCI                  = pci.New
@@ -216,3 +358,17 @@ type TPMDevice = tpm.Device
 var (
 	TPM = tpm.New
 )
+
+type PMEMInfo = pmem.Info
+type PMEMRegion = pmem.Region
+type PMEMNamespace = pmem.Namespace
+type PMEMNamespaceMode = pmem.NamespaceMode
+type NVDIMM = pmem.NVDIMM
+type CXLMemoryDevice = pmem.CXLMemoryDevice
+type CXLPort = pmem.CXLPort
+type CXLPortType = pmem.CXLPortType
+type CXLDecoder = pmem.CXLDecoder
+
+var (
+	PMEM = pmem.New
+)
@@ -37,6 +37,18 @@ func showBlock(cmd *cobra.Command, args []string) error {
 				fmt.Printf("  %v\n", part)
 			}
 		}
+		for _, ctrl := range block.NVMeControllers {
+			fmt.Printf(" %v\n", ctrl)
+		}
+		for _, host := range block.SCSIHosts {
+			fmt.Printf(" %v\n", host)
+		}
+		for _, enc := range block.Enclosures {
+			fmt.Printf(" %v\n", enc)
+			for _, slot := range enc.Slots {
+				fmt.Printf("  %v\n", slot)
+			}
+		}
 	case outputFormatJSON:
 		fmt.Printf("%s\n", block.JSONString(pretty))
 	case outputFormatYAML:
@@ -0,0 +1,53 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jaypipes/ghw"
+	"github.com/spf13/cobra"
+)
+
+// pmemCmd represents the `pmem` command
+var pmemCmd = &cobra.Command{
+	Use:   "pmem",
+	Short: "Show persistent memory and CXL memory device information for the host system",
+	RunE:  showPMEM,
+}
+
+// showPMEM shows persistent memory and CXL memory device information for the host system.
+func showPMEM(cmd *cobra.Command, args []string) error {
+	pmem, err := ghw.PMEM(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("error getting pmem info: %w", err)
+	}
+
+	switch outputFormat {
+	case outputFormatHuman:
+		fmt.Printf("%v\n", pmem)
+
+		for _, region := range pmem.Regions {
+			fmt.Printf(" %v\n", region)
+			for _, ns := range region.Namespaces {
+				fmt.Printf("  %v\n", ns)
+			}
+		}
+		for _, dev := range pmem.CXLMemoryDevices {
+			fmt.Printf(" %v\n", dev)
+		}
+	case outputFormatJSON:
+		fmt.Printf("%s\n", pmem.JSONString(pretty))
+	case outputFormatYAML:
+		fmt.Printf("%s", pmem.YAMLString())
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(pmemCmd)
+}
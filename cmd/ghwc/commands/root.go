@@ -107,6 +107,7 @@ func showAll(cmd *cobra.Command, args []string) error {
 			showUSB,
 			showWatchdog,
 			showTPM,
+			showPMEM,
 		} {
 			err := f(cmd, args)
 			if err != nil {
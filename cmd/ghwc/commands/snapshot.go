@@ -15,6 +15,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/jaypipes/ghw/internal/config"
 	"github.com/jaypipes/ghw/internal/log"
 	"github.com/jaypipes/ghw/pkg/snapshot"
 )
@@ -22,6 +23,8 @@ import (
 var (
 	// output filepath to save snapshot to
 	outPath string
+	// whether to include the IP addresses and routes of the host
+	netAddressing bool
 )
 
 var snapshotCmd = &cobra.Command{
@@ -33,6 +36,9 @@ var snapshotCmd = &cobra.Command{
 // doSnapshot creates a ghw snapshot
 func doSnapshot(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+	if netAddressing {
+		ctx = config.WithSnapshotNetAddressing()(ctx)
+	}
 	scratchDir, err := os.MkdirTemp("", "ghw-snapshot")
 	if err != nil {
 		return err
@@ -86,5 +92,11 @@ func init() {
 		outPath,
 		"Path to place snapshot. Defaults to file in current directory with name $OS-$ARCH-$HASHSYSTEMNAME.tar.gz",
 	)
+	snapshotCmd.PersistentFlags().BoolVar(
+		&netAddressing,
+		"net-addressing",
+		false,
+		"Include the IP addresses and routes of the host, which identify it, in the snapshot",
+	)
 	rootCmd.AddCommand(snapshotCmd)
 }
@@ -21,7 +21,9 @@ import (
 	"github.com/jaypipes/ghw/pkg/memory"
 	"github.com/jaypipes/ghw/pkg/net"
 	"github.com/jaypipes/ghw/pkg/pci"
+	"github.com/jaypipes/ghw/pkg/pmem"
 	"github.com/jaypipes/ghw/pkg/product"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/topology"
 	"github.com/jaypipes/ghw/pkg/tpm"
 	"github.com/jaypipes/ghw/pkg/usb"
@@ -46,12 +48,14 @@ type HostInfo struct {
 	USB         *usb.Info         `json:"usb"`
 	Watchdog    *watchdog.Info    `json:"watchdog"`
 	TPM         *tpm.Info         `json:"tpm"`
+	PMEM        *pmem.Info        `json:"pmem"`
 }
 
 // Host returns a pointer to a HostInfo struct that contains fields with
 // information about the host system's CPU, memory, network devices, etc
 func Host(args ...any) (*HostInfo, error) {
-	ctx := config.ContextFromArgs(args...)
+	// the SMBIOS tables are decoded once for all the packages reading them
+	ctx := smbios.WithCache(config.ContextFromArgs(args...))
 	memInfo, err := memory.New(ctx)
 	if err != nil {
 		return nil, err
@@ -112,6 +116,10 @@ func Host(args ...any) (*HostInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	pmemInfo, err := pmem.New(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return &HostInfo{
 		CPU:         cpuInfo,
@@ -129,6 +137,7 @@ func Host(args ...any) (*HostInfo, error) {
 		USB:         usbInfo,
 		Watchdog:    watchdogInfo,
 		TPM:         tpmInfo,
+		PMEM:        pmemInfo,
 	}, nil
 }
 
@@ -136,7 +145,7 @@ func Host(args ...any) (*HostInfo, error) {
 // structs' String-ified output
 func (info *HostInfo) String() string {
 	return fmt.Sprintf(
-		"%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n",
+		"%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n",
 		info.Block.String(),
 		info.CPU.String(),
 		info.GPU.String(),
@@ -152,6 +161,7 @@ func (info *HostInfo) String() string {
 		info.USB.String(),
 		info.Watchdog.String(),
 		info.TPM.String(),
+		info.PMEM.String(),
 	)
 }
 
@@ -9,6 +9,7 @@ package config
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/jaypipes/ghw/pkg/option"
 	"github.com/jaypipes/pcidb"
@@ -19,6 +20,9 @@ const (
 	envKeyDisableWarnings = "GHW_DISABLE_WARNINGS"
 	envKeyDisableTools    = "GHW_DISABLE_TOOLS"
 	envKeyDisableTopology = "GHW_DISABLE_TOPOLOGY"
+	envKeyEnableHealth    = "GHW_ENABLE_DISK_HEALTH"
+	envKeyBlockInclude    = "GHW_BLOCK_INCLUDE"
+	envKeyBlockExclude    = "GHW_BLOCK_EXCLUDE"
 )
 
 type Key string
@@ -30,8 +34,13 @@ var (
 	toolsEnabledKey        = Key("ghw.tools.enabled")
 	defaultTopologyEnabled = true
 	topologyEnabledKey     = Key("ghw.topology.enabled")
+	defaultHealthEnabled   = false
+	healthEnabledKey       = Key("ghw.health.enabled")
+	snapshotAddressingKey  = Key("ghw.snapshot.addressing")
+	blockFilterKey         = Key("ghw.block.filter")
 	pcidbKey               = Key("ghw.pcidb")
 	pathOverridesKey       = Key("ghw.path.overrides")
+	cgroupKey              = Key("ghw.cgroup")
 )
 
 // Modifier sets some value on the context
@@ -127,6 +136,148 @@ func TopologyEnabled(ctx context.Context) bool {
 	return defaultTopologyEnabled
 }
 
+// WithEnableDiskHealth makes ghw query the health and SMART data of disks.
+// This requires opening the disk devices and sending them commands, which
+// usually needs root privileges, hence it is disabled by default.
+func WithEnableDiskHealth() Modifier {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, healthEnabledKey, true)
+	}
+}
+
+// EnvOrDefaultEnableDiskHealth returns true if the GHW_ENABLE_DISK_HEALTH
+// environs variable is set to any value.
+func EnvOrDefaultEnableDiskHealth() bool {
+	if _, exists := os.LookupEnv(envKeyEnableHealth); exists {
+		return true
+	}
+	return defaultHealthEnabled
+}
+
+// DiskHealthEnabled returns true if the collection of disk health data is
+// enabled.
+func DiskHealthEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return defaultHealthEnabled
+	}
+	if v := ctx.Value(healthEnabledKey); v != nil {
+		return v.(bool)
+	}
+	return defaultHealthEnabled
+}
+
+// WithSnapshotNetAddressing makes snapshots include the IP addresses and
+// routes of the host, found in /proc/net, so that the addresses and default
+// routes of the NICs can be read from the snapshot. They are left out by
+// default, as they identify the host.
+func WithSnapshotNetAddressing() Modifier {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, snapshotAddressingKey, true)
+	}
+}
+
+// SnapshotNetAddressing returns true if snapshots include the IP addresses
+// and routes of the host.
+func SnapshotNetAddressing(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if v := ctx.Value(snapshotAddressingKey); v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// BlockDeviceClass is a class of block devices that the block device
+// enumeration can be told to include or exclude
+type BlockDeviceClass string
+
+const (
+	// BlockDeviceLoop is the class of loop devices
+	BlockDeviceLoop BlockDeviceClass = "loop"
+	// BlockDeviceRAM is the class of RAM disks of the brd driver
+	BlockDeviceRAM BlockDeviceClass = "ram"
+	// BlockDeviceZRAM is the class of compressed RAM block devices
+	BlockDeviceZRAM BlockDeviceClass = "zram"
+	// BlockDeviceDM is the class of device-mapper devices, e.g. LVM logical
+	// volumes
+	BlockDeviceDM BlockDeviceClass = "dm"
+	// BlockDeviceMD is the class of software RAID (MD) arrays
+	BlockDeviceMD BlockDeviceClass = "md"
+	// BlockDeviceRemovable is the class of devices with removable media
+	BlockDeviceRemovable BlockDeviceClass = "removable"
+	// BlockDeviceZeroSize is the class of devices with a size of zero, e.g.
+	// card readers without a card or unused loop devices
+	BlockDeviceZeroSize BlockDeviceClass = "zero-size"
+)
+
+// withBlockDevices returns a Modifier recording whether the block devices of
+// the supplied classes are included, on top of the choices already recorded
+func withBlockDevices(include bool, classes []BlockDeviceClass) Modifier {
+	return func(ctx context.Context) context.Context {
+		filter := map[BlockDeviceClass]bool{}
+		for class, included := range BlockDeviceFilter(ctx) {
+			filter[class] = included
+		}
+		for _, class := range classes {
+			filter[class] = include
+		}
+		return context.WithValue(ctx, blockFilterKey, filter)
+	}
+}
+
+// WithIncludeBlockDevices makes the block device enumeration report the
+// devices of the supplied classes, including those skipped by default, i.e.
+// the unused loop devices. A later WithExcludeBlockDevices for the same class
+// overrides it.
+func WithIncludeBlockDevices(classes ...BlockDeviceClass) Modifier {
+	return withBlockDevices(true, classes)
+}
+
+// WithExcludeBlockDevices makes the block device enumeration skip the devices
+// belonging to any of the supplied classes. A later WithIncludeBlockDevices
+// for the same class overrides it.
+func WithExcludeBlockDevices(classes ...BlockDeviceClass) Modifier {
+	return withBlockDevices(false, classes)
+}
+
+// BlockDeviceFilter returns the classes of block devices explicitly included
+// (true) or excluded (false) in the supplied context. Classes absent from the
+// returned map get the default treatment.
+func BlockDeviceFilter(ctx context.Context) map[BlockDeviceClass]bool {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(blockFilterKey); v != nil {
+		return v.(map[BlockDeviceClass]bool)
+	}
+	return nil
+}
+
+// envBlockDeviceClasses returns the comma-separated classes of block devices
+// in the supplied environs variable
+func envBlockDeviceClasses(key string) []BlockDeviceClass {
+	classes := []BlockDeviceClass{}
+	for _, class := range strings.Split(os.Getenv(key), ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			classes = append(classes, BlockDeviceClass(strings.ToLower(class)))
+		}
+	}
+	return classes
+}
+
+// EnvOrDefaultIncludeBlockDevices returns the classes of block devices listed
+// in the GHW_BLOCK_INCLUDE environs variable, e.g. "loop,removable"
+func EnvOrDefaultIncludeBlockDevices() []BlockDeviceClass {
+	return envBlockDeviceClasses(envKeyBlockInclude)
+}
+
+// EnvOrDefaultExcludeBlockDevices returns the classes of block devices listed
+// in the GHW_BLOCK_EXCLUDE environs variable, e.g. "dm,zero-size"
+func EnvOrDefaultExcludeBlockDevices() []BlockDeviceClass {
+	return envBlockDeviceClasses(envKeyBlockExclude)
+}
+
 // WithPCIDB allows you to provide a custom instance of the PCI database
 // (pcidb.PCIDB) to ghw. This is useful if you want to use a preloaded or
 // specially configured PCI database, such as one created with custom
@@ -167,6 +318,27 @@ func PathOverrides(ctx context.Context) map[string]string {
 	return nil
 }
 
+// WithCgroup selects the cgroup whose cpuset ghw reports, as a path relative
+// to the root of the cgroup hierarchy, e.g. "/kubepods.slice/pod1234". By
+// default ghw reports the cpuset of the calling process.
+func WithCgroup(path string) Modifier {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, cgroupKey, path)
+	}
+}
+
+// Cgroup returns the cgroup path set in the supplied context, or an empty
+// string if none is set, meaning the cgroup of the calling process.
+func Cgroup(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v := ctx.Value(cgroupKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
 // ContextFromEnv returns a new context.Context populated from the environs or
 // default option values
 func ContextFromEnv() context.Context {
@@ -180,6 +352,15 @@ func ContextFromEnv() context.Context {
 	if disableWarn {
 		ctx = WithDisableWarnings()(ctx)
 	}
+	if EnvOrDefaultEnableDiskHealth() {
+		ctx = WithEnableDiskHealth()(ctx)
+	}
+	if classes := EnvOrDefaultIncludeBlockDevices(); len(classes) > 0 {
+		ctx = WithIncludeBlockDevices(classes...)(ctx)
+	}
+	if classes := EnvOrDefaultExcludeBlockDevices(); len(classes) > 0 {
+		ctx = WithExcludeBlockDevices(classes...)(ctx)
+	}
 	useLogfmt := EnvOrDefaultLogLogfmt()
 	if useLogfmt {
 		ctx = WithLogLogfmt()(ctx)
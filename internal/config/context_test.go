@@ -67,3 +67,50 @@ func TestContextFromArgsOldStyleOptionChrootOverridesEnv(t *testing.T) {
 		t.Fatalf("Expected old-style option.WithChroot to override env, got %q", got)
 	}
 }
+
+// TestDiskHealthEnabled ensures that the collection of disk health data is
+// disabled unless requested with GHW_ENABLE_DISK_HEALTH or the modifier.
+func TestDiskHealthEnabled(t *testing.T) {
+	if config.DiskHealthEnabled(config.ContextFromArgs()) {
+		t.Fatalf("Expected disk health collection to be disabled by default")
+	}
+	if !config.DiskHealthEnabled(config.ContextFromArgs(config.WithEnableDiskHealth())) {
+		t.Fatalf("Expected WithEnableDiskHealth modifier to be applied")
+	}
+
+	t.Setenv("GHW_ENABLE_DISK_HEALTH", "1")
+	if !config.DiskHealthEnabled(config.ContextFromArgs()) {
+		t.Fatalf("Expected GHW_ENABLE_DISK_HEALTH to enable disk health collection")
+	}
+}
+
+// TestBlockDeviceFilter ensures that the block device classes included and
+// excluded by the environs and the modifiers are merged, the last choice for a
+// class winning.
+func TestBlockDeviceFilter(t *testing.T) {
+	if filter := config.BlockDeviceFilter(config.ContextFromArgs()); len(filter) != 0 {
+		t.Fatalf("Expected no block device filter by default, but got %v", filter)
+	}
+
+	t.Setenv("GHW_BLOCK_INCLUDE", "loop, Removable")
+	t.Setenv("GHW_BLOCK_EXCLUDE", "dm")
+	ctx := config.ContextFromArgs(
+		config.WithExcludeBlockDevices(config.BlockDeviceRemovable, config.BlockDeviceZeroSize),
+		config.WithIncludeBlockDevices(config.BlockDeviceDM),
+	)
+	expected := map[config.BlockDeviceClass]bool{
+		config.BlockDeviceLoop:      true,
+		config.BlockDeviceRemovable: false,
+		config.BlockDeviceZeroSize:  false,
+		config.BlockDeviceDM:        true,
+	}
+	filter := config.BlockDeviceFilter(ctx)
+	if len(filter) != len(expected) {
+		t.Fatalf("Expected block device filter %v, but got %v", expected, filter)
+	}
+	for class, included := range expected {
+		if got, ok := filter[class]; !ok || got != included {
+			t.Fatalf("Expected block device filter %v, but got %v", expected, filter)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+// Package testutil contains helpers shared by the tests of the ghw packages.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// WriteFiles creates the supplied files below root, keyed by their path
+// relative to root, along with their parent directories.
+func WriteFiles(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// WriteSysfsFiles is WriteFiles for sysfs attributes, whose contents are
+// terminated by a newline, as the kernel prints them
+func WriteSysfsFiles(t testing.TB, root string, attrs map[string]string) {
+	t.Helper()
+	files := make(map[string]string, len(attrs))
+	for name, content := range attrs {
+		files[name] = content + "\n"
+	}
+	WriteFiles(t, root, files)
+}
@@ -10,10 +10,16 @@ import (
 
 	"github.com/jaypipes/ghw/pkg/linuxdmi"
 	"github.com/jaypipes/ghw/pkg/linuxdt"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
 func (i *Info) load(ctx context.Context) error {
+	if table, err := smbios.Load(ctx); err == nil {
+		if boards := table.Baseboards(); len(boards) > 0 {
+			return i.loadSMBIOS(boards[0])
+		}
+	}
 	if !linuxdmi.Available(ctx) && linuxdt.Available(ctx) {
 		return i.loadDeviceTree(ctx)
 	}
@@ -27,6 +33,19 @@ func (i *Info) load(ctx context.Context) error {
 	return nil
 }
 
+// loadSMBIOS populates baseboard information from the first decoded SMBIOS
+// Baseboard Information structure. Systems with several boards list the main
+// board first.
+func (i *Info) loadSMBIOS(board *smbios.Baseboard) error {
+	i.AssetTag = util.StringOrUnknown(board.AssetTag)
+	i.SerialNumber = util.StringOrUnknown(board.SerialNumber)
+	i.Vendor = util.StringOrUnknown(board.Manufacturer)
+	i.Version = util.StringOrUnknown(board.Version)
+	i.Product = util.StringOrUnknown(board.Product)
+
+	return nil
+}
+
 // loadDeviceTree populates baseboard information from the DeviceTree on systems
 // without DMI/SMBIOS. The DeviceTree carries the model, vendor and serial
 // number, so the asset tag and version stay unknown.
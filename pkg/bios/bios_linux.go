@@ -10,10 +10,16 @@ import (
 
 	"github.com/jaypipes/ghw/pkg/linuxdmi"
 	"github.com/jaypipes/ghw/pkg/linuxdt"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
 func (i *Info) load(ctx context.Context) error {
+	if table, err := smbios.Load(ctx); err == nil {
+		if bios := table.BIOS(); bios != nil {
+			return i.loadSMBIOS(bios)
+		}
+	}
 	if !linuxdmi.Available(ctx) && linuxdt.Available(ctx) {
 		return i.loadDeviceTree(ctx)
 	}
@@ -25,6 +31,16 @@ func (i *Info) load(ctx context.Context) error {
 	return nil
 }
 
+// loadSMBIOS populates BIOS information from the decoded SMBIOS BIOS
+// Information structure.
+func (i *Info) loadSMBIOS(bios *smbios.BIOS) error {
+	i.Vendor = util.StringOrUnknown(bios.Vendor)
+	i.Version = util.StringOrUnknown(bios.Version)
+	i.Date = util.StringOrUnknown(bios.ReleaseDate)
+
+	return nil
+}
+
 // loadDeviceTree populates BIOS/firmware information from the DeviceTree on
 // systems without DMI/SMBIOS. U-Boot exposes its version under the "chosen"
 // node; when present we report it as a "U-Boot" firmware. The DeviceTree has no
@@ -35,6 +35,15 @@ const (
 	DriveTypeSSD
 	// DriveTypeVirtual indicates a virtual drive i.e. loop devices
 	DriveTypeVirtual
+	// DriveTypeSMR indicates a shingled magnetic recording (SMR) hard disk
+	// drive exposing its zones, either host-managed or host-aware
+	DriveTypeSMR
+	// DriveTypeZNS indicates a zoned solid-state drive, e.g. an NVMe Zoned
+	// Namespace (ZNS)
+	DriveTypeZNS
+	// DriveTypeRAM indicates a drive backed by memory, e.g. zram or brd
+	// ramdisks
+	DriveTypeRAM
 )
 
 const (
@@ -60,6 +69,9 @@ var (
 		DriveTypeODD:     "ODD",
 		DriveTypeSSD:     "SSD",
 		DriveTypeVirtual: "virtual",
+		DriveTypeSMR:     "SMR",
+		DriveTypeZNS:     "ZNS",
+		DriveTypeRAM:     "RAM",
 	}
 
 	// NOTE(fromani): the keys are all lowercase and do not match
@@ -74,6 +86,9 @@ var (
 		"odd":     DriveTypeODD,
 		"ssd":     DriveTypeSSD,
 		"virtual": DriveTypeVirtual,
+		"smr":     DriveTypeSMR,
+		"zns":     DriveTypeZNS,
+		"ram":     DriveTypeRAM,
 	}
 )
 
@@ -130,6 +145,37 @@ const (
 	StorageControllerMMC
 	// StorageControllerLoop indicates a loopback storage controller
 	StorageControllerLoop
+	// StorageControllerUSB indicates a USB mass storage device, using either
+	// the usb-storage or the UAS driver
+	StorageControllerUSB
+	// StorageControllerSATA indicates a disk attached to a SATA (or PATA)
+	// controller handled by libata, e.g. an AHCI controller
+	StorageControllerSATA
+	// StorageControllerSAS indicates a disk attached to a Serial Attached SCSI
+	// (SAS) host bus adapter, including SATA disks behind one
+	StorageControllerSAS
+	// StorageControllerXen indicates a Xen paravirtualized block device
+	// (blkfront)
+	StorageControllerXen
+	// StorageControllerISCSI indicates a SCSI disk reached over the network
+	// through an iSCSI session
+	StorageControllerISCSI
+	// StorageControllerNBD indicates a network block device (NBD)
+	StorageControllerNBD
+	// StorageControllerRBD indicates a Ceph RADOS block device (RBD)
+	StorageControllerRBD
+	// StorageControllerZRAM indicates a compressed RAM block device (zram)
+	StorageControllerZRAM
+	// StorageControllerUBLK indicates a block device served by a userspace
+	// process through the ublk driver
+	StorageControllerUBLK
+	// StorageControllerRAM indicates a RAM disk of the brd driver
+	StorageControllerRAM
+	// StorageControllerDeviceMapper indicates a device-mapper device, e.g.
+	// an LVM logical volume
+	StorageControllerDeviceMapper
+	// StorageControllerMD indicates a Linux software RAID (MD) array
+	StorageControllerMD
 )
 
 const (
@@ -158,6 +204,19 @@ var (
 		StorageControllerVirtIO:  "virtio",
 		StorageControllerMMC:     "MMC",
 		StorageControllerLoop:    "loop",
+
+		StorageControllerUSB:          "USB",
+		StorageControllerSATA:         "SATA",
+		StorageControllerSAS:          "SAS",
+		StorageControllerXen:          "Xen",
+		StorageControllerISCSI:        "iSCSI",
+		StorageControllerNBD:          "NBD",
+		StorageControllerRBD:          "RBD",
+		StorageControllerZRAM:         "zram",
+		StorageControllerUBLK:         "ublk",
+		StorageControllerRAM:          "RAM",
+		StorageControllerDeviceMapper: "device-mapper",
+		StorageControllerMD:           "MD",
 	}
 
 	// NOTE(fromani): the keys are all lowercase and do not match
@@ -173,6 +232,19 @@ var (
 		"virtio":  StorageControllerVirtIO,
 		"mmc":     StorageControllerMMC,
 		"loop":    StorageControllerLoop,
+
+		"usb":           StorageControllerUSB,
+		"sata":          StorageControllerSATA,
+		"sas":           StorageControllerSAS,
+		"xen":           StorageControllerXen,
+		"iscsi":         StorageControllerISCSI,
+		"nbd":           StorageControllerNBD,
+		"rbd":           StorageControllerRBD,
+		"zram":          StorageControllerZRAM,
+		"ublk":          StorageControllerUBLK,
+		"ram":           StorageControllerRAM,
+		"device-mapper": StorageControllerDeviceMapper,
+		"md":            StorageControllerMD,
 	}
 )
 
@@ -201,6 +273,30 @@ func (sc StorageController) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.Quote(strings.ToLower(sc.String()))), nil
 }
 
+// DeviceClass is a class of block devices, e.g. loop devices or devices with
+// removable media, that can be included or excluded from the disks reported
+// with WithIncludeBlockDevices and WithExcludeBlockDevices
+type DeviceClass = config.BlockDeviceClass
+
+const (
+	DeviceClassLoop      = config.BlockDeviceLoop
+	DeviceClassRAM       = config.BlockDeviceRAM
+	DeviceClassZRAM      = config.BlockDeviceZRAM
+	DeviceClassDM        = config.BlockDeviceDM
+	DeviceClassMD        = config.BlockDeviceMD
+	DeviceClassRemovable = config.BlockDeviceRemovable
+	DeviceClassZeroSize  = config.BlockDeviceZeroSize
+)
+
+// Classification records that a disk belongs to a class of block devices
+type Classification struct {
+	// Class is the class of block devices the disk belongs to
+	Class DeviceClass `json:"class"`
+	// Reason describes what put the disk in the class, e.g.
+	// `size is 0 bytes`
+	Reason string `json:"reason"`
+}
+
 // Disk describes a single disk drive on the host system. Disk drives provide
 // raw block storage resources.
 type Disk struct {
@@ -242,6 +338,44 @@ type Disk struct {
 	// Partitions contains an array of pointers to `Partition` structs, one for
 	// each partition on the disk.
 	Partitions []*Partition `json:"partitions"`
+	// NVMe contains the NVMe namespace information of the disk when the disk
+	// is an NVMe namespace.
+	NVMe *NVMeNamespace `json:"nvme,omitempty"`
+	// Holders contains the names of the devices built on top of the disk,
+	// e.g. the `dm-0` device of an LVM logical volume using the disk as a
+	// physical volume.
+	Holders []string `json:"holders,omitempty"`
+	// Slaves contains the names of the disks or partitions the disk is built
+	// on top of, e.g. the members of an MD RAID array.
+	Slaves []string `json:"slaves,omitempty"`
+	// DeviceMapper contains the device-mapper information of the disk when
+	// the disk is a device-mapper device.
+	DeviceMapper *DeviceMapper `json:"device_mapper,omitempty"`
+	// MDRaid contains the MD RAID array information of the disk when the disk
+	// is an MD RAID array.
+	MDRaid *MDRaid `json:"md_raid,omitempty"`
+	// Mounts contains an array of pointers to `Mount` structs, one for each
+	// mount of a filesystem housed directly on the disk, e.g. the filesystem
+	// of an LVM logical volume.
+	Mounts []*Mount `json:"mounts,omitempty"`
+	// Usage contains the capacity usage of the filesystem housed directly on
+	// the disk when it is mounted.
+	Usage *FilesystemUsage `json:"usage,omitempty"`
+	// PartitionTable contains the partition table of the disk as decoded
+	// from the disk itself, when the disk can be read.
+	PartitionTable *PartitionTable `json:"partition_table,omitempty"`
+	// Queue contains the request queue settings and limits of the disk.
+	Queue *DiskQueue `json:"queue,omitempty"`
+	// Health contains the health and SMART data the disk reports, when
+	// enabled with WithEnableDiskHealth and the disk can be queried.
+	Health *DiskHealth `json:"health,omitempty"`
+	// SCSI contains the SCSI address of SCSI disks, including SATA and SAS
+	// disks, and where they sit in the SAS topology and enclosures.
+	SCSI *SCSIDevice `json:"scsi,omitempty"`
+	// Classifications contains the classes of block devices the disk belongs
+	// to, e.g. `loop` or `removable`, along with why. These are the classes
+	// the WithIncludeBlockDevices and WithExcludeBlockDevices filters match.
+	Classifications []*Classification `json:"classifications,omitempty"`
 	// TODO(jaypipes): Add PCI field for accessing PCI device information
 	// PCI *PCIDevice `json:"pci"`
 }
@@ -255,7 +389,9 @@ type Partition struct {
 	// Label is the human-readable label given to the partition. On Linux, this
 	// is derived from the `ID_PART_ENTRY_NAME` udev entry.
 	Label string `json:"label"`
-	// MountPoint is the path where this partition is mounted.
+	// MountPoint is the path where this partition is mounted. When the
+	// partition is mounted several times, e.g. with bind mounts, this is the
+	// first of its Mounts.
 	MountPoint string `json:"mount_point"`
 	// SizeBytes contains the total amount of storage, in bytes, this partition
 	// can consume.
@@ -271,6 +407,50 @@ type Partition struct {
 	// FilesystemLabel is the label of the filesystem contained on the
 	// partition. On Linux, this is derived from the `ID_FS_NAME` udev entry.
 	FilesystemLabel string `json:"filesystem_label"`
+	// Holders contains the names of the devices built on top of the
+	// partition, e.g. the `md0` MD RAID array the partition is a member of.
+	Holders []string `json:"holders,omitempty"`
+	// Mounts contains an array of pointers to `Mount` structs, one for each
+	// mount of the partition's filesystem.
+	Mounts []*Mount `json:"mounts,omitempty"`
+	// Usage contains the capacity usage of the partition's filesystem when it
+	// is mounted.
+	Usage *FilesystemUsage `json:"usage,omitempty"`
+	// TableEntry contains the entry of the partition in the partition table
+	// of its disk, when the disk can be read.
+	TableEntry *PartitionTableEntry `json:"table_entry,omitempty"`
+}
+
+// Mount describes one mount of a filesystem.
+type Mount struct {
+	// Path is the path the filesystem is mounted at.
+	Path string `json:"path"`
+	// FilesystemType is the type of the mounted filesystem, e.g. `ext4`.
+	FilesystemType string `json:"filesystem_type"`
+	// Options contains the mount options, e.g. `rw` or `noatime`.
+	Options []string `json:"options"`
+	// IsReadOnly indicates if the filesystem is mounted read-only.
+	IsReadOnly bool `json:"read_only"`
+}
+
+// FilesystemUsage describes the capacity usage of a mounted filesystem, like
+// reported by `df`.
+type FilesystemUsage struct {
+	// TotalBytes is the size of the filesystem.
+	TotalBytes uint64 `json:"total_bytes"`
+	// UsedBytes is the amount of storage used in the filesystem.
+	UsedBytes uint64 `json:"used_bytes"`
+	// AvailableBytes is the amount of storage available to unprivileged
+	// users. It excludes the blocks the filesystem reserves for the root
+	// user, so it is usually less than TotalBytes minus UsedBytes.
+	AvailableBytes uint64 `json:"available_bytes"`
+	// TotalInodes is the number of inodes of the filesystem, or 0 for
+	// filesystems allocating inodes dynamically.
+	TotalInodes uint64 `json:"total_inodes"`
+	// UsedInodes is the number of inodes in use.
+	UsedInodes uint64 `json:"used_inodes"`
+	// FreeInodes is the number of free inodes.
+	FreeInodes uint64 `json:"free_inodes"`
 }
 
 // Info describes all disk drives and partitions in the host system.
@@ -286,6 +466,18 @@ type Info struct {
 	// Partitions contains an array of pointers to `Partition` structs, one for
 	// each partition on any disk drive on the host system.
 	Partitions []*Partition `json:"-"`
+	// NVMeControllers contains an array of pointers to `NVMeController`
+	// structs, one for each NVMe controller on the host system.
+	NVMeControllers []*NVMeController `json:"nvme_controllers,omitempty"`
+	// SCSIHosts contains an array of pointers to `SCSIHost` structs, one for
+	// each SCSI host (HBA) on the host system.
+	SCSIHosts []*SCSIHost `json:"scsi_hosts,omitempty"`
+	// SASExpanders contains an array of pointers to `SASExpander` structs,
+	// one for each SAS expander on the host system.
+	SASExpanders []*SASExpander `json:"sas_expanders,omitempty"`
+	// Enclosures contains an array of pointers to `Enclosure` structs, one
+	// for each storage enclosure managed through SCSI Enclosure Services.
+	Enclosures []*Enclosure `json:"enclosures,omitempty"`
 }
 
 // New returns a pointer to an Info struct that describes the block storage
@@ -0,0 +1,306 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HealthStatus is the overall health self-assessment of a disk
+type HealthStatus int
+
+const (
+	// HealthStatusUnknown means the disk did not report its health
+	// self-assessment
+	HealthStatusUnknown HealthStatus = iota
+	// HealthStatusPassed means the disk reports no failure, nor predicts any
+	HealthStatusPassed
+	// HealthStatusFailed means the disk reports a failure or predicts one,
+	// e.g. because a SMART attribute crossed its threshold or the spare
+	// capacity of an NVMe device fell below its threshold
+	HealthStatusFailed
+)
+
+var (
+	healthStatusString = map[HealthStatus]string{
+		HealthStatusUnknown: "Unknown",
+		HealthStatusPassed:  "passed",
+		HealthStatusFailed:  "failed",
+	}
+
+	// used by HealthStatus::UnmarshalJSON, hence the lowercase keys
+	stringHealthStatus = map[string]HealthStatus{
+		"unknown": HealthStatusUnknown,
+		"passed":  HealthStatusPassed,
+		"failed":  HealthStatusFailed,
+	}
+)
+
+func (s HealthStatus) String() string {
+	return healthStatusString[s]
+}
+
+func (s HealthStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(s.String()))), nil
+}
+
+func (s *HealthStatus) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	key := strings.ToLower(str)
+	val, ok := stringHealthStatus[key]
+	if !ok {
+		return fmt.Errorf("unknown health status: %q", key)
+	}
+	*s = val
+	return nil
+}
+
+// DiskHealth describes the health of a disk as reported by the disk itself,
+// through the SMART/Health Information log page of NVMe devices, the SMART
+// attributes of ATA devices or the log pages of SCSI devices. Not all
+// protocols report all the fields, a field that was not reported is zero.
+type DiskHealth struct {
+	// Protocol is the protocol the health data was read with, one of `nvme`,
+	// `ata` or `scsi`
+	Protocol string `json:"protocol"`
+	// Status is the overall health self-assessment of the disk
+	Status HealthStatus `json:"status"`
+	// TemperatureCelsius is the current temperature of the disk
+	TemperatureCelsius int `json:"temperature_celsius,omitempty"`
+	// PowerOnHours is the number of hours the disk has been powered on
+	PowerOnHours uint64 `json:"power_on_hours,omitempty"`
+	// ReallocatedSectors is the number of sectors remapped to spare ones
+	// because they could not be written or read reliably anymore, from the
+	// Reallocated Sectors Count attribute of ATA devices or the grown defect
+	// list of SCSI devices
+	ReallocatedSectors uint64 `json:"reallocated_sectors,omitempty"`
+	// PercentageUsed is the estimate of the life of the device used so far,
+	// which may exceed 100. It is only reported by NVMe devices, SCSI solid
+	// state devices and the ATA solid state devices having one of the common
+	// wear-out attributes.
+	PercentageUsed int `json:"percentage_used,omitempty"`
+	// MediaErrors is the number of unrecovered data integrity errors, from
+	// the Media and Data Integrity Errors of NVMe devices, the Reported
+	// Uncorrectable Errors attribute of ATA devices or the uncorrected read
+	// and write errors of SCSI devices
+	MediaErrors uint64 `json:"media_errors,omitempty"`
+}
+
+const (
+	nvmeSMARTLogSize = 512
+	// the temperature bit of the critical warning of the NVMe SMART/Health
+	// Information log page only reports a transient condition
+	nvmeCriticalWarningTemperature = 0x02
+)
+
+// parseNVMeSMARTLog decodes the SMART/Health Information log page (02h) of an
+// NVMe device. The counters of the page are 128-bit, only their low 64 bits
+// are kept.
+func parseNVMeSMARTLog(b []byte) (*DiskHealth, error) {
+	if len(b) < nvmeSMARTLogSize {
+		return nil, fmt.Errorf("short NVMe SMART log page: %d bytes", len(b))
+	}
+	h := &DiskHealth{
+		Protocol:       "nvme",
+		Status:         HealthStatusPassed,
+		PercentageUsed: int(b[5]),
+		PowerOnHours:   binary.LittleEndian.Uint64(b[128:136]),
+		MediaErrors:    binary.LittleEndian.Uint64(b[160:168]),
+	}
+	if b[0]&^nvmeCriticalWarningTemperature != 0 {
+		h.Status = HealthStatusFailed
+	}
+	// the composite temperature is in Kelvin
+	if k := binary.LittleEndian.Uint16(b[1:3]); k != 0 {
+		h.TemperatureCelsius = int(k) - 273
+	}
+	return h, nil
+}
+
+const (
+	ataSMARTDataSize      = 512
+	ataSMARTNumAttributes = 30
+	ataSMARTAttributeSize = 12
+
+	ataAttrReallocatedSectors = 5
+	ataAttrPowerOnHours       = 9
+	ataAttrWearLevelingCount  = 177
+	ataAttrReportedUncorrect  = 187
+	ataAttrAirflowTemperature = 190
+	ataAttrTemperature        = 194
+	ataAttrSSDLifeLeft        = 231
+	ataAttrMediaWearout       = 233
+)
+
+// parseATASMARTData decodes the attributes of the data returned by the SMART
+// READ DATA command of an ATA device. The raw values of most attributes are
+// vendor specific, the ones used here are the commonly agreed upon ones.
+func parseATASMARTData(b []byte) (*DiskHealth, error) {
+	if len(b) < ataSMARTDataSize {
+		return nil, fmt.Errorf("short ATA SMART data: %d bytes", len(b))
+	}
+	h := &DiskHealth{Protocol: "ata"}
+	airflowTemp := 0
+	for i := 0; i < ataSMARTNumAttributes; i++ {
+		attr := b[2+i*ataSMARTAttributeSize : 2+(i+1)*ataSMARTAttributeSize]
+		// the normalized value goes from 100 or more when new down to the
+		// threshold of the attribute
+		value := int(attr[3])
+		var raw uint64
+		for j := 10; j >= 5; j-- {
+			raw = raw<<8 | uint64(attr[j])
+		}
+		switch attr[0] {
+		case ataAttrReallocatedSectors:
+			h.ReallocatedSectors = raw
+		case ataAttrPowerOnHours:
+			// some vendors store the minutes in the upper bytes
+			h.PowerOnHours = raw & 0xffffffff
+		case ataAttrReportedUncorrect:
+			h.MediaErrors = raw & 0xffffffff
+		case ataAttrTemperature:
+			h.TemperatureCelsius = int(attr[5])
+		case ataAttrAirflowTemperature:
+			airflowTemp = int(attr[5])
+		case ataAttrWearLevelingCount, ataAttrSSDLifeLeft, ataAttrMediaWearout:
+			if value <= 100 {
+				h.PercentageUsed = 100 - value
+			}
+		}
+	}
+	if h.TemperatureCelsius == 0 {
+		h.TemperatureCelsius = airflowTemp
+	}
+	return h, nil
+}
+
+// parseATASMARTStatus returns the health status an ATA device reported to the
+// SMART RETURN STATUS command, through the LBA Mid and LBA High registers
+// returned in the sense data of the ATA PASS-THROUGH command, in either the
+// descriptor or the fixed format.
+func parseATASMARTStatus(sense []byte) HealthStatus {
+	var mid, high byte
+	switch {
+	case len(sense) >= 8 && (sense[0]&0x7f == 0x72 || sense[0]&0x7f == 0x73):
+		end := min(8+int(sense[7]), len(sense))
+		for off := 8; off+2 <= end; off += 2 + int(sense[off+1]) {
+			// ATA Status Return descriptor
+			if sense[off] == 0x09 && off+14 <= end {
+				mid, high = sense[off+9], sense[off+11]
+				break
+			}
+		}
+	case len(sense) >= 12 && (sense[0]&0x7f == 0x70 || sense[0]&0x7f == 0x71):
+		mid, high = sense[10], sense[11]
+	}
+	switch {
+	case mid == 0x4f && high == 0xc2:
+		return HealthStatusPassed
+	case mid == 0xf4 && high == 0x2c:
+		return HealthStatusFailed
+	}
+	return HealthStatusUnknown
+}
+
+const (
+	scsiLogPageWriteErrors      = 0x02
+	scsiLogPageReadErrors       = 0x03
+	scsiLogPageTemperature      = 0x0d
+	scsiLogPageSolidStateMedia  = 0x11
+	scsiLogPageBackgroundScan   = 0x15
+	scsiLogPageInfoExceptions   = 0x2f
+	scsiParamUncorrectedErrors  = 0x0006
+	scsiParamPercentageUsed     = 0x0001
+	scsiTemperatureNotAvailable = 0xff
+)
+
+// scsiLogPages lists the SCSI log pages parseSCSILogPages uses
+var scsiLogPages = []byte{
+	scsiLogPageWriteErrors,
+	scsiLogPageReadErrors,
+	scsiLogPageTemperature,
+	scsiLogPageSolidStateMedia,
+	scsiLogPageBackgroundScan,
+	scsiLogPageInfoExceptions,
+}
+
+// scsiLogParameters returns the values of the parameters of a SCSI log page,
+// keyed by parameter code
+func scsiLogParameters(page []byte) map[uint16][]byte {
+	params := map[uint16][]byte{}
+	if len(page) < 4 {
+		return params
+	}
+	end := min(4+int(binary.BigEndian.Uint16(page[2:4])), len(page))
+	for off := 4; off+4 <= end; {
+		code := binary.BigEndian.Uint16(page[off : off+2])
+		size := int(page[off+3])
+		if off+4+size > end {
+			break
+		}
+		params[code] = page[off+4 : off+4+size]
+		off += 4 + size
+	}
+	return params
+}
+
+// scsiCounter decodes a big-endian counter of any size up to 8 bytes
+func scsiCounter(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// parseSCSILogPages decodes the health of a SCSI device from the log pages it
+// returned, keyed by page code, and the number of entries of its grown defect
+// list
+func parseSCSILogPages(pages map[byte][]byte, grownDefects uint64) *DiskHealth {
+	h := &DiskHealth{
+		Protocol:           "scsi",
+		ReallocatedSectors: grownDefects,
+	}
+	if page, ok := pages[scsiLogPageInfoExceptions]; ok {
+		h.Status = HealthStatusPassed
+		if p := scsiLogParameters(page)[0]; len(p) >= 3 {
+			// a non-zero additional sense code, usually 5Dh, reports a
+			// predicted failure
+			if p[0] != 0 {
+				h.Status = HealthStatusFailed
+			}
+			if p[2] != scsiTemperatureNotAvailable {
+				h.TemperatureCelsius = int(p[2])
+			}
+		}
+	}
+	if p := scsiLogParameters(pages[scsiLogPageTemperature])[0]; len(p) >= 2 {
+		if p[1] != scsiTemperatureNotAvailable {
+			h.TemperatureCelsius = int(p[1])
+		}
+	}
+	for _, code := range []byte{scsiLogPageReadErrors, scsiLogPageWriteErrors} {
+		p := scsiLogParameters(pages[code])[scsiParamUncorrectedErrors]
+		h.MediaErrors += scsiCounter(p)
+	}
+	if p := scsiLogParameters(pages[scsiLogPageSolidStateMedia])[scsiParamPercentageUsed]; len(p) >= 4 {
+		h.PercentageUsed = int(p[3])
+	}
+	// the first parameter of the background scan results page holds the
+	// accumulated power on minutes
+	if p := scsiLogParameters(pages[scsiLogPageBackgroundScan])[0]; len(p) >= 4 {
+		h.PowerOnHours = uint64(binary.BigEndian.Uint32(p[0:4])) / 60
+	}
+	return h
+}
@@ -0,0 +1,265 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+const (
+	// _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeIoctlAdminCmd   = 0xc0484e41
+	nvmeAdminGetLogPage = 0x02
+	nvmeLogSMART        = 0x02
+	nvmeNSIDAll         = 0xffffffff
+
+	sgIO           = 0x2285
+	sgDxferNone    = -1
+	sgDxferFromDev = -3
+	sgTimeoutMs    = 10000
+
+	scsiStatusGood           = 0x00
+	scsiStatusCheckCondition = 0x02
+	scsiLogSense             = 0x4d
+	scsiReadDefectData10     = 0x37
+	scsiLogSenseSize         = 1024
+
+	ataPassThrough16 = 0x85
+	ataSMART         = 0xb0
+	ataSMARTReadData = 0xd0
+	ataSMARTStatus   = 0xda
+)
+
+// nvmeAdminCmd mirrors struct nvme_admin_cmd of linux/nvme_ioctl.h
+type nvmeAdminCmd struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        unsafe.Pointer
+	_           [8 - unsafe.Sizeof(uintptr(0))]byte // addr is 64-bit on 32-bit platforms too
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+// sgIOHdr mirrors struct sg_io_hdr of scsi/sg.h
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	iovecCount     uint16
+	dxferLen       uint32
+	dxferp         unsafe.Pointer
+	cmdp           unsafe.Pointer
+	sbp            unsafe.Pointer
+	timeout        uint32
+	flags          uint32
+	packID         int32
+	usrPtr         unsafe.Pointer
+	status         uint8
+	maskedStatus   uint8
+	msgStatus      uint8
+	sbLenWr        uint8
+	hostStatus     uint16
+	driverStatus   uint16
+	resid          int32
+	duration       uint32
+	info           uint32
+}
+
+// diskHealth returns the health of a disk, read with the commands of the
+// protocol the disk speaks, or nil if the disk cannot be opened or does not
+// support them. Opening the disk usually requires root privileges.
+func diskHealth(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	disk string,
+	storageController StorageController,
+) *DiskHealth {
+	var read func(*os.File) (*DiskHealth, error)
+	switch storageController {
+	case StorageControllerNVMe:
+		read = nvmeHealth
+	case StorageControllerSATA:
+		// libata translates the ATA PASS-THROUGH SCSI commands
+		read = ataHealth
+	case StorageControllerSAS, StorageControllerSCSI:
+		read = scsiHealth
+		if diskVendor(paths, disk) == "ATA" {
+			// SATA disks behind a SAS HBA, whose firmware translates the
+			// ATA PASS-THROUGH SCSI commands
+			read = ataHealth
+		}
+	default:
+		return nil
+	}
+	f, err := os.OpenFile(filepath.Join(paths.DevRoot, disk), os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		log.Debug(ctx, "failed to open %s to read its health: %s", disk, err)
+		return nil
+	}
+	defer util.SafeClose(f)
+	h, err := read(f)
+	if err != nil {
+		log.Debug(ctx, "failed to read health of %s: %s", disk, err)
+		return nil
+	}
+	return h
+}
+
+// ioctl issues an ioctl on the supplied file, and returns the non-negative
+// value the syscall returned, which the NVMe driver sets to the status of
+// the command. It is a variable for the tests to fake devices.
+var ioctl = func(f *os.File, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+	r1, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return 0, errno
+	}
+	return r1, nil
+}
+
+// nvmeHealth reads the SMART/Health Information log page of an NVMe
+// namespace block device, for the whole controller
+func nvmeHealth(f *os.File) (*DiskHealth, error) {
+	buf := make([]byte, nvmeSMARTLogSize)
+	cmd := nvmeAdminCmd{
+		opcode:  nvmeAdminGetLogPage,
+		nsid:    nvmeNSIDAll,
+		addr:    unsafe.Pointer(&buf[0]),
+		dataLen: uint32(len(buf)),
+		// the number of dwords to read, minus one, is in the upper half
+		cdw10:     nvmeLogSMART | uint32(len(buf)/4-1)<<16,
+		timeoutMs: sgTimeoutMs,
+	}
+	status, err := ioctl(f, nvmeIoctlAdminCmd, unsafe.Pointer(&cmd))
+	runtime.KeepAlive(buf)
+	if err != nil {
+		return nil, err
+	}
+	// the result field only holds dword 0 of the completion, the status of
+	// a command the controller rejected is the return value of the ioctl
+	if status != 0 {
+		return nil, fmt.Errorf("NVMe Get Log Page failed with status %#x", status)
+	}
+	return parseNVMeSMARTLog(buf)
+}
+
+// sendSCSI sends a SCSI command with the SG_IO ioctl, reading the response
+// into data, if any. It returns the SCSI status and the sense data.
+func sendSCSI(f *os.File, cdb []byte, data []byte) (uint8, []byte, error) {
+	sense := make([]byte, 32)
+	hdr := sgIOHdr{
+		interfaceID:    'S',
+		dxferDirection: sgDxferNone,
+		cmdLen:         uint8(len(cdb)),
+		mxSbLen:        uint8(len(sense)),
+		cmdp:           unsafe.Pointer(&cdb[0]),
+		sbp:            unsafe.Pointer(&sense[0]),
+		timeout:        sgTimeoutMs,
+	}
+	if len(data) > 0 {
+		hdr.dxferDirection = sgDxferFromDev
+		hdr.dxferLen = uint32(len(data))
+		hdr.dxferp = unsafe.Pointer(&data[0])
+	}
+	_, err := ioctl(f, sgIO, unsafe.Pointer(&hdr))
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(data)
+	runtime.KeepAlive(sense)
+	if err != nil {
+		return 0, nil, err
+	}
+	if hdr.hostStatus != 0 {
+		return 0, nil, fmt.Errorf("SCSI command %#x failed with host status %#x", cdb[0], hdr.hostStatus)
+	}
+	return hdr.status, sense[:hdr.sbLenWr], nil
+}
+
+// ataHealth reads the SMART attributes and the health status of an ATA device
+// with ATA PASS-THROUGH (16) commands
+func ataHealth(f *os.File) (*DiskHealth, error) {
+	data := make([]byte, ataSMARTDataSize)
+	// PIO Data-In protocol, transfer length in the sector count, one sector
+	cdb := []byte{
+		ataPassThrough16, 4 << 1, 0x0e, 0, ataSMARTReadData, 0, 1, 0, 0, 0, 0x4f, 0, 0xc2, 0, ataSMART, 0,
+	}
+	status, _, err := sendSCSI(f, cdb, data)
+	if err != nil {
+		return nil, err
+	}
+	if status != scsiStatusGood {
+		return nil, fmt.Errorf("SMART READ DATA failed with status %#x", status)
+	}
+	h, err := parseATASMARTData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Non-data protocol, with CK_COND set for the device to return the
+	// registers holding the status in the sense data
+	cdb = []byte{
+		ataPassThrough16, 3 << 1, 0x20, 0, ataSMARTStatus, 0, 0, 0, 0, 0, 0x4f, 0, 0xc2, 0, ataSMART, 0,
+	}
+	status, sense, err := sendSCSI(f, cdb, nil)
+	if err == nil && (status == scsiStatusGood || status == scsiStatusCheckCondition) {
+		h.Status = parseATASMARTStatus(sense)
+	}
+	return h, nil
+}
+
+// scsiHealth reads the health of a SCSI device from its log pages and the
+// header of its grown defect list
+func scsiHealth(f *os.File) (*DiskHealth, error) {
+	pages := map[byte][]byte{}
+	for _, code := range scsiLogPages {
+		page := make([]byte, scsiLogSenseSize)
+		// PC=01b, the cumulative values
+		cdb := []byte{scsiLogSense, 0, 0x40 | code, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint16(cdb[7:9], uint16(len(page)))
+		status, _, err := sendSCSI(f, cdb, page)
+		// devices reject the pages they do not support
+		if err != nil || status != scsiStatusGood || page[0]&0x3f != code {
+			continue
+		}
+		pages[code] = page
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no supported SCSI log page")
+	}
+
+	var grownDefects uint64
+	header := make([]byte, 4)
+	// only the header of the grown defect list, in the bytes from index
+	// format whose descriptors are 8 bytes long
+	cdb := []byte{scsiReadDefectData10, 0, 0x08 | 0x04, 0, 0, 0, 0, 0, byte(len(header)), 0}
+	if status, _, err := sendSCSI(f, cdb, header); err == nil && status == scsiStatusGood {
+		grownDefects = uint64(binary.BigEndian.Uint16(header[2:4]) / 8)
+	}
+	return parseSCSILogPages(pages, grownDefects), nil
+}
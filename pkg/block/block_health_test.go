@@ -0,0 +1,193 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestParseNVMeSMARTLog(t *testing.T) {
+	b := make([]byte, nvmeSMARTLogSize)
+	binary.LittleEndian.PutUint16(b[1:3], 310)
+	b[5] = 7
+	binary.LittleEndian.PutUint64(b[128:136], 12345)
+	binary.LittleEndian.PutUint64(b[160:168], 3)
+
+	expected := &DiskHealth{
+		Protocol:           "nvme",
+		Status:             HealthStatusPassed,
+		TemperatureCelsius: 37,
+		PowerOnHours:       12345,
+		PercentageUsed:     7,
+		MediaErrors:        3,
+	}
+	h, err := parseNVMeSMARTLog(b)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the SMART log, but got %v", err)
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, h)
+	}
+
+	// an over temperature warning alone is not a failure
+	b[0] = nvmeCriticalWarningTemperature
+	if h, _ = parseNVMeSMARTLog(b); h.Status != HealthStatusPassed {
+		t.Fatalf("Expected status passed with a temperature warning, but got %s", h.Status)
+	}
+	// the available spare fell below its threshold
+	b[0] |= 0x01
+	if h, _ = parseNVMeSMARTLog(b); h.Status != HealthStatusFailed {
+		t.Fatalf("Expected status failed with a spare warning, but got %s", h.Status)
+	}
+
+	if _, err = parseNVMeSMARTLog(b[:64]); err == nil {
+		t.Fatalf("Expected an error parsing a short SMART log, but got nil")
+	}
+}
+
+func TestParseATASMARTData(t *testing.T) {
+	b := make([]byte, ataSMARTDataSize)
+	writeAttr := func(i int, id byte, value byte, raw uint64) {
+		attr := b[2+i*ataSMARTAttributeSize:]
+		attr[0] = id
+		attr[3] = value
+		for j := 5; j <= 10; j++ {
+			attr[j] = byte(raw)
+			raw >>= 8
+		}
+	}
+	writeAttr(0, ataAttrReallocatedSectors, 100, 8)
+	// minutes in the upper bytes must be ignored
+	writeAttr(1, ataAttrPowerOnHours, 95, 0x2a00002000)
+	writeAttr(2, ataAttrReportedUncorrect, 100, 2)
+	writeAttr(3, ataAttrAirflowTemperature, 60, 40)
+	// current temperature 35, min 20 and max 50 in the other raw bytes
+	writeAttr(4, ataAttrTemperature, 65, 0x003200140023)
+	writeAttr(5, ataAttrMediaWearout, 88, 0)
+
+	expected := &DiskHealth{
+		Protocol:           "ata",
+		TemperatureCelsius: 35,
+		PowerOnHours:       0x2000,
+		ReallocatedSectors: 8,
+		PercentageUsed:     12,
+		MediaErrors:        2,
+	}
+	h, err := parseATASMARTData(b)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the SMART data, but got %v", err)
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, h)
+	}
+
+	// without attribute 194, the airflow temperature is used
+	b[2+4*ataSMARTAttributeSize] = 0
+	if h, _ = parseATASMARTData(b); h.TemperatureCelsius != 40 {
+		t.Fatalf("Expected the airflow temperature 40, but got %d", h.TemperatureCelsius)
+	}
+}
+
+func TestParseATASMARTStatus(t *testing.T) {
+	descriptor := func(mid byte, high byte) []byte {
+		sense := make([]byte, 22)
+		sense[0] = 0x72
+		sense[7] = 14
+		sense[8] = 0x09
+		sense[9] = 0x0c
+		sense[8+9] = mid
+		sense[8+11] = high
+		return sense
+	}
+	fixed := make([]byte, 18)
+	fixed[0] = 0x70
+	fixed[10] = 0xf4
+	fixed[11] = 0x2c
+
+	tests := []struct {
+		name     string
+		sense    []byte
+		expected HealthStatus
+	}{
+		{"descriptor passed", descriptor(0x4f, 0xc2), HealthStatusPassed},
+		{"descriptor failed", descriptor(0xf4, 0x2c), HealthStatusFailed},
+		{"fixed failed", fixed, HealthStatusFailed},
+		{"no sense data", nil, HealthStatusUnknown},
+	}
+	for _, test := range tests {
+		if got := parseATASMARTStatus(test.sense); got != test.expected {
+			t.Fatalf("Expected %s for %s, but got %s", test.expected, test.name, got)
+		}
+	}
+}
+
+// buildSCSILogPage returns a SCSI log page with the supplied parameters, in
+// order of parameter code
+func buildSCSILogPage(code byte, params map[uint16][]byte, order ...uint16) []byte {
+	page := []byte{code, 0, 0, 0}
+	for _, pc := range order {
+		page = append(page, byte(pc>>8), byte(pc), 0x03, byte(len(params[pc])))
+		page = append(page, params[pc]...)
+	}
+	binary.BigEndian.PutUint16(page[2:4], uint16(len(page)-4))
+	return page
+}
+
+func TestParseSCSILogPages(t *testing.T) {
+	pages := map[byte][]byte{
+		scsiLogPageTemperature: buildSCSILogPage(scsiLogPageTemperature, map[uint16][]byte{
+			0x0000: {0, 41},
+			0x0001: {0, 65},
+		}, 0x0000, 0x0001),
+		scsiLogPageInfoExceptions: buildSCSILogPage(scsiLogPageInfoExceptions, map[uint16][]byte{
+			0x0000: {0, 0, 40},
+		}, 0x0000),
+		scsiLogPageReadErrors: buildSCSILogPage(scsiLogPageReadErrors, map[uint16][]byte{
+			0x0005:                     {0, 0, 0, 0, 0, 0x10, 0, 0},
+			scsiParamUncorrectedErrors: {0, 0, 0, 2},
+		}, 0x0005, scsiParamUncorrectedErrors),
+		scsiLogPageWriteErrors: buildSCSILogPage(scsiLogPageWriteErrors, map[uint16][]byte{
+			scsiParamUncorrectedErrors: {0, 1},
+		}, scsiParamUncorrectedErrors),
+		scsiLogPageSolidStateMedia: buildSCSILogPage(scsiLogPageSolidStateMedia, map[uint16][]byte{
+			scsiParamPercentageUsed: {0, 0, 0, 4},
+		}, scsiParamPercentageUsed),
+		scsiLogPageBackgroundScan: buildSCSILogPage(scsiLogPageBackgroundScan, map[uint16][]byte{
+			0x0000: {0, 0, 0x0e, 0x10, 0, 0, 0, 0, 0, 0, 0, 0},
+		}, 0x0000),
+	}
+
+	expected := &DiskHealth{
+		Protocol:           "scsi",
+		Status:             HealthStatusPassed,
+		TemperatureCelsius: 41,
+		PowerOnHours:       60,
+		ReallocatedSectors: 5,
+		PercentageUsed:     4,
+		MediaErrors:        3,
+	}
+	h := parseSCSILogPages(pages, 5)
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, h)
+	}
+
+	// failure prediction threshold exceeded
+	pages[scsiLogPageInfoExceptions] = buildSCSILogPage(scsiLogPageInfoExceptions, map[uint16][]byte{
+		0x0000: {0x5d, 0, 40},
+	}, 0x0000)
+	if h = parseSCSILogPages(pages, 5); h.Status != HealthStatusFailed {
+		t.Fatalf("Expected status failed with a predicted failure, but got %s", h.Status)
+	}
+
+	// without the informational exceptions page there is no status
+	delete(pages, scsiLogPageInfoExceptions)
+	if h = parseSCSILogPages(pages, 5); h.Status != HealthStatusUnknown {
+		t.Fatalf("Expected status unknown without exceptions page, but got %s", h.Status)
+	}
+}
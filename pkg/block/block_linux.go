@@ -8,14 +8,18 @@ package block
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/jaypipes/ghw/internal/config"
 	"github.com/jaypipes/ghw/internal/log"
 	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/pci"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
@@ -24,7 +28,11 @@ const (
 )
 
 func (i *Info) load(ctx context.Context) error {
+	paths := linuxpath.New(ctx)
 	i.Disks = disks(ctx)
+	pciDevice := pciDevices(ctx)
+	i.fillNVMe(paths, pciDevice)
+	i.fillSCSI(paths, pciDevice)
 	var tsb uint64
 	for _, d := range i.Disks {
 		tsb += d.SizeBytes
@@ -34,6 +42,27 @@ func (i *Info) load(ctx context.Context) error {
 	return nil
 }
 
+// pciDevices returns a function returning the PCI device with the supplied
+// address, which loads the PCI device information on its first call only, so
+// that hosts with no PCI storage controller do not pay for it
+func pciDevices(ctx context.Context) func(string) *pci.Device {
+	var pciInfo *pci.Info
+	loaded := false
+	return func(address string) *pci.Device {
+		if !loaded {
+			loaded = true
+			var err error
+			if pciInfo, err = pci.New(ctx); err != nil {
+				log.Warn(ctx, "failed to initialize PCI device database: %s", err)
+			}
+		}
+		if pciInfo == nil {
+			return nil
+		}
+		return pciInfo.GetDevice(address)
+	}
+}
+
 func diskPhysicalBlockSizeBytes(paths *linuxpath.Paths, disk string) uint64 {
 	// We can find the sector size in Linux by looking at the
 	// /sys/block/$DEVICE/queue/physical_block_size file in sysfs
@@ -79,6 +108,16 @@ func diskNUMANodeID(paths *linuxpath.Paths, disk string) int {
 	return -1
 }
 
+// sysfsAttr returns the trimmed contents of the supplied attribute file of a
+// sysfs directory, or an empty string if it cannot be read
+func sysfsAttr(path string, attr string) string {
+	contents, err := os.ReadFile(filepath.Join(path, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
+
 func diskVendor(paths *linuxpath.Paths, disk string) string {
 	// In Linux, the vendor for a disk device is found in the
 	// /sys/block/$DEVICE/device/vendor file in sysfs
@@ -223,6 +262,7 @@ func diskPartitions(
 	ctx context.Context,
 	paths *linuxpath.Paths,
 	disk string,
+	table *PartitionTable,
 ) []*Partition {
 	out := make([]*Partition, 0)
 	path := filepath.Join(paths.SysBlock, disk)
@@ -237,7 +277,11 @@ func diskPartitions(
 			continue
 		}
 		size := partitionSizeBytes(paths, disk, fname)
-		mp, pt, ro := partitionInfo(paths, fname)
+		mounts := deviceMounts(paths, fname)
+		mp, pt, ro := "", "", true
+		if len(mounts) > 0 {
+			mp, pt, ro = mounts[0].Path, mounts[0].FilesystemType, mounts[0].IsReadOnly
+		}
 		du := diskPartUUID(paths, disk, fname)
 		label := diskPartLabel(paths, disk, fname)
 		if pt == "" {
@@ -253,6 +297,12 @@ func diskPartitions(
 			UUID:            du,
 			Label:           label,
 			FilesystemLabel: fsLabel,
+			Holders:         deviceLinks(filepath.Join(path, fname, "holders")),
+			Mounts:          mounts,
+			Usage:           filesystemUsage(ctx, filepath.Join(path, fname), mounts),
+		}
+		if entry := table.entry(partitionNumber(paths, disk, fname)); entry != nil {
+			setPartitionTableEntry(ctx, p, entry)
 		}
 		out = append(out, p)
 	}
@@ -326,22 +376,27 @@ func disks(ctx context.Context) []*Disk {
 	// run. We can get all of this information by examining the /sys/block
 	// and /sys/class/block files
 	disks := make([]*Disk, 0)
+	filter := config.BlockDeviceFilter(ctx)
 	files, err := os.ReadDir(paths.SysBlock)
 	if err != nil {
 		return nil
 	}
 	for _, file := range files {
 		dname := file.Name()
+		if isNVMePathDevice(dname) {
+			// Each path of a multipath NVMe namespace has its own hidden
+			// block device, which is reported along with the namespace
+			continue
+		}
 
 		driveType, storageController := diskTypes(dname)
-		// TODO(jaypipes): Move this into diskTypes() once abstracting
-		// diskIsRotational for ease of unit testing
-		// Only reclassify HDD to SSD if non-rotational to avoid changing already correct types.
-		// This addresses changed kernel behavior where rotational detection may be unreliable,
-		// where some kernels report CD-ROM drives as non-rotational, incorrectly classifying them as SSD.
-		if !diskIsRotational(ctx, paths, dname) && driveType == DRIVE_TYPE_HDD {
-			driveType = DRIVE_TYPE_SSD
+		sc, scReason := diskController(paths, dname)
+		if sc != StorageControllerUnknown {
+			storageController = sc
+		} else {
+			scReason = "guessed from the device name"
 		}
+		driveType = diskDriveType(ctx, paths, dname, storageController, driveType)
 		size := diskSizeBytes(paths, dname)
 		pbs := diskPhysicalBlockSizeBytes(paths, dname)
 		busPath := diskBusPath(paths, dname)
@@ -353,8 +408,9 @@ func disks(ctx context.Context) []*Disk {
 		wwnNoExtension := diskWWNNoExtension(paths, dname)
 		removable := diskIsRemovable(paths, dname)
 
-		if storageController == STORAGE_CONTROLLER_LOOP && size == 0 {
-			// We don't care about unused loop devices...
+		classifications := diskClassifications(storageController, scReason, removable, size)
+		if reason := diskExcluded(filter, classifications); reason != "" {
+			log.Debug(ctx, "skipping block device %s: %s", dname, reason)
 			continue
 		}
 		d := &Disk{
@@ -371,9 +427,28 @@ func disks(ctx context.Context) []*Disk {
 			SerialNumber:           serialNo,
 			WWN:                    wwn,
 			WWNNoExtension:         wwnNoExtension,
+			Holders:                deviceLinks(filepath.Join(paths.SysBlock, dname, "holders")),
+			Slaves:                 deviceLinks(filepath.Join(paths.SysBlock, dname, "slaves")),
+			DeviceMapper:           diskDeviceMapper(paths, dname),
+			MDRaid:                 diskMDRaid(paths, dname),
+			Classifications:        classifications,
 		}
 
-		parts := diskPartitions(ctx, paths, dname)
+		d.Mounts = deviceMounts(paths, dname)
+		if d.DeviceMapper != nil {
+			// device-mapper devices are mounted through their
+			// /dev/mapper/$NAME alias
+			d.Mounts = append(d.Mounts, deviceMounts(paths, "/dev/mapper/"+d.DeviceMapper.Name)...)
+		}
+		d.Usage = filesystemUsage(ctx, filepath.Join(paths.SysBlock, dname), d.Mounts)
+
+		d.PartitionTable = diskPartitionTable(ctx, paths, dname, driveType, size)
+		d.Queue = diskQueue(paths, dname)
+		if config.DiskHealthEnabled(ctx) {
+			d.Health = diskHealth(ctx, paths, dname, storageController)
+		}
+
+		parts := diskPartitions(ctx, paths, dname, d.PartitionTable)
 		// Map this Disk object into the Partition...
 		for _, part := range parts {
 			part.Disk = d
@@ -386,7 +461,70 @@ func disks(ctx context.Context) []*Disk {
 	return disks
 }
 
-// diskTypes returns the drive type, storage controller and bus type of a disk
+// deviceClasses maps the storage controllers of the virtual and memory-backed
+// block devices to their class
+var deviceClasses = map[StorageController]DeviceClass{
+	StorageControllerLoop:         DeviceClassLoop,
+	StorageControllerRAM:          DeviceClassRAM,
+	StorageControllerZRAM:         DeviceClassZRAM,
+	StorageControllerDeviceMapper: DeviceClassDM,
+	StorageControllerMD:           DeviceClassMD,
+}
+
+// diskClassifications returns the classes of block devices a disk belongs to,
+// along with the reason it belongs to each of them
+func diskClassifications(
+	storageController StorageController,
+	scReason string,
+	removable bool,
+	size uint64,
+) []*Classification {
+	var classifications []*Classification
+	if class, ok := deviceClasses[storageController]; ok {
+		classifications = append(classifications, &Classification{
+			Class:  class,
+			Reason: fmt.Sprintf("storage controller is %s (%s)", storageController, scReason),
+		})
+	}
+	if removable {
+		classifications = append(classifications, &Classification{
+			Class:  DeviceClassRemovable,
+			Reason: "sysfs removable attribute is 1",
+		})
+	}
+	if size == 0 {
+		classifications = append(classifications, &Classification{
+			Class:  DeviceClassZeroSize,
+			Reason: "size is 0 bytes",
+		})
+	}
+	return classifications
+}
+
+// diskExcluded returns why the block device filter excludes a disk of the
+// supplied classes, or an empty string if the disk is included. Excluding a
+// class wins over including another class of the disk.
+func diskExcluded(
+	filter map[DeviceClass]bool,
+	classifications []*Classification,
+) string {
+	in := map[DeviceClass]bool{}
+	for _, c := range classifications {
+		if included, ok := filter[c.Class]; ok && !included {
+			return fmt.Sprintf("%s devices are excluded", c.Class)
+		}
+		in[c.Class] = true
+	}
+	// Unused loop devices are noise, unless asked for
+	if in[DeviceClassLoop] && in[DeviceClassZeroSize] &&
+		!filter[DeviceClassLoop] && !filter[DeviceClassZeroSize] {
+		return "unused loop devices are excluded by default"
+	}
+	return ""
+}
+
+// diskTypes returns the drive type and storage controller of a disk guessed
+// from its name, for when sysfs tells nothing better
 func diskTypes(dname string) (
 	DriveType,
 	StorageController,
@@ -415,18 +553,168 @@ func diskTypes(dname string) (
 		storageController = StorageControllerSCSI
 	} else if strings.HasPrefix(dname, "xvd") {
 		driveType = DriveTypeHDD
-		storageController = StorageControllerSCSI
+		storageController = StorageControllerXen
 	} else if strings.HasPrefix(dname, "mmc") {
 		driveType = DriveTypeSSD
 		storageController = StorageControllerMMC
 	} else if strings.HasPrefix(dname, "loop") {
 		driveType = DriveTypeVirtual
 		storageController = StorageControllerLoop
+	} else if strings.HasPrefix(dname, "zram") {
+		driveType = DriveTypeRAM
+		storageController = StorageControllerZRAM
+	} else if strings.HasPrefix(dname, "ram") {
+		driveType = DriveTypeRAM
+		storageController = StorageControllerRAM
+	} else if strings.HasPrefix(dname, "nbd") {
+		driveType = DriveTypeVirtual
+		storageController = StorageControllerNBD
+	} else if strings.HasPrefix(dname, "rbd") {
+		driveType = DriveTypeVirtual
+		storageController = StorageControllerRBD
+	} else if strings.HasPrefix(dname, "ublkb") {
+		driveType = DriveTypeVirtual
+		storageController = StorageControllerUBLK
+	} else if strings.HasPrefix(dname, "dm-") {
+		driveType = DriveTypeVirtual
+		storageController = StorageControllerDeviceMapper
+	} else if strings.HasPrefix(dname, "md") {
+		driveType = DriveTypeVirtual
+		storageController = StorageControllerMD
 	}
 
 	return driveType, storageController
 }
 
+var (
+	regexUSBBus      = regexp.MustCompile(`^usb\d+$`)
+	regexISCSI       = regexp.MustCompile(`^session\d+$`)
+	regexATAPort     = regexp.MustCompile(`^ata\d+$`)
+	regexIDEPort     = regexp.MustCompile(`^ide\d+$`)
+	regexMMCCard     = regexp.MustCompile(`^mmc\d+:[0-9a-f]+$`)
+	regexXenVBD      = regexp.MustCompile(`^vbd-\d+$`)
+	regexVirtIODev   = regexp.MustCompile(`^virtio\d+$`)
+	regexSASEndpoint = regexp.MustCompile(`^end_device-`)
+	regexNVMeDevice  = regexp.MustCompile(`^nvme(-subsystem)?$`)
+	regexRBDBus      = regexp.MustCompile(`^rbd$`)
+)
+
+// diskController returns the storage controller of a disk from the devices
+// its driver attached along the sysfs path of its block device directory,
+// e.g. /sys/devices/pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda
+// is a SATA disk, or StorageControllerUnknown if the path tells nothing, e.g.
+// for the virtual devices most drivers put under /sys/devices/virtual/block.
+// It also returns what in sysfs gave the storage controller away.
+func diskController(paths *linuxpath.Paths, dname string) (StorageController, string) {
+	path := filepath.Join(paths.SysBlock, dname)
+	// These drivers add a directory of their own to their block devices
+	for dir, sc := range map[string]StorageController{
+		"dm":   StorageControllerDeviceMapper,
+		"md":   StorageControllerMD,
+		"loop": StorageControllerLoop,
+	} {
+		if _, err := os.Stat(filepath.Join(path, dir)); err == nil {
+			return sc, fmt.Sprintf("sysfs has a %s directory", dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(path, "comp_algorithm")); err == nil {
+		return StorageControllerZRAM, "sysfs has a comp_algorithm attribute"
+	}
+
+	dest, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return StorageControllerUnknown, ""
+	}
+	components := strings.Split(dest, string(os.PathSeparator))
+	// find returns the first component of the device path matching re
+	find := func(re *regexp.Regexp) string {
+		for _, c := range components {
+			if re.MatchString(c) {
+				return c
+			}
+		}
+		return ""
+	}
+	type rule struct {
+		re *regexp.Regexp
+		sc StorageController
+	}
+	rules := []rule{
+		{regexNVMeDevice, StorageControllerNVMe},
+		{regexMMCCard, StorageControllerMMC},
+		{regexXenVBD, StorageControllerXen},
+		{regexVirtIODev, StorageControllerVirtIO},
+		{regexIDEPort, StorageControllerIDE},
+		{regexRBDBus, StorageControllerRBD},
+	}
+	addr := filepath.Base(filepath.Dir(filepath.Dir(dest)))
+	if _, ok := parseSCSIAddress(addr); ok {
+		// SCSI disks, the transport is found further up the path
+		rules = []rule{
+			{regexUSBBus, StorageControllerUSB},
+			{regexISCSI, StorageControllerISCSI},
+			{regexSASEndpoint, StorageControllerSAS},
+			{regexATAPort, StorageControllerSATA},
+		}
+		for _, r := range rules {
+			if c := find(r.re); c != "" {
+				return r.sc, fmt.Sprintf("SCSI device %s is below %s", addr, c)
+			}
+		}
+		return StorageControllerSCSI, fmt.Sprintf("SCSI device %s", addr)
+	}
+	for _, r := range rules {
+		if c := find(r.re); c != "" {
+			return r.sc, fmt.Sprintf("device is below %s", c)
+		}
+	}
+	return StorageControllerUnknown, ""
+}
+
+// diskDriveType refines the drive type of a disk guessed from its name with
+// what sysfs reports about the disk and its storage controller
+func diskDriveType(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	dname string,
+	storageController StorageController,
+	driveType DriveType,
+) DriveType {
+	switch storageController {
+	case StorageControllerLoop, StorageControllerDeviceMapper, StorageControllerMD,
+		StorageControllerNBD, StorageControllerRBD, StorageControllerUBLK:
+		return DriveTypeVirtual
+	case StorageControllerZRAM, StorageControllerRAM:
+		return DriveTypeRAM
+	}
+	// The peripheral device type of SCSI devices, see the SPC standard
+	switch sysfsAttr(filepath.Join(paths.SysBlock, dname, "device"), "type") {
+	case "0":
+		if driveType == DriveTypeUnknown || driveType == DriveTypeODD {
+			driveType = DriveTypeHDD
+		}
+	case "5", "7":
+		// CD/DVD drives and optical memory devices
+		return DriveTypeODD
+	}
+	// Only reclassify HDD to SSD if non-rotational to avoid changing already correct types.
+	// This addresses changed kernel behavior where rotational detection may be unreliable,
+	// where some kernels report CD-ROM drives as non-rotational, incorrectly classifying them as SSD.
+	if driveType == DriveTypeHDD && !diskIsRotational(ctx, paths, dname) {
+		driveType = DriveTypeSSD
+	}
+	switch stringZonedModel[sysfsAttr(filepath.Join(paths.SysBlock, dname, "queue"), "zoned")] {
+	case ZonedModelHostAware, ZonedModelHostManaged:
+		if driveType == DriveTypeHDD {
+			return DriveTypeSMR
+		}
+		if driveType == DriveTypeSSD {
+			return DriveTypeZNS
+		}
+	}
+	return driveType
+}
+
 func diskIsRotational(
 	ctx context.Context,
 	paths *linuxpath.Paths,
@@ -454,13 +742,13 @@ func partitionSizeBytes(paths *linuxpath.Paths, disk string, part string) uint64
 	return size * sectorSize
 }
 
-// Given a full or short partition name, returns the mount point, the type of
-// the partition and whether it's readonly
-func partitionInfo(paths *linuxpath.Paths, part string) (string, string, bool) {
-	// Allow calling PartitionInfo with either the full partition name
+// deviceMounts returns the mounts of the filesystem housed on the supplied
+// device, given as a full or short name, e.g. "/dev/sda1" or "sda1"
+func deviceMounts(paths *linuxpath.Paths, dev string) []*Mount {
+	// Allow calling deviceMounts with either the full device name
 	// "/dev/sda1" or just "sda1"
-	if !strings.HasPrefix(part, "/dev") {
-		part = "/dev/" + part
+	if !strings.HasPrefix(dev, "/dev") {
+		dev = "/dev/" + dev
 	}
 
 	// mount entries for mounted partitions look like this:
@@ -468,15 +756,16 @@ func partitionInfo(paths *linuxpath.Paths, part string) (string, string, bool) {
 	var r io.ReadCloser
 	r, err := os.Open(paths.ProcMounts)
 	if err != nil {
-		return "", "", true
+		return nil
 	}
 	defer util.SafeClose(r)
 
+	var mounts []*Mount
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		entry := parseMountEntry(line)
-		if entry == nil || entry.Partition != part {
+		if entry == nil || entry.Partition != dev {
 			continue
 		}
 		ro := true
@@ -486,10 +775,14 @@ func partitionInfo(paths *linuxpath.Paths, part string) (string, string, bool) {
 				break
 			}
 		}
-
-		return entry.Mountpoint, entry.FilesystemType, ro
-	}
-	return "", "", true
+		mounts = append(mounts, &Mount{
+			Path:           entry.Mountpoint,
+			FilesystemType: entry.FilesystemType,
+			Options:        entry.Options,
+			IsReadOnly:     ro,
+		})
+	}
+	return mounts
 }
 
 type mountEntry struct {
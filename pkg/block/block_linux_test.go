@@ -15,9 +15,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"syscall"
 	"testing"
+	"unsafe"
 
 	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/testutil"
 	"github.com/jaypipes/ghw/pkg/linuxpath"
 	"github.com/jaypipes/ghw/pkg/util"
 )
@@ -137,7 +141,7 @@ func TestDiskTypes(t *testing.T) {
 			line: "xvda1",
 			expected: entry{
 				driveType:         DRIVE_TYPE_HDD,
-				storageController: STORAGE_CONTROLLER_SCSI,
+				storageController: StorageControllerXen,
 			},
 		},
 		{
@@ -175,6 +179,55 @@ func TestDiskTypes(t *testing.T) {
 				storageController: STORAGE_CONTROLLER_LOOP,
 			},
 		},
+		{
+			line: "zram0",
+			expected: entry{
+				driveType:         DriveTypeRAM,
+				storageController: StorageControllerZRAM,
+			},
+		},
+		{
+			line: "ram0",
+			expected: entry{
+				driveType:         DriveTypeRAM,
+				storageController: StorageControllerRAM,
+			},
+		},
+		{
+			line: "nbd0",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerNBD,
+			},
+		},
+		{
+			line: "rbd0",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerRBD,
+			},
+		},
+		{
+			line: "ublkb0",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerUBLK,
+			},
+		},
+		{
+			line: "dm-0",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerDeviceMapper,
+			},
+		},
+		{
+			line: "md127",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerMD,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -367,3 +420,952 @@ func TestLoopDevicesWithOption(t *testing.T) {
 		t.Fatalf("got partition %s but expected %s", foundDisk.Partitions[0], loopPartitionName)
 	}
 }
+
+// TestNVMeMultipath emulates an NVMe over Fabrics subsystem reached through
+// two controllers, with one namespace the kernel handles multipathing for
+func TestNVMeMultipath(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	nqn := "nqn.2014-08.org.nvmexpress:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6"
+	writeFiles := func(dir string, files map[string]string) {
+		for name, contents := range files {
+			_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+			_ = os.WriteFile(filepath.Join(dir, name), []byte(contents+"\n"), 0644)
+		}
+	}
+	for i, ana := range []string{"optimized", "non-optimized"} {
+		ctrl := fmt.Sprintf("nvme%d", i)
+		writeFiles(filepath.Join(paths.SysClassNVMe, ctrl), map[string]string{
+			"cntlid":                               fmt.Sprintf("%d", i+1),
+			"model":                                "Linux",
+			"serial":                               "a1b2c3d4",
+			"firmware_rev":                         "6.8.0",
+			"transport":                            "tcp",
+			"address":                              fmt.Sprintf("traddr=192.168.1.%d,trsvcid=4420", 10+i),
+			"state":                                "live",
+			"subsysnqn":                            nqn,
+			fmt.Sprintf("nvme0c%dn1/ana_state", i): ana,
+			fmt.Sprintf("nvme0c%dn1/nsid", i):      "1",
+			fmt.Sprintf("ng%dn1/dev", i):           "240:0",
+		})
+		_ = os.MkdirAll(filepath.Join(paths.SysClassNVMeSubsystem, "nvme-subsys0", ctrl), 0755)
+		writeFiles(filepath.Join(paths.SysBlock, fmt.Sprintf("nvme0c%dn1", i)), map[string]string{
+			"size": "2097152",
+		})
+	}
+	writeFiles(filepath.Join(paths.SysBlock, "nvme0n1"), map[string]string{
+		"size":                     "2097152",
+		"nsid":                     "1",
+		"wwid":                     "uuid.6f1a4a5c-6a3e-4d8e-9d1b-1b2f8e4a8c21",
+		"metadata_bytes":           "8",
+		"queue/logical_block_size": "4096",
+		"queue/rotational":         "0",
+	})
+
+	info, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error creating BlockInfo, but got %v", err)
+	}
+	if len(info.Disks) != 1 || info.Disks[0].Name != "nvme0n1" {
+		t.Fatalf("Expected only the nvme0n1 disk, but got %v", info.Disks)
+	}
+	if len(info.NVMeControllers) != 2 {
+		t.Fatalf("Expected 2 NVMe controllers, but got %d", len(info.NVMeControllers))
+	}
+	ctrl := info.NVMeControllers[1]
+	if ctrl.Name != "nvme1" || ctrl.ID != 2 || ctrl.Transport != NVMeTransportTCP {
+		t.Fatalf("Expected nvme1 TCP controller with ID 2, but got %v", ctrl)
+	}
+	if ctrl.Address != "traddr=192.168.1.11,trsvcid=4420" {
+		t.Fatalf("Expected nvme1 address traddr=192.168.1.11,trsvcid=4420, but got %s", ctrl.Address)
+	}
+	if ctrl.Subsystem != "nvme-subsys0" || ctrl.SubsystemNQN != nqn {
+		t.Fatalf("Expected nvme1 in nvme-subsys0 (%s), but got %s (%s)", nqn, ctrl.Subsystem, ctrl.SubsystemNQN)
+	}
+	if !reflect.DeepEqual(ctrl.Namespaces, []string{"nvme0n1"}) {
+		t.Fatalf("Expected nvme1 namespaces [nvme0n1], but got %v", ctrl.Namespaces)
+	}
+
+	ns := info.Disks[0].NVMe
+	if ns == nil {
+		t.Fatalf("Expected NVMe namespace information for nvme0n1, but got nil")
+	}
+	if ns.ID != 1 || ns.LBADataSizeBytes != 4096 || ns.LBAMetadataSizeBytes != 8 {
+		t.Fatalf("Expected NSID 1 with a 4096+8 LBA format, but got %d with %d+%d",
+			ns.ID, ns.LBADataSizeBytes, ns.LBAMetadataSizeBytes)
+	}
+	if ns.SubsystemNQN != nqn {
+		t.Fatalf("Expected namespace subsystem NQN %s, but got %s", nqn, ns.SubsystemNQN)
+	}
+	if len(ns.Paths) != 2 {
+		t.Fatalf("Expected 2 namespace paths, but got %d", len(ns.Paths))
+	}
+	for i, ana := range []string{"optimized", "non-optimized"} {
+		path := ns.Paths[i]
+		if path.Controller != info.NVMeControllers[i] || path.ANAState != ana {
+			t.Fatalf("Expected path %d through nvme%d in state %s, but got %s through %s in state %s",
+				i, i, ana, path.Name, path.ControllerName, path.ANAState)
+		}
+	}
+}
+
+// TestStorageStack emulates a LUKS mapping on top of an LVM logical volume
+// whose physical volume is an MD RAID1 array of two partitions
+func TestStorageStack(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	writeFiles := func(dir string, files map[string]string) {
+		for name, contents := range files {
+			_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+			_ = os.WriteFile(filepath.Join(dir, name), []byte(contents+"\n"), 0644)
+		}
+	}
+	link := func(dir string, names ...string) {
+		_ = os.MkdirAll(dir, 0755)
+		for _, name := range names {
+			_ = os.Symlink(filepath.Join("..", "..", name), filepath.Join(dir, name))
+		}
+	}
+	for _, disk := range []string{"sda", "sdb"} {
+		writeFiles(filepath.Join(paths.SysBlock, disk), map[string]string{
+			"size":             "4194304",
+			"queue/rotational": "1",
+			disk + "1/size":    "4192256",
+		})
+		link(filepath.Join(paths.SysBlock, disk, disk+"1", "holders"), "md0")
+	}
+	writeFiles(filepath.Join(paths.SysBlock, "md0"), map[string]string{
+		"size":              "4190208",
+		"md/level":          "raid1",
+		"md/array_state":    "clean",
+		"md/raid_disks":     "2",
+		"md/degraded":       "1",
+		"md/chunk_size":     "0",
+		"md/sync_action":    "recover",
+		"md/dev-sda1/slot":  "0",
+		"md/dev-sda1/state": "in_sync",
+		"md/dev-sdb1/slot":  "none",
+		"md/dev-sdb1/state": "spare",
+	})
+	link(filepath.Join(paths.SysBlock, "md0", "slaves"), "sda1", "sdb1")
+	link(filepath.Join(paths.SysBlock, "md0", "holders"), "dm-0")
+	writeFiles(filepath.Join(paths.SysBlock, "dm-0"), map[string]string{
+		"size":             "4182016",
+		"queue/rotational": "0",
+		"dm/name":          "vg--data-root",
+		"dm/uuid":          "LVM-Fz1wDMGkXXp5Sd4aBJXbYlBgEKgmXVgZ0mXAxH5kOlRaAB3FcGD2NvfoLNwsFb9t",
+	})
+	link(filepath.Join(paths.SysBlock, "dm-0", "slaves"), "md0")
+	link(filepath.Join(paths.SysBlock, "dm-0", "holders"), "dm-1")
+	writeFiles(filepath.Join(paths.SysBlock, "dm-1"), map[string]string{
+		"size":             "4149248",
+		"queue/rotational": "0",
+		"dm/name":          "luks-3b7f8c4e",
+		"dm/uuid":          "CRYPT-LUKS2-3b7f8c4e2a1d4f5e9c8b7a6d5e4f3a2b-luks-3b7f8c4e",
+	})
+	link(filepath.Join(paths.SysBlock, "dm-1", "slaves"), "dm-0")
+
+	info, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error creating BlockInfo, but got %v", err)
+	}
+
+	lv := info.disk("dm-0")
+	if lv == nil || lv.DeviceMapper == nil {
+		t.Fatalf("Expected device-mapper information for dm-0, but got none")
+	}
+	dm := lv.DeviceMapper
+	if dm.Kind != DeviceMapperKindLVM || dm.VolumeGroup != "vg-data" || dm.LogicalVolume != "root" {
+		t.Fatalf("Expected LVM logical volume vg-data/root, but got %s %s/%s", dm.Kind, dm.VolumeGroup, dm.LogicalVolume)
+	}
+	if !reflect.DeepEqual(lv.Holders, []string{"dm-1"}) || !reflect.DeepEqual(lv.Slaves, []string{"md0"}) {
+		t.Fatalf("Expected dm-0 held by dm-1 on top of md0, but got %v and %v", lv.Holders, lv.Slaves)
+	}
+	if kind := info.disk("dm-1").DeviceMapper.Kind; kind != DeviceMapperKindLUKS {
+		t.Fatalf("Expected dm-1 to be a LUKS mapping, but got %s", kind)
+	}
+
+	md := info.disk("md0").MDRaid
+	if md == nil {
+		t.Fatalf("Expected MD RAID information for md0, but got nil")
+	}
+	if md.Level != "raid1" || md.State != "clean" || md.RaidDisks != 2 || md.DegradedDisks != 1 {
+		t.Fatalf("Expected clean raid1 of 2 disks with 1 degraded, but got %s %s of %d with %d degraded",
+			md.State, md.Level, md.RaidDisks, md.DegradedDisks)
+	}
+	expectedMembers := []*MDRaidMember{
+		{Name: "sda1", Slot: 0, State: "in_sync"},
+		{Name: "sdb1", Slot: -1, State: "spare"},
+	}
+	if !reflect.DeepEqual(md.Members, expectedMembers) {
+		t.Fatalf("Expected members %v, but got %v", expectedMembers, md.Members)
+	}
+
+	if holders := info.partition("sdb1").Holders; !reflect.DeepEqual(holders, []string{"md0"}) {
+		t.Fatalf("Expected sdb1 held by md0, but got %v", holders)
+	}
+	var names []string
+	for _, d := range info.PhysicalDisks("dm-1") {
+		names = append(names, d.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"sda", "sdb"}) {
+		t.Fatalf("Expected dm-1 on top of sda and sdb, but got %v", names)
+	}
+}
+
+func TestDeviceMounts(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	_ = os.MkdirAll(filepath.Dir(paths.ProcMounts), 0755)
+	_ = os.WriteFile(paths.ProcMounts, []byte(`/dev/sda1 /srv ext4 rw,relatime 0 0
+/dev/sda2 / xfs rw,relatime 0 0
+/dev/sda1 /var/lib/data ext4 ro,nosuid,relatime 0 0
+/dev/mapper/vg0-home /home ext4 rw,noatime 0 0
+`), 0644)
+
+	mounts := deviceMounts(paths, "sda1")
+	expected := []*Mount{
+		{Path: "/srv", FilesystemType: "ext4", Options: []string{"rw", "relatime"}, IsReadOnly: false},
+		{Path: "/var/lib/data", FilesystemType: "ext4", Options: []string{"ro", "nosuid", "relatime"}, IsReadOnly: true},
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("Expected sda1 mounts %v, but got %v", expected, mounts)
+	}
+	if mounts := deviceMounts(paths, "/dev/mapper/vg0-home"); len(mounts) != 1 || mounts[0].Path != "/home" {
+		t.Fatalf("Expected vg0-home mounted at /home, but got %v", mounts)
+	}
+	if mounts := deviceMounts(paths, "sdb1"); mounts != nil {
+		t.Fatalf("Expected no mounts for sdb1, but got %v", mounts)
+	}
+}
+
+func TestFilesystemUsage(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := config.WithChroot(baseDir)(context.TODO())
+	sysDevicePath := filepath.Join(baseDir, "sys", "block", "sda", "sda1")
+	_ = os.MkdirAll(sysDevicePath, 0755)
+	mounts := []*Mount{{Path: "/"}}
+
+	// The mountpoint of the chroot is not on the sda1 device, as in a
+	// snapshot
+	_ = os.WriteFile(filepath.Join(sysDevicePath, "dev"), []byte("259:4095\n"), 0644)
+	if usage := filesystemUsage(ctx, sysDevicePath, mounts); usage != nil {
+		t.Fatalf("Expected no usage for a mountpoint on another device, but got %v", usage)
+	}
+
+	// Pretend the chroot is on the sda1 device, as when the host root
+	// filesystem is bind mounted into a container
+	var st syscall.Stat_t
+	if err := syscall.Stat(baseDir, &st); err != nil {
+		t.Fatalf("Expected no error stat'ing %s, but got %v", baseDir, err)
+	}
+	_ = os.WriteFile(filepath.Join(sysDevicePath, "dev"), []byte(devNumber(uint64(st.Dev))+"\n"), 0644)
+	usage := filesystemUsage(ctx, sysDevicePath, mounts)
+	if usage == nil {
+		t.Fatalf("Expected usage for a mountpoint on the device, but got nil")
+	}
+	if usage.TotalBytes == 0 || usage.UsedBytes > usage.TotalBytes || usage.AvailableBytes > usage.TotalBytes {
+		t.Fatalf("Expected consistent usage, but got %+v", usage)
+	}
+
+	if usage := filesystemUsage(ctx, sysDevicePath, nil); usage != nil {
+		t.Fatalf("Expected no usage for an unmounted device, but got %v", usage)
+	}
+}
+
+func TestDevNumber(t *testing.T) {
+	tests := []struct {
+		dev      uint64
+		expected string
+	}{
+		{dev: 0x801, expected: "8:1"},
+		{dev: 0x10300, expected: "259:0"},
+		{dev: 0x10300 | (0x1f << 20), expected: "259:7936"},
+	}
+	for _, test := range tests {
+		if actual := devNumber(test.dev); actual != test.expected {
+			t.Fatalf("Expected %s for device number %#x, but got %s", test.expected, test.dev, actual)
+		}
+	}
+}
+
+// TestPartitionTableFallback checks that the partition UUID and label come
+// from the partition table of the disk when there is no udev database
+func TestPartitionTableFallback(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	numBlocks := uint64(2048)
+	image := buildGPTDisk(t, numBlocks, "4c3e9f1a-2b7d-4e58-a6c0-9d8e7f6a5b4c", []testGPTEntry{
+		{
+			typeGUID: "0fc63daf-8483-4772-8e79-3d69d8477de4",
+			uuid:     "f2d1c3b4-5a69-4788-9b0c-1d2e3f405162",
+			name:     "data",
+			start:    34,
+			end:      2014,
+		},
+	})
+	_ = os.MkdirAll(paths.DevRoot, 0755)
+	_ = os.WriteFile(filepath.Join(paths.DevRoot, "sda"), image, 0644)
+	_ = os.MkdirAll(filepath.Join(paths.SysBlock, "sda", "sda1"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysBlock, "sda", "queue"), 0755)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "size"), []byte(fmt.Sprintf("%d\n", numBlocks)), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "queue", "rotational"), []byte("1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "queue", "logical_block_size"), []byte("512\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "sda1", "partition"), []byte("1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "sda1", "size"), []byte("1981\n"), 0644)
+
+	d := disks(ctx)
+	if len(d) != 1 {
+		t.Fatalf("Expected one disk, but got %d", len(d))
+	}
+	pt := d[0].PartitionTable
+	if pt == nil || pt.Type != PartitionTableTypeGPT || pt.ID != "4c3e9f1a-2b7d-4e58-a6c0-9d8e7f6a5b4c" {
+		t.Fatalf("Expected GPT 4c3e9f1a-2b7d-4e58-a6c0-9d8e7f6a5b4c, but got %+v", pt)
+	}
+	if len(d[0].Partitions) != 1 {
+		t.Fatalf("Expected one partition, but got %d", len(d[0].Partitions))
+	}
+	part := d[0].Partitions[0]
+	if part.TableEntry == nil || part.TableEntry.TypeName != "Linux filesystem" {
+		t.Fatalf("Expected a Linux filesystem partition table entry, but got %+v", part.TableEntry)
+	}
+	if part.UUID != "f2d1c3b4-5a69-4788-9b0c-1d2e3f405162" || part.Label != "data" {
+		t.Fatalf("Expected partition UUID f2d1c3b4-5a69-4788-9b0c-1d2e3f405162 and label data, but got %s and %s",
+			part.UUID, part.Label)
+	}
+}
+
+func TestDiskQueue(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := config.WithChroot(baseDir)(context.TODO())
+	paths := linuxpath.New(ctx)
+
+	queuePath := filepath.Join(paths.SysBlock, "sda", "queue")
+	_ = os.MkdirAll(queuePath, 0755)
+	for name, contents := range map[string]string{
+		"logical_block_size":  "512",
+		"minimum_io_size":     "4096",
+		"optimal_io_size":     "0",
+		"max_sectors_kb":      "1280",
+		"max_hw_sectors_kb":   "32767",
+		"discard_granularity": "4096",
+		"discard_max_bytes":   "2147450880",
+		"zoned":               "host-managed",
+		"nr_zones":            "55880",
+		"chunk_sectors":       "524288",
+		"write_cache":         "write back",
+		"scheduler":           "[mq-deadline] kyber bfq none",
+		"nr_requests":         "64",
+		"dax":                 "0",
+	} {
+		_ = os.WriteFile(filepath.Join(queuePath, name), []byte(contents+"\n"), 0644)
+	}
+
+	expected := &DiskQueue{
+		LogicalBlockSizeBytes:   512,
+		MinimumIOSizeBytes:      4096,
+		OptimalIOSizeBytes:      0,
+		MaxIOSizeBytes:          1280 * 1024,
+		MaxHardwareIOSizeBytes:  32767 * 1024,
+		SupportsDiscard:         true,
+		DiscardGranularityBytes: 4096,
+		DiscardMaxBytes:         2147450880,
+		ZonedModel:              ZonedModelHostManaged,
+		NumZones:                55880,
+		ZoneSizeBytes:           256 * 1024 * 1024,
+		WriteCache:              "write back",
+		Scheduler:               "mq-deadline",
+		AvailableSchedulers:     []string{"mq-deadline", "kyber", "bfq", "none"},
+		NumRequests:             64,
+		SupportsDAX:             false,
+	}
+	if q := diskQueue(paths, "sda"); !reflect.DeepEqual(q, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, q)
+	}
+	if q := diskQueue(paths, "sdb"); q != nil {
+		t.Fatalf("Expected no queue for a missing disk, but got %+v", q)
+	}
+}
+
+func TestParseScheduler(t *testing.T) {
+	tests := []struct {
+		contents          string
+		expectedActive    string
+		expectedAvailable []string
+	}{
+		{
+			contents:          "mq-deadline kyber [bfq] none",
+			expectedActive:    "bfq",
+			expectedAvailable: []string{"mq-deadline", "kyber", "bfq", "none"},
+		},
+		{
+			contents:          "[none] mq-deadline",
+			expectedActive:    "none",
+			expectedAvailable: []string{"none", "mq-deadline"},
+		},
+		{
+			// device-mapper devices
+			contents:          "none",
+			expectedActive:    "none",
+			expectedAvailable: []string{"none"},
+		},
+		{
+			contents:          "",
+			expectedActive:    "",
+			expectedAvailable: []string{},
+		},
+	}
+	for _, test := range tests {
+		active, available := parseScheduler(test.contents)
+		if active != test.expectedActive || !reflect.DeepEqual(available, test.expectedAvailable) {
+			t.Fatalf("Expected %s in %v for %q, but got %s in %v",
+				test.expectedActive, test.expectedAvailable, test.contents, active, available)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := config.WithChroot(baseDir)(context.TODO())
+	paths := linuxpath.New(ctx)
+
+	writeStat := func(dir string, contents string) {
+		_ = os.MkdirAll(dir, 0755)
+		_ = os.WriteFile(filepath.Join(dir, "stat"), []byte(contents), 0644)
+	}
+	// a kernel 5.5+ disk with one partition, and a pre 4.18 disk
+	writeStat(filepath.Join(paths.SysBlock, "sda"),
+		"  135540    38121 10429810    57932   200193   191541 14553840   291866        0   207604   368398    10212        0 100468480     1542     27402    17057\n")
+	writeStat(filepath.Join(paths.SysBlock, "sda", "sda1"),
+		"     350        0    11870       80        2        0       16        1        0       92       81        0        0        0        0        0        0\n")
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "sda1", "partition"), []byte("1\n"), 0644)
+	writeStat(filepath.Join(paths.SysBlock, "sdb"),
+		"      12        0       96        4        0        0        0        0        0        4        4\n")
+	// hidden NVMe path devices are skipped, like in the inventory
+	writeStat(filepath.Join(paths.SysBlock, "nvme0c0n1"),
+		"       1        0        8        0        0        0        0        0        0        0        0\n")
+
+	stats := &Stats{}
+	if err := stats.load(ctx); err != nil {
+		t.Fatalf("Expected no error loading the stats, but got %v", err)
+	}
+	if len(stats.Devices) != 3 {
+		t.Fatalf("Expected 3 devices, but got %d", len(stats.Devices))
+	}
+	expected := &DeviceStats{
+		Name:              "sda",
+		ReadsCompleted:    135540,
+		ReadsMerged:       38121,
+		SectorsRead:       10429810,
+		ReadTicksMs:       57932,
+		WritesCompleted:   200193,
+		WritesMerged:      191541,
+		SectorsWritten:    14553840,
+		WriteTicksMs:      291866,
+		IOTicksMs:         207604,
+		TimeInQueueMs:     368398,
+		DiscardsCompleted: 10212,
+		SectorsDiscarded:  100468480,
+		DiscardTicksMs:    1542,
+		FlushesCompleted:  27402,
+		FlushTicksMs:      17057,
+	}
+	if ds := stats.Device("sda"); !reflect.DeepEqual(ds, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, ds)
+	}
+	if ds := stats.Device("sda1"); ds == nil || ds.Disk != "sda" || ds.SectorsRead != 11870 {
+		t.Fatalf("Expected sda1 of disk sda with 11870 sectors read, but got %+v", ds)
+	}
+	if ds := stats.Device("sdb"); ds == nil || ds.ReadsCompleted != 12 || ds.FlushesCompleted != 0 {
+		t.Fatalf("Expected sdb with 12 reads and no flush counter, but got %+v", ds)
+	}
+
+	if _, err := parseDeviceStats("1 2 3\n"); err == nil {
+		t.Fatalf("Expected an error parsing a truncated stat file, but got nil")
+	}
+}
+
+func TestSCSITopology(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	writeFiles := func(dir string, files map[string]string) {
+		_ = os.MkdirAll(dir, 0755)
+		for name, contents := range files {
+			_ = os.WriteFile(filepath.Join(dir, name), []byte(contents+"\n"), 0644)
+		}
+	}
+	// sysfs links are relative, so that they resolve within the chroot
+	link := func(target string, name string) {
+		_ = os.MkdirAll(filepath.Dir(name), 0755)
+		rel, _ := filepath.Rel(filepath.Dir(name), target)
+		_ = os.Symlink(rel, name)
+	}
+
+	hba := filepath.Join(paths.SysRoot, "devices", "pci0000:00", "0000:00:01.0", "0000:03:00.0", "host2")
+	writeFiles(filepath.Join(hba, "scsi_host", "host2"), map[string]string{
+		"proc_name":        "mpt3sas",
+		"host_sas_address": "0x500605b00a1b2c30",
+	})
+	link(filepath.Join(hba, "scsi_host", "host2"), filepath.Join(paths.SysClassSCSIHost, "host2"))
+	// an AHCI controller with no SAS topology
+	ahci := filepath.Join(paths.SysRoot, "devices", "pci0000:00", "0000:00:17.0", "ata1", "host0")
+	writeFiles(filepath.Join(ahci, "scsi_host", "host0"), map[string]string{
+		"proc_name": "ahci",
+	})
+	link(filepath.Join(ahci, "scsi_host", "host0"), filepath.Join(paths.SysClassSCSIHost, "host0"))
+
+	expander := filepath.Join(hba, "port-2:0", "expander-2:0")
+	writeFiles(filepath.Join(expander, "sas_expander", "expander-2:0"), map[string]string{
+		"vendor_id":   "LSI",
+		"product_id":  "SAS3x40",
+		"product_rev": "0601",
+	})
+	link(filepath.Join(expander, "sas_expander", "expander-2:0"), filepath.Join(paths.SysClassSASExpander, "expander-2:0"))
+	writeFiles(filepath.Join(expander, "sas_device", "expander-2:0"), map[string]string{
+		"sas_address": "0x500056b3f1b2c3ff",
+	})
+	link(filepath.Join(expander, "sas_device", "expander-2:0"), filepath.Join(paths.SysClassSASDevice, "expander-2:0"))
+
+	endDevice := filepath.Join(expander, "port-2:0:5", "end_device-2:0:5")
+	writeFiles(filepath.Join(endDevice, "sas_device", "end_device-2:0:5"), map[string]string{
+		"sas_address": "0x5000c500a1b2c3d5",
+	})
+	link(filepath.Join(endDevice, "sas_device", "end_device-2:0:5"), filepath.Join(paths.SysClassSASDevice, "end_device-2:0:5"))
+	scsiDev := filepath.Join(endDevice, "target2:0:5", "2:0:5:0")
+	writeFiles(filepath.Join(scsiDev, "block", "sdc"), map[string]string{"size": "1000"})
+	link(filepath.Join(scsiDev, "block", "sdc"), filepath.Join(paths.SysBlock, "sdc"))
+
+	sataDev := filepath.Join(ahci, "target0:0:0", "0:0:0:0")
+	writeFiles(filepath.Join(sataDev, "block", "sda"), map[string]string{"size": "1000"})
+	link(filepath.Join(sataDev, "block", "sda"), filepath.Join(paths.SysBlock, "sda"))
+
+	ses := filepath.Join(expander, "port-2:0:10", "end_device-2:0:10", "target2:0:10", "2:0:10:0")
+	writeFiles(ses, map[string]string{
+		"vendor": "HGST",
+		"model":  "H4060-J",
+	})
+	enclosure := filepath.Join(ses, "enclosure", "2:0:10:0")
+	writeFiles(enclosure, map[string]string{"id": "0x5000ccab0405db7f"})
+	link(ses, filepath.Join(enclosure, "device"))
+	writeFiles(filepath.Join(enclosure, "SLOT 07"), map[string]string{
+		"slot":   "7",
+		"status": "OK",
+		"locate": "1",
+		"fault":  "0",
+	})
+	link(scsiDev, filepath.Join(enclosure, "SLOT 07", "device"))
+	writeFiles(filepath.Join(enclosure, "SLOT 08"), map[string]string{
+		"slot":   "8",
+		"status": "not installed",
+		"locate": "0",
+		"fault":  "1",
+	})
+	link(enclosure, filepath.Join(paths.SysClassEnclosure, "2:0:10:0"))
+
+	info := &Info{}
+	if err := info.load(ctx); err != nil {
+		t.Fatalf("Expected no error loading block info, but got %v", err)
+	}
+
+	if len(info.SCSIHosts) != 2 {
+		t.Fatalf("Expected 2 SCSI hosts, but got %d", len(info.SCSIHosts))
+	}
+	host := info.SCSIHosts[1]
+	if host.Name != "host2" || host.ID != 2 || host.Driver != "mpt3sas" ||
+		host.SASAddress != "0x500605b00a1b2c30" || host.PCIAddress != "0000:03:00.0" {
+		t.Fatalf("Expected host2 of mpt3sas at 0000:03:00.0, but got %+v", host)
+	}
+
+	expectedExpanders := []*SASExpander{
+		{
+			Name:       "expander-2:0",
+			SASAddress: "0x500056b3f1b2c3ff",
+			Vendor:     "LSI",
+			Product:    "SAS3x40",
+			Revision:   "0601",
+			Parent:     "host2",
+		},
+	}
+	if !reflect.DeepEqual(info.SASExpanders, expectedExpanders) {
+		t.Fatalf("Expected %+v, but got %+v", expectedExpanders, info.SASExpanders)
+	}
+
+	if len(info.Enclosures) != 1 {
+		t.Fatalf("Expected 1 enclosure, but got %d", len(info.Enclosures))
+	}
+	enc := info.Enclosures[0]
+	if enc.Name != "2:0:10:0" || enc.ID != "0x5000ccab0405db7f" || enc.Vendor != "HGST" || len(enc.Slots) != 2 {
+		t.Fatalf("Expected enclosure 2:0:10:0 of HGST with 2 slots, but got %+v", enc)
+	}
+	empty := enc.Slots[1]
+	if empty.Slot != 8 || empty.Disk != "" || empty.Status != "not installed" || !empty.FaultLED || empty.LocateLED {
+		t.Fatalf("Expected empty slot 8 with the fault LED on, but got %+v", empty)
+	}
+
+	var sda, sdc *Disk
+	for _, d := range info.Disks {
+		switch d.Name {
+		case "sda":
+			sda = d
+		case "sdc":
+			sdc = d
+		}
+	}
+	if sda == nil || sdc == nil {
+		t.Fatalf("Expected disks sda and sdc, but got %v", info.Disks)
+	}
+	if sda.SCSI == nil || sda.SCSI.Address.String() != "0:0:0:0" || sda.SCSI.HostName != "host0" ||
+		sda.SCSI.SASAddress != "" || sda.SCSI.Slot != -1 || sda.SCSI.EnclosureSlot != nil {
+		t.Fatalf("Expected SATA disk sda at 0:0:0:0 of host0, but got %+v", sda.SCSI)
+	}
+
+	s := sdc.SCSI
+	if s == nil {
+		t.Fatalf("Expected SCSI information for sdc, but got nil")
+	}
+	expectedAddr := SCSIAddress{Host: 2, Channel: 0, Target: 5, LUN: 0}
+	if s.Address != expectedAddr || s.Host != host || s.SASAddress != "0x5000c500a1b2c3d5" ||
+		!reflect.DeepEqual(s.Expanders, []string{"expander-2:0"}) {
+		t.Fatalf("Expected sdc at 2:0:5:0 behind expander-2:0 of host2, but got %+v", s)
+	}
+	if s.Enclosure != "2:0:10:0" || s.Slot != 7 || s.EnclosureSlot != enc.Slots[0] {
+		t.Fatalf("Expected sdc in slot 7 of enclosure 2:0:10:0, but got %+v", s)
+	}
+	if slot := s.EnclosureSlot; slot.Name != "SLOT 07" || slot.Disk != "sdc" || !slot.LocateLED ||
+		slot.FaultLED || slot.SASAddress != "" {
+		t.Fatalf("Expected slot SLOT 07 holding sdc with the locate LED on, but got %+v", slot)
+	}
+}
+
+func TestDiskController(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	devices := filepath.Join(paths.SysRoot, "devices")
+	tests := []struct {
+		dname      string
+		devicePath string
+		attrs      map[string]string
+		expected   StorageController
+		driveType  DriveType
+	}{
+		{
+			dname:      "sda",
+			devicePath: "pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			attrs:      map[string]string{"queue/rotational": "1"},
+			expected:   StorageControllerSATA,
+			driveType:  DriveTypeHDD,
+		},
+		{
+			dname:      "sdb",
+			devicePath: "pci0000:00/0000:00:01.0/0000:03:00.0/host2/port-2:0/expander-2:0/port-2:0:5/end_device-2:0:5/target2:0:5/2:0:5:0/block/sdb",
+			attrs:      map[string]string{"queue/rotational": "1", "queue/zoned": "host-managed"},
+			expected:   StorageControllerSAS,
+			driveType:  DriveTypeSMR,
+		},
+		{
+			dname:      "sdc",
+			devicePath: "pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdc",
+			attrs:      map[string]string{"queue/rotational": "0"},
+			expected:   StorageControllerUSB,
+			driveType:  DriveTypeSSD,
+		},
+		{
+			dname:      "sdd",
+			devicePath: "platform/host7/session1/target7:0:0/7:0:0:0/block/sdd",
+			attrs:      map[string]string{"queue/rotational": "1"},
+			expected:   StorageControllerISCSI,
+			driveType:  DriveTypeHDD,
+		},
+		{
+			// virtio-scsi disks are SCSI disks
+			dname:      "sde",
+			devicePath: "pci0000:00/0000:00:03.0/virtio1/host0/target0:0:1/0:0:1:0/block/sde",
+			attrs:      map[string]string{"queue/rotational": "1"},
+			expected:   StorageControllerSCSI,
+			driveType:  DriveTypeHDD,
+		},
+		{
+			// an optical drive not named srN
+			dname:      "sdf",
+			devicePath: "pci0000:00/0000:00:17.0/ata2/host1/target1:0:0/1:0:0:0/block/sdf",
+			attrs:      map[string]string{"queue/rotational": "0", "device/type": "5"},
+			expected:   StorageControllerSATA,
+			driveType:  DriveTypeODD,
+		},
+		{
+			dname:      "xvda",
+			devicePath: "vbd-51712/block/xvda",
+			attrs:      map[string]string{"queue/rotational": "0"},
+			expected:   StorageControllerXen,
+			driveType:  DriveTypeSSD,
+		},
+		{
+			dname:      "vda",
+			devicePath: "pci0000:00/0000:00:04.0/virtio2/block/vda",
+			attrs:      map[string]string{"queue/rotational": "1"},
+			expected:   StorageControllerVirtIO,
+			driveType:  DriveTypeHDD,
+		},
+		{
+			dname:      "nvme0n2",
+			devicePath: "pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n2",
+			attrs:      map[string]string{"queue/rotational": "0", "queue/zoned": "host-managed"},
+			expected:   StorageControllerNVMe,
+			driveType:  DriveTypeZNS,
+		},
+		{
+			dname:      "mmcblk0",
+			devicePath: "platform/fe340000.mmc/mmc_host/mmc0/mmc0:0001/block/mmcblk0",
+			expected:   StorageControllerMMC,
+			driveType:  DriveTypeSSD,
+		},
+		{
+			dname:      "rbd0",
+			devicePath: "rbd/0/block/rbd0",
+			expected:   StorageControllerRBD,
+			driveType:  DriveTypeVirtual,
+		},
+		{
+			dname:      "zram0",
+			devicePath: "virtual/block/zram0",
+			attrs:      map[string]string{"comp_algorithm": "lzo [zstd]"},
+			expected:   StorageControllerZRAM,
+			driveType:  DriveTypeRAM,
+		},
+		{
+			// named by the user through udev rules
+			dname:      "dm-3",
+			devicePath: "virtual/block/dm-3",
+			attrs:      map[string]string{"dm/name": "vg0-root"},
+			expected:   StorageControllerDeviceMapper,
+			driveType:  DriveTypeVirtual,
+		},
+		{
+			// nothing in the path of the device tells what it is
+			dname:      "nbd0",
+			devicePath: "virtual/block/nbd0",
+			expected:   StorageControllerUnknown,
+			driveType:  DriveTypeVirtual,
+		},
+	}
+	for _, test := range tests {
+		devDir := filepath.Join(devices, test.devicePath)
+		if err := os.MkdirAll(devDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(paths.SysBlock, 0755); err != nil {
+			t.Fatal(err)
+		}
+		rel, err := filepath.Rel(paths.SysBlock, devDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(rel, filepath.Join(paths.SysBlock, test.dname)); err != nil {
+			t.Fatal(err)
+		}
+		if dev := filepath.Dir(filepath.Dir(devDir)); filepath.Base(filepath.Dir(devDir)) == "block" {
+			// the device link of the block device points to its parent
+			if rel, err = filepath.Rel(devDir, dev); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(rel, filepath.Join(devDir, "device")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// after the device link, so that device/* attributes land in the parent
+		testutil.WriteSysfsFiles(t, devDir, test.attrs)
+
+		got, _ := diskController(paths, test.dname)
+		if got != test.expected {
+			t.Fatalf("For %s, expected storage controller %s, but got %s", test.dname, test.expected, got)
+		}
+		driveType, storageController := diskTypes(test.dname)
+		if got != StorageControllerUnknown {
+			storageController = got
+		}
+		if dt := diskDriveType(ctx, paths, test.dname, storageController, driveType); dt != test.driveType {
+			t.Fatalf("For %s, expected drive type %s, but got %s", test.dname, test.driveType, dt)
+		}
+	}
+}
+
+// TestBlockDeviceFilter ensures that the classes of block devices included and
+// excluded by the context modifiers select the disks reported
+func TestBlockDeviceFilter(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	for dname, attrs := range map[string]map[string]string{
+		"sda":   {"size": "2048"},
+		"sdb":   {"size": "0", "removable": "1"},
+		"loop0": {"size": "2048", "loop/backing_file": "/var/lib/image"},
+		"loop1": {"size": "0", "loop/backing_file": ""},
+		"zram0": {"size": "2048", "comp_algorithm": "[lzo] zstd"},
+		"dm-0":  {"size": "2048", "dm/name": "vg-root"},
+		"md0":   {"size": "2048", "md/level": "raid1"},
+	} {
+		for attr, val := range attrs {
+			path := filepath.Join(paths.SysBlock, dname, attr)
+			_ = os.MkdirAll(filepath.Dir(path), 0755)
+			_ = os.WriteFile(path, []byte(val+"\n"), 0644)
+		}
+	}
+
+	names := func(ctx context.Context) []string {
+		out := []string{}
+		for _, d := range disks(ctx) {
+			out = append(out, d.Name)
+		}
+		sort.Strings(out)
+		return out
+	}
+	tests := []struct {
+		name      string
+		modifiers []config.Modifier
+		expected  []string
+	}{
+		{
+			name:     "default",
+			expected: []string{"dm-0", "loop0", "md0", "sda", "sdb", "zram0"},
+		},
+		{
+			name:      "include loop",
+			modifiers: []config.Modifier{config.WithIncludeBlockDevices(config.BlockDeviceLoop)},
+			expected:  []string{"dm-0", "loop0", "loop1", "md0", "sda", "sdb", "zram0"},
+		},
+		{
+			name: "exclude virtual",
+			modifiers: []config.Modifier{config.WithExcludeBlockDevices(
+				config.BlockDeviceLoop, config.BlockDeviceZRAM, config.BlockDeviceDM, config.BlockDeviceMD,
+			)},
+			expected: []string{"sda", "sdb"},
+		},
+		{
+			// excluding a class of a disk wins over including another
+			name: "exclude zero-size",
+			modifiers: []config.Modifier{
+				config.WithIncludeBlockDevices(config.BlockDeviceLoop, config.BlockDeviceRemovable),
+				config.WithExcludeBlockDevices(config.BlockDeviceZeroSize),
+			},
+			expected: []string{"dm-0", "loop0", "md0", "sda", "zram0"},
+		},
+		{
+			name: "exclude then include",
+			modifiers: []config.Modifier{
+				config.WithExcludeBlockDevices(config.BlockDeviceRemovable),
+				config.WithIncludeBlockDevices(config.BlockDeviceRemovable),
+			},
+			expected: []string{"dm-0", "loop0", "md0", "sda", "sdb", "zram0"},
+		},
+	}
+	for _, test := range tests {
+		tctx := ctx
+		for _, m := range test.modifiers {
+			tctx = m(tctx)
+		}
+		if got := names(tctx); !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("%s: Expected disks %v, but got %v", test.name, test.expected, got)
+		}
+	}
+
+	for _, d := range disks(ctx) {
+		if d.Name != "sdb" {
+			continue
+		}
+		expected := []*Classification{
+			{Class: DeviceClassRemovable, Reason: "sysfs removable attribute is 1"},
+			{Class: DeviceClassZeroSize, Reason: "size is 0 bytes"},
+		}
+		if !reflect.DeepEqual(d.Classifications, expected) {
+			t.Fatalf("Expected classifications %+v, but got %+v", expected, d.Classifications)
+		}
+	}
+}
+
+// TestNVMeHealthCommandStatus ensures a Get Log Page command the controller
+// rejects is reported as a failure, rather than as the zeroed log page of a
+// healthy disk
+func TestNVMeHealthCommandStatus(t *testing.T) {
+	origIoctl := ioctl
+	defer func() {
+		ioctl = origIoctl
+	}()
+
+	var cmd nvmeAdminCmd
+	ioctl = func(f *os.File, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+		cmd = *(*nvmeAdminCmd)(arg)
+		// Invalid Log Page, with the Do Not Retry bit
+		return 0x4109, nil
+	}
+	h, err := nvmeHealth(nil)
+	if err == nil {
+		t.Fatalf("Expected an error for a failed command, but got %+v", h)
+	}
+	if cmd.opcode != nvmeAdminGetLogPage || cmd.cdw10&0xff != nvmeLogSMART {
+		t.Fatalf("Expected a Get Log Page command for the SMART log, but got %+v", cmd)
+	}
+
+	ioctl = func(f *os.File, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+		return 0, syscall.EACCES
+	}
+	if _, err := nvmeHealth(nil); err != syscall.EACCES {
+		t.Fatalf("Expected EACCES, but got %v", err)
+	}
+}
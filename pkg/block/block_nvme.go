@@ -0,0 +1,179 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/pci"
+)
+
+// NVMeTransport describes the transport an NVMe controller is reached over
+type NVMeTransport int
+
+const (
+	// NVMeTransportUnknown means we could not determine the transport of the
+	// controller
+	NVMeTransportUnknown NVMeTransport = iota
+	// NVMeTransportPCIe indicates a controller attached to the local PCIe
+	// bus
+	NVMeTransportPCIe
+	// NVMeTransportTCP indicates an NVMe over Fabrics controller reached over
+	// TCP
+	NVMeTransportTCP
+	// NVMeTransportRDMA indicates an NVMe over Fabrics controller reached over
+	// RDMA (RoCE, iWARP or InfiniBand)
+	NVMeTransportRDMA
+	// NVMeTransportFC indicates an NVMe over Fabrics controller reached over
+	// Fibre Channel
+	NVMeTransportFC
+	// NVMeTransportLoop indicates a controller of a local NVMe target
+	// exported through the loopback transport
+	NVMeTransportLoop
+)
+
+var (
+	nvmeTransportString = map[NVMeTransport]string{
+		NVMeTransportUnknown: "Unknown",
+		NVMeTransportPCIe:    "PCIe",
+		NVMeTransportTCP:     "TCP",
+		NVMeTransportRDMA:    "RDMA",
+		NVMeTransportFC:      "FC",
+		NVMeTransportLoop:    "Loop",
+	}
+
+	// keys are lowercase, matching what NVMeTransport::MarshalJSON produces
+	stringNVMeTransport = map[string]NVMeTransport{
+		"unknown": NVMeTransportUnknown,
+		"pcie":    NVMeTransportPCIe,
+		"tcp":     NVMeTransportTCP,
+		"rdma":    NVMeTransportRDMA,
+		"fc":      NVMeTransportFC,
+		"loop":    NVMeTransportLoop,
+	}
+)
+
+func (t NVMeTransport) String() string {
+	return nvmeTransportString[t]
+}
+
+// MarshalJSON serializes the transport as the lowercased string used by the
+// kernel in /sys/class/nvme/nvmeX/transport
+func (t NVMeTransport) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(t.String()))), nil
+}
+
+func (t *NVMeTransport) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringNVMeTransport[key]
+	if !ok {
+		return fmt.Errorf("unknown NVMe transport: %q", key)
+	}
+	*t = val
+	return nil
+}
+
+// NVMeController describes an NVMe controller, the entity processing the
+// commands sent to the namespaces of an NVMe subsystem. A subsystem reached
+// over several paths, e.g. a dual-ported drive or an NVMe over Fabrics
+// target, has one controller per path.
+type NVMeController struct {
+	// Name is the kernel name of the controller, e.g. `nvme0`
+	Name string `json:"name"`
+	// ID is the controller identifier (CNTLID), unique within the subsystem
+	ID int `json:"id"`
+	// Model is the model number reported by the controller
+	Model string `json:"model"`
+	// SerialNumber is the serial number reported by the controller
+	SerialNumber string `json:"serial_number"`
+	// FirmwareRevision is the revision of the firmware the controller runs
+	FirmwareRevision string `json:"firmware_revision"`
+	// Transport is the transport the controller is reached over
+	Transport NVMeTransport `json:"transport"`
+	// Address is the transport address of the controller. This is the PCI
+	// address for PCIe controllers and a string such as
+	// `traddr=192.168.1.10,trsvcid=4420` for NVMe over Fabrics controllers.
+	Address string `json:"address"`
+	// State is the state of the controller, e.g. `live` or `connecting`
+	State string `json:"state"`
+	// Subsystem is the kernel name of the subsystem the controller belongs
+	// to, e.g. `nvme-subsys0`
+	Subsystem string `json:"subsystem,omitempty"`
+	// SubsystemNQN is the NVMe Qualified Name of the subsystem the controller
+	// belongs to
+	SubsystemNQN string `json:"subsystem_nqn"`
+	// Namespaces contains the names of the block devices of the namespaces
+	// attached to the controller, e.g. `nvme0n1`
+	Namespaces []string `json:"namespaces"`
+	// PCI is a pointer to the PCI device of PCIe controllers
+	PCI *pci.Device `json:"pci,omitempty"`
+}
+
+// String returns a short string indicating important information about the
+// NVMe controller.
+func (c *NVMeController) String() string {
+	return fmt.Sprintf(
+		"%s %s [@%s] model=%s serial=%s firmware=%s nqn=%s",
+		c.Name,
+		c.Transport.String(),
+		c.Address,
+		c.Model,
+		c.SerialNumber,
+		c.FirmwareRevision,
+		c.SubsystemNQN,
+	)
+}
+
+// NVMeNamespacePath describes the access to an NVMe namespace through one of
+// the controllers of its subsystem.
+type NVMeNamespacePath struct {
+	// Name is the kernel name of the path, e.g. `nvme0c1n1` when the kernel
+	// handles multipathing, or the name of the namespace block device
+	// otherwise
+	Name string `json:"name"`
+	// ControllerName is the name of the controller the path goes through,
+	// e.g. `nvme1`
+	ControllerName string `json:"controller"`
+	// Controller is a pointer to the controller the path goes through
+	Controller *NVMeController `json:"-"`
+	// ANAState is the Asymmetric Namespace Access state of the path, e.g.
+	// `optimized`, `non-optimized` or `inaccessible`. It is empty when the
+	// controller does not report ANA.
+	ANAState string `json:"ana_state,omitempty"`
+}
+
+// NVMeNamespace describes an NVMe namespace, the storage exposed by an NVMe
+// subsystem as a block device.
+type NVMeNamespace struct {
+	// ID is the namespace identifier (NSID)
+	ID uint32 `json:"nsid"`
+	// LBADataSizeBytes is the size, in bytes, of the data of a logical block
+	// in the namespace's current LBA format
+	LBADataSizeBytes uint64 `json:"lba_data_size_bytes"`
+	// LBAMetadataSizeBytes is the size, in bytes, of the metadata stored
+	// along with each logical block in the namespace's current LBA format
+	LBAMetadataSizeBytes uint64 `json:"lba_metadata_size_bytes"`
+	// WWID is the World-wide Identifier the kernel built for the namespace
+	// from its NGUID, EUI-64 or UUID
+	WWID string `json:"wwid,omitempty"`
+	// Subsystem is the kernel name of the subsystem the namespace belongs to,
+	// e.g. `nvme-subsys0`
+	Subsystem string `json:"subsystem,omitempty"`
+	// SubsystemNQN is the NVMe Qualified Name of the subsystem the namespace
+	// belongs to
+	SubsystemNQN string `json:"subsystem_nqn"`
+	// Paths contains the controllers the namespace can be accessed through
+	// along with their multipath state
+	Paths []*NVMeNamespacePath `json:"paths"`
+}
@@ -0,0 +1,184 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/pci"
+)
+
+var (
+	regexNVMeController = regexp.MustCompile(`^nvme\d+$`)
+	// regexNVMeNamespace matches both the block device of a namespace, e.g.
+	// nvme0n1, and the per-controller path devices the kernel creates for
+	// the namespaces it handles multipathing for, e.g. nvme0c1n1. The
+	// instance of the namespace block device is the one of the subsystem,
+	// not the one of the controller.
+	regexNVMeNamespace = regexp.MustCompile(`^nvme(\d+)(c\d+)?n(\d+)$`)
+)
+
+var nvmeTransports = map[string]NVMeTransport{
+	"pcie": NVMeTransportPCIe,
+	"tcp":  NVMeTransportTCP,
+	"rdma": NVMeTransportRDMA,
+	"fc":   NVMeTransportFC,
+	"loop": NVMeTransportLoop,
+}
+
+// isNVMePathDevice returns true if the supplied block device name is the one
+// of a hidden multipath device, which only represents one of the paths of a
+// namespace whose block device is listed separately
+func isNVMePathDevice(dname string) bool {
+	m := regexNVMeNamespace.FindStringSubmatch(dname)
+	return m != nil && m[2] != ""
+}
+
+// nvmeControllers returns the NVMe controllers found in /sys/class/nvme along
+// with the paths of the namespaces attached to them, keyed by the name of the
+// namespace block device
+func nvmeControllers(
+	paths *linuxpath.Paths,
+) ([]*NVMeController, map[string][]*NVMeNamespacePath) {
+	nsPaths := map[string][]*NVMeNamespacePath{}
+	entries, err := os.ReadDir(paths.SysClassNVMe)
+	if err != nil {
+		return nil, nsPaths
+	}
+	subsystems := nvmeSubsystems(paths)
+	ctrls := []*NVMeController{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !regexNVMeController.MatchString(name) {
+			continue
+		}
+		path := filepath.Join(paths.SysClassNVMe, name)
+		id, err := strconv.Atoi(sysfsAttr(path, "cntlid"))
+		if err != nil {
+			id = -1
+		}
+		ctrl := &NVMeController{
+			Name:             name,
+			ID:               id,
+			Model:            sysfsAttr(path, "model"),
+			SerialNumber:     sysfsAttr(path, "serial"),
+			FirmwareRevision: sysfsAttr(path, "firmware_rev"),
+			Transport:        nvmeTransports[sysfsAttr(path, "transport")],
+			Address:          sysfsAttr(path, "address"),
+			State:            sysfsAttr(path, "state"),
+			Subsystem:        subsystems[name],
+			SubsystemNQN:     sysfsAttr(path, "subsysnqn"),
+			Namespaces:       []string{},
+		}
+		if ctrl.Transport == NVMeTransportPCIe && ctrl.Address == "" {
+			// Kernels older than 4.20 have no address attribute for PCIe
+			// controllers, whose device link points to the PCI device
+			if dest, err := os.Readlink(filepath.Join(path, "device")); err == nil {
+				ctrl.Address = filepath.Base(dest)
+			}
+		}
+
+		// The controller directory contains one directory per namespace
+		// attached to the controller, named after the namespace block device
+		// or, for multipath namespaces, after the path device
+		children, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			m := regexNVMeNamespace.FindStringSubmatch(child.Name())
+			if m == nil {
+				continue
+			}
+			nsName := "nvme" + m[1] + "n" + m[3]
+			nsPaths[nsName] = append(nsPaths[nsName], &NVMeNamespacePath{
+				Name:           child.Name(),
+				ControllerName: name,
+				Controller:     ctrl,
+				ANAState:       sysfsAttr(filepath.Join(path, child.Name()), "ana_state"),
+			})
+			ctrl.Namespaces = append(ctrl.Namespaces, nsName)
+		}
+		sort.Strings(ctrl.Namespaces)
+		ctrls = append(ctrls, ctrl)
+	}
+	return ctrls, nsPaths
+}
+
+// nvmeSubsystems returns the name of the subsystem of each controller, keyed
+// by controller name. Each /sys/class/nvme-subsystem/nvme-subsysN directory
+// contains links to the controllers of the subsystem.
+func nvmeSubsystems(paths *linuxpath.Paths) map[string]string {
+	subsystems := map[string]string{}
+	entries, err := os.ReadDir(paths.SysClassNVMeSubsystem)
+	if err != nil {
+		return subsystems
+	}
+	for _, entry := range entries {
+		children, err := os.ReadDir(filepath.Join(paths.SysClassNVMeSubsystem, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if regexNVMeController.MatchString(child.Name()) {
+				subsystems[child.Name()] = entry.Name()
+			}
+		}
+	}
+	return subsystems
+}
+
+// nvmeNamespace returns the NVMe namespace information of the supplied disk,
+// accessed through the supplied paths
+func nvmeNamespace(
+	paths *linuxpath.Paths,
+	disk string,
+	nsPaths []*NVMeNamespacePath,
+) *NVMeNamespace {
+	path := filepath.Join(paths.SysBlock, disk)
+	ns := &NVMeNamespace{
+		WWID:  sysfsAttr(path, "wwid"),
+		Paths: nsPaths,
+	}
+	if nsPaths == nil {
+		ns.Paths = []*NVMeNamespacePath{}
+	}
+	if nsid, err := strconv.ParseUint(sysfsAttr(path, "nsid"), 10, 32); err == nil {
+		ns.ID = uint32(nsid)
+	}
+	ns.LBADataSizeBytes, _ = strconv.ParseUint(sysfsAttr(path, "queue/logical_block_size"), 10, 64)
+	ns.LBAMetadataSizeBytes, _ = strconv.ParseUint(sysfsAttr(path, "metadata_bytes"), 10, 64)
+	if len(nsPaths) > 0 {
+		ns.Subsystem = nsPaths[0].Controller.Subsystem
+		ns.SubsystemNQN = nsPaths[0].Controller.SubsystemNQN
+	}
+	return ns
+}
+
+// fillNVMe sets the NVMe namespace information of the NVMe disks and links
+// the PCIe controllers to their PCI device
+func (i *Info) fillNVMe(paths *linuxpath.Paths, pciDevice func(string) *pci.Device) {
+	ctrls, nsPaths := nvmeControllers(paths)
+	if len(ctrls) == 0 {
+		return
+	}
+	i.NVMeControllers = ctrls
+	for _, d := range i.Disks {
+		if d.StorageController == StorageControllerNVMe {
+			d.NVMe = nvmeNamespace(paths, d.Name, nsPaths[d.Name])
+		}
+	}
+	for _, ctrl := range ctrls {
+		if ctrl.Transport == NVMeTransportPCIe && ctrl.Address != "" {
+			ctrl.PCI = pciDevice(ctrl.Address)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PartitionTableType describes the format of a disk's partition table
+type PartitionTableType int
+
+const (
+	// PartitionTableTypeUnknown means we could not find a partition table we
+	// know how to decode
+	PartitionTableTypeUnknown PartitionTableType = iota
+	// PartitionTableTypeGPT indicates a GUID Partition Table
+	PartitionTableTypeGPT
+	// PartitionTableTypeMBR indicates a Master Boot Record (DOS) partition
+	// table
+	PartitionTableTypeMBR
+)
+
+var (
+	partitionTableTypeString = map[PartitionTableType]string{
+		PartitionTableTypeUnknown: "Unknown",
+		PartitionTableTypeGPT:     "GPT",
+		PartitionTableTypeMBR:     "MBR",
+	}
+
+	// used by PartitionTableType::UnmarshalJSON, hence the lowercase keys
+	stringPartitionTableType = map[string]PartitionTableType{
+		"unknown": PartitionTableTypeUnknown,
+		"gpt":     PartitionTableTypeGPT,
+		"mbr":     PartitionTableTypeMBR,
+	}
+)
+
+func (t PartitionTableType) String() string {
+	return partitionTableTypeString[t]
+}
+
+// MarshalJSON lowercases the partition table type, like for the other enums
+// of this package
+func (t PartitionTableType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(t.String()))), nil
+}
+
+func (t *PartitionTableType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringPartitionTableType[key]
+	if !ok {
+		return fmt.Errorf("unknown partition table type: %q", key)
+	}
+	*t = val
+	return nil
+}
+
+// PartitionTable describes the partition table of a disk, as decoded from
+// the disk itself.
+type PartitionTable struct {
+	// Type is the format of the partition table
+	Type PartitionTableType `json:"type"`
+	// ID is the disk GUID of GPT disks, or the hexadecimal disk signature of
+	// MBR disks, e.g. `0e8c9ea4`
+	ID string `json:"id"`
+	// LogicalBlockSizeBytes is the size of the logical blocks the partition
+	// table addresses
+	LogicalBlockSizeBytes uint64 `json:"logical_block_size_bytes"`
+	// Entries contains an array of pointers to `PartitionTableEntry` structs,
+	// one for each used entry of the partition table
+	Entries []*PartitionTableEntry `json:"entries"`
+}
+
+// PartitionTableEntry describes a partition as recorded in the partition
+// table of a disk.
+type PartitionTableEntry struct {
+	// Number is the number of the partition, as used by the kernel to name
+	// the partition's block device, e.g. 1 for `sda1`. Logical partitions of
+	// MBR disks are numbered from 5.
+	Number int `json:"number"`
+	// Type is the partition type GUID of GPT partitions, or the hexadecimal
+	// partition type of MBR partitions, e.g. `0x83`
+	Type string `json:"type"`
+	// TypeName is a friendly name for Type, e.g. `EFI System`, or empty if
+	// the type is not a well-known one
+	TypeName string `json:"type_name,omitempty"`
+	// UUID is the unique partition GUID of GPT partitions. For MBR partitions
+	// this is the disk signature followed by the partition number, e.g.
+	// `0e8c9ea4-01`, like the PARTUUID udev and blkid report.
+	UUID string `json:"uuid"`
+	// Name is the name of GPT partitions
+	Name string `json:"name,omitempty"`
+	// Attributes contains the attribute flags of GPT partitions, or the boot
+	// indicator of MBR partitions
+	Attributes uint64 `json:"attributes"`
+	// AttributeNames contains the names of the well-known flags set in
+	// Attributes, e.g. `legacy-bios-bootable` or `bootable`
+	AttributeNames []string `json:"attribute_names,omitempty"`
+	// StartLBA is the first logical block of the partition
+	StartLBA uint64 `json:"start_lba"`
+	// EndLBA is the last logical block of the partition, inclusive
+	EndLBA uint64 `json:"end_lba"`
+}
+
+// entry returns the entry of the partition with the supplied number, or nil
+// if there is none
+func (pt *PartitionTable) entry(number int) *PartitionTableEntry {
+	if pt == nil {
+		return nil
+	}
+	for _, pte := range pt.Entries {
+		if pte.Number == number {
+			return pte
+		}
+	}
+	return nil
+}
+
+var (
+	errNoPartitionTable = errors.New("no partition table found")
+
+	gptSignature = []byte("EFI PART")
+
+	// well-known GPT partition type GUIDs, see
+	// https://en.wikipedia.org/wiki/GUID_Partition_Table#Partition_type_GUIDs
+	// and the Discoverable Partitions Specification of the UAPI group
+	gptTypeNames = map[string]string{
+		"c12a7328-f81f-11d2-ba4b-00a0c93ec93b": "EFI System",
+		"21686148-6449-6e6f-744e-656564454649": "BIOS boot",
+		"024dee41-33e7-11d3-9d69-0008c781f39f": "MBR partition scheme",
+		"e3c9e316-0b5c-4db8-817d-f92df00215ae": "Microsoft reserved",
+		"ebd0a0a2-b9e5-4433-87c0-68b6b72699c7": "Microsoft basic data",
+		"de94bba4-06d1-4d40-a16a-bfd50179d6ac": "Windows recovery environment",
+		"5808c8aa-7e8f-42e0-85d2-e1e90434cfb3": "Microsoft LDM metadata",
+		"af9b60a0-1431-4f62-bc68-3311714a69ad": "Microsoft LDM data",
+		"0fc63daf-8483-4772-8e79-3d69d8477de4": "Linux filesystem",
+		"0657fd6d-a4ab-43c4-84e5-0933c84b4f4f": "Linux swap",
+		"e6d6d379-f507-44c2-a23c-238f2a3df928": "Linux LVM",
+		"a19d880f-05fc-4d3b-a006-743f0f84911e": "Linux RAID",
+		"ca7d7ccb-63ed-4c53-861c-1742536059cc": "Linux LUKS",
+		"bc13c2ff-59e6-4262-a352-b275fd6f7172": "Linux extended boot",
+		"933ac7e1-2eb4-4f13-b844-0e14e2aef915": "Linux home",
+		"3b8f8425-20e0-4f3b-907f-1a25a76f98e8": "Linux server data",
+		"4d21b016-b534-45c2-a9fb-5c16e091fd2d": "Linux variable data",
+		"7ec6f557-3bc5-4aca-b293-16ef5df639d1": "Linux temporary data",
+		"8da63339-0007-60c0-c436-083ac8230908": "Linux reserved",
+		"44479540-f297-41b2-9af7-d131d5f0458a": "Linux root (x86)",
+		"4f68bce3-e8cd-4db1-96e7-fbcaf984b709": "Linux root (x86-64)",
+		"69dad710-2ce4-4e3c-b16c-21a1d49abed3": "Linux root (ARM)",
+		"b921b045-1df0-41c3-af44-4c6f280d3fae": "Linux root (ARM64)",
+		"72ec70a6-cf74-40e6-bd49-4bda08e8f224": "Linux root (RISC-V 64)",
+		"8484680c-9521-48c6-9c11-b0720656f69e": "Linux /usr (x86-64)",
+		"b0e01050-ee5f-4390-949a-9101b17104e9": "Linux /usr (ARM64)",
+		"6a898cc3-1dd2-11b2-99a6-080020736631": "Solaris /usr & Apple ZFS",
+		"516e7cb6-6ecf-11d6-8ff8-00022d09712b": "FreeBSD UFS",
+		"83bd6b9d-7f41-11dc-be0b-001560b84f0f": "FreeBSD boot",
+		"516e7cb4-6ecf-11d6-8ff8-00022d09712b": "FreeBSD data",
+		"516e7cb5-6ecf-11d6-8ff8-00022d09712b": "FreeBSD swap",
+		"516e7cba-6ecf-11d6-8ff8-00022d09712b": "FreeBSD ZFS",
+		"48465300-0000-11aa-aa11-00306543ecac": "Apple HFS+",
+		"7c3457ef-0000-11aa-aa11-00306543ecac": "Apple APFS",
+		"426f6f74-0000-11aa-aa11-00306543ecac": "Apple boot",
+		"6a85cf4d-1dd2-11b2-99a6-080020736631": "Solaris root",
+		"fe3a2a5d-4f32-41a7-b725-accc3285a309": "ChromeOS kernel",
+		"3cb8e202-3b7e-47dd-8a3c-7ff2a13cfcec": "ChromeOS root",
+		"45b0969e-9b03-4f30-b4c6-b4b80ceff106": "Ceph journal",
+		"4fbd7e29-9d25-41b8-afd0-062c0ceff05d": "Ceph OSD",
+		"aa31e02a-400f-11db-9590-000c2911d1b8": "VMware VMFS",
+	}
+
+	// well-known GPT attribute flags, keyed by bit number. Bits 0 to 2 apply
+	// to all the partitions, bits 60 to 63 are defined by the partition type
+	// but Microsoft and the Discoverable Partitions Specification agree on
+	// them.
+	gptAttributeNames = map[uint]string{
+		0:  "required",
+		1:  "no-block-io",
+		2:  "legacy-bios-bootable",
+		60: "read-only",
+		61: "shadow-copy",
+		62: "hidden",
+		63: "no-automount",
+	}
+
+	// well-known MBR partition types
+	mbrTypeNames = map[byte]string{
+		0x01: "FAT12",
+		0x04: "FAT16 <32M",
+		0x05: "Extended",
+		0x06: "FAT16",
+		0x07: "HPFS/NTFS/exFAT",
+		0x0b: "W95 FAT32",
+		0x0c: "W95 FAT32 (LBA)",
+		0x0e: "W95 FAT16 (LBA)",
+		0x0f: "W95 extended (LBA)",
+		0x11: "Hidden FAT12",
+		0x12: "Compaq diagnostics",
+		0x14: "Hidden FAT16 <32M",
+		0x16: "Hidden FAT16",
+		0x17: "Hidden HPFS/NTFS",
+		0x1b: "Hidden W95 FAT32",
+		0x1c: "Hidden W95 FAT32 (LBA)",
+		0x1e: "Hidden W95 FAT16 (LBA)",
+		0x27: "Hidden NTFS WinRE",
+		0x42: "SFS",
+		0x82: "Linux swap / Solaris",
+		0x83: "Linux",
+		0x85: "Linux extended",
+		0x86: "NTFS volume set",
+		0x87: "NTFS volume set",
+		0x8e: "Linux LVM",
+		0xa5: "FreeBSD",
+		0xa6: "OpenBSD",
+		0xa8: "Darwin UFS",
+		0xa9: "NetBSD",
+		0xaf: "HFS / HFS+",
+		0xbe: "Solaris boot",
+		0xbf: "Solaris",
+		0xda: "Non-FS data",
+		0xde: "Dell Utility",
+		0xee: "GPT",
+		0xef: "EFI (FAT-12/16/32)",
+		0xfb: "VMware VMFS",
+		0xfc: "VMware VMKCORE",
+		0xfd: "Linux raid autodetect",
+	}
+)
+
+const (
+	mbrSignatureOffset   = 510
+	mbrDiskIDOffset      = 440
+	mbrEntriesOffset     = 446
+	mbrEntrySize         = 16
+	mbrTypeGPTProtective = 0xee
+	mbrBootIndicator     = 0x80
+	// maxLogicalPartitions bounds the walk of the extended boot record chain,
+	// which a corrupted disk could make circular
+	maxLogicalPartitions = 128
+	// maxGPTEntriesSize bounds the size of the GPT partition entry array a
+	// corrupted header could make us read. The usual array is 16KiB.
+	maxGPTEntriesSize = 1 << 20
+)
+
+// readPartitionTable decodes the partition table of the disk whose content is
+// supplied. GPT is tried first, falling back to the backup GPT header at the
+// end of the disk when the primary one is corrupted, then MBR.
+func readPartitionTable(
+	r io.ReaderAt,
+	lbaSize uint64,
+	sizeBytes uint64,
+) (*PartitionTable, error) {
+	if lbaSize < 512 {
+		lbaSize = 512
+	}
+	mbr := make([]byte, lbaSize)
+	if _, err := r.ReadAt(mbr, 0); err != nil {
+		return nil, err
+	}
+	if mbr[mbrSignatureOffset] != 0x55 || mbr[mbrSignatureOffset+1] != 0xaa {
+		// MBR signature is missing, but a GPT disk could have had its
+		// protective MBR wiped out
+		if pt, err := readGPT(r, lbaSize, 1); err == nil {
+			return pt, nil
+		}
+		return nil, errNoPartitionTable
+	}
+
+	isGPT := false
+	for i := 0; i < 4; i++ {
+		if mbr[mbrEntriesOffset+i*mbrEntrySize+4] == mbrTypeGPTProtective {
+			isGPT = true
+		}
+	}
+	if isGPT {
+		pt, err := readGPT(r, lbaSize, 1)
+		if err == nil {
+			return pt, nil
+		}
+		if sizeBytes/lbaSize > 1 {
+			if pt, backupErr := readGPT(r, lbaSize, sizeBytes/lbaSize-1); backupErr == nil {
+				return pt, nil
+			}
+		}
+		return nil, err
+	}
+	return readMBR(r, lbaSize, mbr)
+}
+
+// readGPT decodes the GPT whose header is at the supplied LBA
+func readGPT(r io.ReaderAt, lbaSize uint64, headerLBA uint64) (*PartitionTable, error) {
+	header := make([]byte, lbaSize)
+	if _, err := r.ReadAt(header, int64(headerLBA*lbaSize)); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[0:8], gptSignature) {
+		return nil, errNoPartitionTable
+	}
+	headerSize := binary.LittleEndian.Uint32(header[12:16])
+	if headerSize < 92 || uint64(headerSize) > lbaSize {
+		return nil, fmt.Errorf("invalid GPT header size %d", headerSize)
+	}
+	// The header CRC is computed with the CRC field zeroed
+	headerCRC := binary.LittleEndian.Uint32(header[16:20])
+	check := make([]byte, headerSize)
+	copy(check, header[:headerSize])
+	binary.LittleEndian.PutUint32(check[16:20], 0)
+	if crc32.ChecksumIEEE(check) != headerCRC {
+		return nil, errors.New("GPT header checksum mismatch")
+	}
+
+	entriesLBA := binary.LittleEndian.Uint64(header[72:80])
+	numEntries := binary.LittleEndian.Uint32(header[80:84])
+	entrySize := binary.LittleEndian.Uint32(header[84:88])
+	entriesCRC := binary.LittleEndian.Uint32(header[88:92])
+	if entrySize < 128 || uint64(numEntries)*uint64(entrySize) > maxGPTEntriesSize {
+		return nil, fmt.Errorf("invalid GPT partition entry array of %d entries of %d bytes", numEntries, entrySize)
+	}
+	entries := make([]byte, numEntries*entrySize)
+	if _, err := r.ReadAt(entries, int64(entriesLBA*lbaSize)); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(entries) != entriesCRC {
+		return nil, errors.New("GPT partition entry array checksum mismatch")
+	}
+
+	pt := &PartitionTable{
+		Type:                  PartitionTableTypeGPT,
+		ID:                    guidString(header[56:72]),
+		LogicalBlockSizeBytes: lbaSize,
+		Entries:               []*PartitionTableEntry{},
+	}
+	var zeroGUID [16]byte
+	for i := uint32(0); i < numEntries; i++ {
+		entry := entries[i*entrySize : (i+1)*entrySize]
+		if bytes.Equal(entry[0:16], zeroGUID[:]) {
+			// unused entry
+			continue
+		}
+		typeGUID := guidString(entry[0:16])
+		attrs := binary.LittleEndian.Uint64(entry[48:56])
+		pte := &PartitionTableEntry{
+			Number:     int(i) + 1,
+			Type:       typeGUID,
+			TypeName:   gptTypeNames[typeGUID],
+			UUID:       guidString(entry[16:32]),
+			Name:       utf16String(entry[56:128]),
+			Attributes: attrs,
+			StartLBA:   binary.LittleEndian.Uint64(entry[32:40]),
+			EndLBA:     binary.LittleEndian.Uint64(entry[40:48]),
+		}
+		for bit := uint(0); bit < 64; bit++ {
+			if name, ok := gptAttributeNames[bit]; ok && attrs&(1<<bit) != 0 {
+				pte.AttributeNames = append(pte.AttributeNames, name)
+			}
+		}
+		pt.Entries = append(pt.Entries, pte)
+	}
+	return pt, nil
+}
+
+// readMBR decodes the MBR partition table in the supplied first block of a
+// disk, following the chain of extended boot records of an extended partition
+// to find the logical partitions
+func readMBR(r io.ReaderAt, lbaSize uint64, mbr []byte) (*PartitionTable, error) {
+	diskID := binary.LittleEndian.Uint32(mbr[mbrDiskIDOffset : mbrDiskIDOffset+4])
+	pt := &PartitionTable{
+		Type:                  PartitionTableTypeMBR,
+		ID:                    fmt.Sprintf("%08x", diskID),
+		LogicalBlockSizeBytes: lbaSize,
+		Entries:               []*PartitionTableEntry{},
+	}
+	var extendedStart uint64
+	for i := 0; i < 4; i++ {
+		pte := mbrEntry(mbr[mbrEntriesOffset+i*mbrEntrySize:], 0)
+		if pte == nil {
+			continue
+		}
+		pte.Number = i + 1
+		if isExtendedMBRType(mbr[mbrEntriesOffset+i*mbrEntrySize+4]) && extendedStart == 0 {
+			extendedStart = pte.StartLBA
+		}
+		pt.Entries = append(pt.Entries, pte)
+	}
+
+	// Each extended boot record holds a logical partition, whose start is
+	// relative to the record, and a link to the next record, whose start is
+	// relative to the extended partition
+	ebrLBA := extendedStart
+	ebr := make([]byte, lbaSize)
+	for n := 5; ebrLBA != 0 && n < 5+maxLogicalPartitions; n++ {
+		if _, err := r.ReadAt(ebr, int64(ebrLBA*lbaSize)); err != nil {
+			break
+		}
+		if ebr[mbrSignatureOffset] != 0x55 || ebr[mbrSignatureOffset+1] != 0xaa {
+			break
+		}
+		if pte := mbrEntry(ebr[mbrEntriesOffset:], ebrLBA); pte != nil {
+			pte.Number = n
+			pt.Entries = append(pt.Entries, pte)
+		}
+		next := binary.LittleEndian.Uint32(ebr[mbrEntriesOffset+mbrEntrySize+8:])
+		if next == 0 {
+			break
+		}
+		ebrLBA = extendedStart + uint64(next)
+	}
+	for _, pte := range pt.Entries {
+		pte.UUID = fmt.Sprintf("%s-%02x", pt.ID, pte.Number)
+	}
+	return pt, nil
+}
+
+// mbrEntry decodes a 16 bytes MBR partition entry whose start LBA is relative
+// to the supplied base, returning nil for unused entries
+func mbrEntry(entry []byte, base uint64) *PartitionTableEntry {
+	ptype := entry[4]
+	sectors := binary.LittleEndian.Uint32(entry[12:16])
+	if ptype == 0 || sectors == 0 {
+		return nil
+	}
+	start := base + uint64(binary.LittleEndian.Uint32(entry[8:12]))
+	pte := &PartitionTableEntry{
+		Type:       fmt.Sprintf("0x%02x", ptype),
+		TypeName:   mbrTypeNames[ptype],
+		Attributes: uint64(entry[0]),
+		StartLBA:   start,
+		EndLBA:     start + uint64(sectors) - 1,
+	}
+	if entry[0] == mbrBootIndicator {
+		pte.AttributeNames = []string{"bootable"}
+	}
+	return pte
+}
+
+func isExtendedMBRType(ptype byte) bool {
+	return ptype == 0x05 || ptype == 0x0f || ptype == 0x85
+}
+
+// guidString returns the canonical lowercase representation of a GUID stored
+// on disk, whose first three fields are little endian
+func guidString(b []byte) string {
+	return fmt.Sprintf(
+		"%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10],
+		b[10:16],
+	)
+}
+
+// utf16String decodes a NUL-padded UTF-16LE string
+func utf16String(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u := binary.LittleEndian.Uint16(b[i:])
+		if u == 0 {
+			break
+		}
+		units = append(units, u)
+	}
+	return string(utf16.Decode(units))
+}
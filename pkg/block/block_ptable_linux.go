@@ -0,0 +1,81 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// diskPartitionTable decodes the partition table of a disk from its device
+// node, e.g. /dev/sda. Reading the device node usually requires root
+// privileges, and the device nodes are often missing from containers, so
+// failing to read it is not an error.
+func diskPartitionTable(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	disk string,
+	driveType DriveType,
+	sizeBytes uint64,
+) *PartitionTable {
+	// Don't wake up optical and floppy drives, which are not partitioned
+	// anyway
+	if sizeBytes == 0 || driveType == DriveTypeODD || driveType == DriveTypeFDD {
+		return nil
+	}
+	path := filepath.Join(paths.DevRoot, disk)
+	f, err := os.Open(path)
+	if err != nil {
+		log.Debug(ctx, "failed to open %s to read its partition table: %s", path, err)
+		return nil
+	}
+	defer util.SafeClose(f)
+
+	lbaSize, _ := strconv.ParseUint(sysfsAttr(filepath.Join(paths.SysBlock, disk, "queue"), "logical_block_size"), 10, 64)
+	pt, err := readPartitionTable(f, lbaSize, sizeBytes)
+	if err != nil {
+		log.Debug(ctx, "failed to read the partition table of %s: %s", path, err)
+		return nil
+	}
+	return pt
+}
+
+// partitionNumber returns the number of a partition from
+// /sys/block/$DEVICE/$PARTITION/partition, or 0 if unknown
+func partitionNumber(paths *linuxpath.Paths, disk string, part string) int {
+	n, err := strconv.Atoi(sysfsAttr(filepath.Join(paths.SysBlock, disk, part), "partition"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// setPartitionTableEntry links a partition to its partition table entry. The
+// UUID and label of the partition, which come from udev, fall back to the
+// ones in the entry when udev does not know them, and a mismatch between
+// both is reported.
+func setPartitionTableEntry(
+	ctx context.Context,
+	p *Partition,
+	entry *PartitionTableEntry,
+) {
+	p.TableEntry = entry
+	if p.UUID == util.UNKNOWN {
+		p.UUID = entry.UUID
+	} else if !strings.EqualFold(p.UUID, entry.UUID) {
+		log.Warn(ctx, "partition %s has UUID %s in udev but %s in its partition table", p.Name, p.UUID, entry.UUID)
+	}
+	if p.Label == util.UNKNOWN && entry.Name != "" {
+		p.Label = entry.Name
+	}
+}
@@ -0,0 +1,242 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+const testLBASize = 512
+
+// guidBytes returns the on-disk representation of a canonical GUID string,
+// whose first three fields are little endian
+func guidBytes(t *testing.T, guid string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(guid, "-", ""))
+	if err != nil || len(b) != 16 {
+		t.Fatalf("Expected a valid GUID, but got %s", guid)
+	}
+	for _, field := range [][]byte{b[0:4], b[4:6], b[6:8]} {
+		for i, j := 0, len(field)-1; i < j; i, j = i+1, j-1 {
+			field[i], field[j] = field[j], field[i]
+		}
+	}
+	return b
+}
+
+type testGPTEntry struct {
+	typeGUID string
+	uuid     string
+	name     string
+	attrs    uint64
+	start    uint64
+	end      uint64
+}
+
+// buildGPTDisk returns the image of a disk of the supplied number of blocks,
+// with a protective MBR, a primary GPT at LBA 1 and a backup GPT at the last
+// LBA, with 128 entries of 128 bytes each
+func buildGPTDisk(t *testing.T, numBlocks uint64, diskGUID string, entries []testGPTEntry) []byte {
+	disk := make([]byte, numBlocks*testLBASize)
+	disk[mbrEntriesOffset+4] = mbrTypeGPTProtective
+	disk[mbrSignatureOffset] = 0x55
+	disk[mbrSignatureOffset+1] = 0xaa
+
+	array := make([]byte, 128*128)
+	for i, entry := range entries {
+		e := array[i*128:]
+		copy(e[0:16], guidBytes(t, entry.typeGUID))
+		copy(e[16:32], guidBytes(t, entry.uuid))
+		binary.LittleEndian.PutUint64(e[32:40], entry.start)
+		binary.LittleEndian.PutUint64(e[40:48], entry.end)
+		binary.LittleEndian.PutUint64(e[48:56], entry.attrs)
+		for j, u := range utf16.Encode([]rune(entry.name)) {
+			binary.LittleEndian.PutUint16(e[56+2*j:], u)
+		}
+	}
+	arrayBlocks := uint64(len(array) / testLBASize)
+
+	writeHeader := func(headerLBA uint64, backupLBA uint64, arrayLBA uint64) {
+		copy(disk[arrayLBA*testLBASize:], array)
+		h := disk[headerLBA*testLBASize:]
+		copy(h[0:8], gptSignature)
+		binary.LittleEndian.PutUint32(h[8:12], 0x00010000)
+		binary.LittleEndian.PutUint32(h[12:16], 92)
+		binary.LittleEndian.PutUint64(h[24:32], headerLBA)
+		binary.LittleEndian.PutUint64(h[32:40], backupLBA)
+		binary.LittleEndian.PutUint64(h[40:48], 2+arrayBlocks)
+		binary.LittleEndian.PutUint64(h[48:56], numBlocks-2-arrayBlocks)
+		copy(h[56:72], guidBytes(t, diskGUID))
+		binary.LittleEndian.PutUint64(h[72:80], arrayLBA)
+		binary.LittleEndian.PutUint32(h[80:84], 128)
+		binary.LittleEndian.PutUint32(h[84:88], 128)
+		binary.LittleEndian.PutUint32(h[88:92], crc32.ChecksumIEEE(array))
+		binary.LittleEndian.PutUint32(h[16:20], crc32.ChecksumIEEE(h[0:92]))
+	}
+	writeHeader(1, numBlocks-1, 2)
+	writeHeader(numBlocks-1, 1, numBlocks-1-arrayBlocks)
+	return disk
+}
+
+func TestReadPartitionTableGPT(t *testing.T) {
+	numBlocks := uint64(2048)
+	entries := []testGPTEntry{
+		{
+			typeGUID: "c12a7328-f81f-11d2-ba4b-00a0c93ec93b",
+			uuid:     "8e6b1a52-4a8b-4c7e-9a39-3f5c2d1e0b71",
+			name:     "EFI System Partition",
+			attrs:    1 << 0,
+			start:    34,
+			end:      1033,
+		},
+		{
+			typeGUID: "0fc63daf-8483-4772-8e79-3d69d8477de4",
+			uuid:     "f2d1c3b4-5a69-4788-9b0c-1d2e3f405162",
+			name:     "root",
+			attrs:    1<<60 | 1<<63,
+			start:    1034,
+			end:      2014,
+		},
+	}
+	disk := buildGPTDisk(t, numBlocks, "4c3e9f1a-2b7d-4e58-a6c0-9d8e7f6a5b4c", entries)
+
+	expected := &PartitionTable{
+		Type:                  PartitionTableTypeGPT,
+		ID:                    "4c3e9f1a-2b7d-4e58-a6c0-9d8e7f6a5b4c",
+		LogicalBlockSizeBytes: testLBASize,
+		Entries: []*PartitionTableEntry{
+			{
+				Number:         1,
+				Type:           "c12a7328-f81f-11d2-ba4b-00a0c93ec93b",
+				TypeName:       "EFI System",
+				UUID:           "8e6b1a52-4a8b-4c7e-9a39-3f5c2d1e0b71",
+				Name:           "EFI System Partition",
+				Attributes:     1,
+				AttributeNames: []string{"required"},
+				StartLBA:       34,
+				EndLBA:         1033,
+			},
+			{
+				Number:         2,
+				Type:           "0fc63daf-8483-4772-8e79-3d69d8477de4",
+				TypeName:       "Linux filesystem",
+				UUID:           "f2d1c3b4-5a69-4788-9b0c-1d2e3f405162",
+				Name:           "root",
+				Attributes:     1<<60 | 1<<63,
+				AttributeNames: []string{"read-only", "no-automount"},
+				StartLBA:       1034,
+				EndLBA:         2014,
+			},
+		},
+	}
+	pt, err := readPartitionTable(bytes.NewReader(disk), testLBASize, uint64(len(disk)))
+	if err != nil {
+		t.Fatalf("Expected no error reading the GPT, but got %v", err)
+	}
+	if !reflect.DeepEqual(pt, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, pt)
+	}
+
+	// Corrupt the primary header, the backup one at the end of the disk
+	// should be used
+	disk[testLBASize+56] ^= 0xff
+	pt, err = readPartitionTable(bytes.NewReader(disk), testLBASize, uint64(len(disk)))
+	if err != nil {
+		t.Fatalf("Expected no error reading the backup GPT, but got %v", err)
+	}
+	if !reflect.DeepEqual(pt, expected) {
+		t.Fatalf("Expected %+v from the backup GPT, but got %+v", expected, pt)
+	}
+
+	// Corrupt the backup header too
+	disk[(numBlocks-1)*testLBASize+56] ^= 0xff
+	if _, err = readPartitionTable(bytes.NewReader(disk), testLBASize, uint64(len(disk))); err == nil {
+		t.Fatalf("Expected an error reading a GPT with corrupted headers, but got nil")
+	}
+}
+
+func TestReadPartitionTableMBR(t *testing.T) {
+	disk := make([]byte, 4096*testLBASize)
+	writeEntry := func(sector uint64, index int, status byte, ptype byte, start uint32, sectors uint32) {
+		e := disk[sector*testLBASize+mbrEntriesOffset+uint64(index*mbrEntrySize):]
+		e[0] = status
+		e[4] = ptype
+		binary.LittleEndian.PutUint32(e[8:12], start)
+		binary.LittleEndian.PutUint32(e[12:16], sectors)
+		disk[sector*testLBASize+mbrSignatureOffset] = 0x55
+		disk[sector*testLBASize+mbrSignatureOffset+1] = 0xaa
+	}
+	binary.LittleEndian.PutUint32(disk[mbrDiskIDOffset:], 0x0e8c9ea4)
+	writeEntry(0, 0, mbrBootIndicator, 0x83, 2048, 1024)
+	writeEntry(0, 1, 0, 0x05, 3072, 1024)
+	// first logical partition, and the link to the second EBR
+	writeEntry(3072, 0, 0, 0x82, 32, 256)
+	writeEntry(3072, 1, 0, 0x05, 512, 512)
+	// second and last logical partition
+	writeEntry(3584, 0, 0, 0x8e, 32, 480)
+
+	expected := &PartitionTable{
+		Type:                  PartitionTableTypeMBR,
+		ID:                    "0e8c9ea4",
+		LogicalBlockSizeBytes: testLBASize,
+		Entries: []*PartitionTableEntry{
+			{
+				Number:         1,
+				Type:           "0x83",
+				TypeName:       "Linux",
+				UUID:           "0e8c9ea4-01",
+				Attributes:     mbrBootIndicator,
+				AttributeNames: []string{"bootable"},
+				StartLBA:       2048,
+				EndLBA:         3071,
+			},
+			{
+				Number:   2,
+				Type:     "0x05",
+				TypeName: "Extended",
+				UUID:     "0e8c9ea4-02",
+				StartLBA: 3072,
+				EndLBA:   4095,
+			},
+			{
+				Number:   5,
+				Type:     "0x82",
+				TypeName: "Linux swap / Solaris",
+				UUID:     "0e8c9ea4-05",
+				StartLBA: 3104,
+				EndLBA:   3359,
+			},
+			{
+				Number:   6,
+				Type:     "0x8e",
+				TypeName: "Linux LVM",
+				UUID:     "0e8c9ea4-06",
+				StartLBA: 3616,
+				EndLBA:   4095,
+			},
+		},
+	}
+	pt, err := readPartitionTable(bytes.NewReader(disk), testLBASize, uint64(len(disk)))
+	if err != nil {
+		t.Fatalf("Expected no error reading the MBR, but got %v", err)
+	}
+	if !reflect.DeepEqual(pt, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, pt)
+	}
+
+	// Wipe the signature, there is no partition table anymore
+	disk[mbrSignatureOffset] = 0
+	if _, err = readPartitionTable(bytes.NewReader(disk), testLBASize, uint64(len(disk))); err == nil {
+		t.Fatalf("Expected an error reading a disk with no partition table, but got nil")
+	}
+}
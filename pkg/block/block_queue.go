@@ -0,0 +1,116 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ZonedModel describes how a disk exposes its zones, if any
+type ZonedModel int
+
+const (
+	// ZonedModelNone indicates a regular, non-zoned, disk
+	ZonedModelNone ZonedModel = iota
+	// ZonedModelHostAware indicates a zoned disk also accepting random
+	// writes, e.g. a host-aware SMR drive
+	ZonedModelHostAware
+	// ZonedModelHostManaged indicates a zoned disk only accepting sequential
+	// writes within a zone, e.g. a host-managed SMR drive or an NVMe ZNS
+	// namespace
+	ZonedModelHostManaged
+)
+
+var (
+	zonedModelString = map[ZonedModel]string{
+		ZonedModelNone:        "none",
+		ZonedModelHostAware:   "host-aware",
+		ZonedModelHostManaged: "host-managed",
+	}
+
+	stringZonedModel = map[string]ZonedModel{
+		"none":         ZonedModelNone,
+		"host-aware":   ZonedModelHostAware,
+		"host-managed": ZonedModelHostManaged,
+	}
+)
+
+func (m ZonedModel) String() string {
+	return zonedModelString[m]
+}
+
+// MarshalJSON serializes the zoned model as found in
+// /sys/block/$DEVICE/queue/zoned
+func (m ZonedModel) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+func (m *ZonedModel) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringZonedModel[key]
+	if !ok {
+		return fmt.Errorf("unknown zoned model: %q", key)
+	}
+	*m = val
+	return nil
+}
+
+// DiskQueue describes the request queue of a disk: the limits of the I/O
+// requests the disk accepts and the settings the kernel uses to issue them.
+type DiskQueue struct {
+	// LogicalBlockSizeBytes is the smallest unit the disk can address
+	LogicalBlockSizeBytes uint64 `json:"logical_block_size_bytes"`
+	// MinimumIOSizeBytes is the preferred minimum size of I/O requests, to
+	// avoid read-modify-write cycles, e.g. the physical block size or the
+	// chunk size of RAID arrays
+	MinimumIOSizeBytes uint64 `json:"minimum_io_size_bytes"`
+	// OptimalIOSizeBytes is the preferred size of I/O requests for sustained
+	// streaming, e.g. the stripe width of RAID arrays, or 0 if the disk does
+	// not report one
+	OptimalIOSizeBytes uint64 `json:"optimal_io_size_bytes"`
+	// MaxIOSizeBytes is the largest I/O request the kernel issues to the disk
+	MaxIOSizeBytes uint64 `json:"max_io_size_bytes"`
+	// MaxHardwareIOSizeBytes is the largest I/O request the disk accepts, the
+	// upper bound of MaxIOSizeBytes
+	MaxHardwareIOSizeBytes uint64 `json:"max_hardware_io_size_bytes"`
+	// SupportsDiscard indicates if the disk supports discarding blocks, i.e.
+	// TRIM or UNMAP
+	SupportsDiscard bool `json:"supports_discard"`
+	// DiscardGranularityBytes is the size of the internal allocation unit of
+	// disks supporting discard
+	DiscardGranularityBytes uint64 `json:"discard_granularity_bytes"`
+	// DiscardMaxBytes is the largest discard request the kernel issues to
+	// the disk
+	DiscardMaxBytes uint64 `json:"discard_max_bytes"`
+	// ZonedModel is how the disk exposes its zones, if any
+	ZonedModel ZonedModel `json:"zoned_model"`
+	// NumZones is the number of zones of zoned disks
+	NumZones uint64 `json:"num_zones,omitempty"`
+	// ZoneSizeBytes is the size of the zones of zoned disks
+	ZoneSizeBytes uint64 `json:"zone_size_bytes,omitempty"`
+	// WriteCache is the write cache mode of the disk, either `write back` or
+	// `write through`
+	WriteCache string `json:"write_cache"`
+	// Scheduler is the active I/O scheduler of the disk, e.g. `mq-deadline`
+	// or `none`
+	Scheduler string `json:"scheduler"`
+	// AvailableSchedulers contains the I/O schedulers the disk can use
+	AvailableSchedulers []string `json:"available_schedulers"`
+	// NumRequests is the number of requests that can be queued per hardware
+	// queue of the disk (nr_requests)
+	NumRequests uint64 `json:"num_requests"`
+	// SupportsDAX indicates if the disk supports direct access (DAX), which
+	// bypasses the page cache, e.g. persistent memory
+	SupportsDAX bool `json:"supports_dax"`
+}
@@ -0,0 +1,71 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// diskQueue returns the request queue information of a disk from the
+// /sys/block/$DEVICE/queue directory, or nil if the disk has no such
+// directory
+func diskQueue(paths *linuxpath.Paths, disk string) *DiskQueue {
+	path := filepath.Join(paths.SysBlock, disk, "queue")
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	uintAttr := func(attr string) uint64 {
+		v, _ := strconv.ParseUint(sysfsAttr(path, attr), 10, 64)
+		return v
+	}
+	q := &DiskQueue{
+		LogicalBlockSizeBytes:   uintAttr("logical_block_size"),
+		MinimumIOSizeBytes:      uintAttr("minimum_io_size"),
+		OptimalIOSizeBytes:      uintAttr("optimal_io_size"),
+		MaxIOSizeBytes:          uintAttr("max_sectors_kb") * 1024,
+		MaxHardwareIOSizeBytes:  uintAttr("max_hw_sectors_kb") * 1024,
+		DiscardGranularityBytes: uintAttr("discard_granularity"),
+		DiscardMaxBytes:         uintAttr("discard_max_bytes"),
+		ZonedModel:              stringZonedModel[sysfsAttr(path, "zoned")],
+		WriteCache:              sysfsAttr(path, "write_cache"),
+		NumRequests:             uintAttr("nr_requests"),
+		SupportsDAX:             sysfsAttr(path, "dax") == "1",
+	}
+	// discard_max_bytes is 0 when the disk does not support discard
+	q.SupportsDiscard = q.DiscardMaxBytes > 0
+	if q.ZonedModel != ZonedModelNone {
+		q.NumZones = uintAttr("nr_zones")
+		// chunk_sectors is the zone size of zoned disks
+		q.ZoneSizeBytes = uintAttr("chunk_sectors") * sectorSize
+	}
+	q.Scheduler, q.AvailableSchedulers = parseScheduler(sysfsAttr(path, "scheduler"))
+	return q
+}
+
+// parseScheduler parses the contents of /sys/block/$DEVICE/queue/scheduler,
+// which lists the available I/O schedulers with the active one in brackets,
+// e.g. "mq-deadline kyber [bfq] none". Disks with no scheduler, like
+// device-mapper devices, have "none" there.
+func parseScheduler(contents string) (string, []string) {
+	active := ""
+	available := []string{}
+	for _, field := range strings.Fields(contents) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			field = field[1 : len(field)-1]
+			active = field
+		}
+		available = append(available, field)
+	}
+	if active == "" && len(available) == 1 {
+		active = available[0]
+	}
+	return active, available
+}
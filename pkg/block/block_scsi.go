@@ -0,0 +1,151 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"fmt"
+
+	"github.com/jaypipes/ghw/pkg/pci"
+)
+
+// SCSIAddress is the address of a SCSI device, also known as its H:C:T:L
+// tuple, e.g. `2:0:5:0`
+type SCSIAddress struct {
+	// Host is the number of the SCSI host (HBA) the device is attached to
+	Host int `json:"host"`
+	// Channel is the bus of the host the device is on
+	Channel int `json:"channel"`
+	// Target is the identifier of the target on the bus
+	Target int `json:"target"`
+	// LUN is the logical unit number of the device within the target
+	LUN uint64 `json:"lun"`
+}
+
+func (a SCSIAddress) String() string {
+	return fmt.Sprintf("%d:%d:%d:%d", a.Host, a.Channel, a.Target, a.LUN)
+}
+
+// SCSIHost describes a SCSI host, which is the kernel representation of a
+// host bus adapter (HBA) or of the port of a storage controller, e.g. `host2`
+type SCSIHost struct {
+	// Name is the kernel name of the host, e.g. `host2`
+	Name string `json:"name"`
+	// ID is the number of the host, e.g. 2 for `host2`
+	ID int `json:"id"`
+	// Driver is the name of the driver handling the host, e.g. `mpt3sas` or
+	// `ahci`
+	Driver string `json:"driver"`
+	// SASAddress is the SAS address of the HBA, for SAS HBAs exposing it
+	SASAddress string `json:"sas_address,omitempty"`
+	// PCIAddress is the address of the PCI device of the host, if any
+	PCIAddress string `json:"pci_address,omitempty"`
+	// PCI is a pointer to the PCI device of the host, if any
+	PCI *pci.Device `json:"pci,omitempty"`
+}
+
+func (h *SCSIHost) String() string {
+	return fmt.Sprintf("%s driver=%s [@%s]", h.Name, h.Driver, h.PCIAddress)
+}
+
+// SASExpander describes a SAS expander, the switch of a SAS fabric fanning
+// out the ports of an HBA to many drives, usually part of an enclosure
+type SASExpander struct {
+	// Name is the kernel name of the expander, e.g. `expander-2:0`
+	Name string `json:"name"`
+	// SASAddress is the SAS address of the expander
+	SASAddress string `json:"sas_address"`
+	// Vendor, Product and Revision are the identification of the expander
+	Vendor   string `json:"vendor"`
+	Product  string `json:"product"`
+	Revision string `json:"revision"`
+	// Parent is the name of the expander the expander is attached to, or
+	// the name of the SCSI host, e.g. `host2`, for the expanders attached
+	// directly to the HBA
+	Parent string `json:"parent"`
+}
+
+// EnclosureSlot describes a device slot of a storage enclosure, as reported
+// by its SCSI Enclosure Services (SES) device
+type EnclosureSlot struct {
+	// Name is the name of the slot component, e.g. `Slot 07` or `DISK07`,
+	// which is the one printed on the enclosure for some vendors
+	Name string `json:"name"`
+	// Slot is the number of the slot the enclosure reports, or -1 if it
+	// reports none
+	Slot int `json:"slot"`
+	// Status is the SES status of the slot, e.g. `OK`, `not installed` or
+	// `critical`
+	Status string `json:"status"`
+	// Disk is the name of the block device of the disk in the slot, e.g.
+	// `sdc`, or is empty if the slot is empty or the disk is not attached
+	// to this host
+	Disk string `json:"disk,omitempty"`
+	// SASAddress is the SAS address of the device in the slot
+	SASAddress string `json:"sas_address,omitempty"`
+	// LocateLED indicates whether the locate (identify) LED of the slot is
+	// on. ghw only reports it, it never turns LEDs on or off.
+	LocateLED bool `json:"locate_led"`
+	// FaultLED indicates whether the fault LED of the slot is on
+	FaultLED bool `json:"fault_led"`
+	// Enclosure is a pointer to the enclosure the slot belongs to
+	Enclosure *Enclosure `json:"-"`
+}
+
+func (s *EnclosureSlot) String() string {
+	disk := s.Disk
+	if disk == "" {
+		disk = "empty"
+	}
+	return fmt.Sprintf("%s slot %d (%s) %s", s.Enclosure.Name, s.Slot, s.Name, disk)
+}
+
+// Enclosure describes a storage enclosure (JBOD, disk shelf or backplane)
+// managed through a SCSI Enclosure Services (SES) device
+type Enclosure struct {
+	// Name is the SCSI address of the SES device of the enclosure, e.g.
+	// `2:0:10:0`, which is the name the kernel gives the enclosure
+	Name string `json:"name"`
+	// ID is the logical identifier of the enclosure, usually the SAS address
+	// of its SES device, which stays the same across reboots and hosts
+	ID string `json:"id"`
+	// Vendor and Model are the identification of the SES device
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+	// Slots contains an array of pointers to `EnclosureSlot` structs, one
+	// for each device slot of the enclosure
+	Slots []*EnclosureSlot `json:"slots"`
+}
+
+func (e *Enclosure) String() string {
+	return fmt.Sprintf("enclosure %s id=%s vendor=%s model=%s (%d slots)", e.Name, e.ID, e.Vendor, e.Model, len(e.Slots))
+}
+
+// SCSIDevice describes where a SCSI disk sits in the SCSI and SAS topology
+type SCSIDevice struct {
+	// Address is the H:C:T:L address of the disk
+	Address SCSIAddress `json:"address"`
+	// HostName is the name of the SCSI host the disk is attached to, e.g.
+	// `host2`
+	HostName string `json:"host_name"`
+	// Host is a pointer to the SCSI host the disk is attached to
+	Host *SCSIHost `json:"-"`
+	// SASAddress is the SAS address of the disk, for disks attached to a SAS
+	// HBA, including SATA disks behind one
+	SASAddress string `json:"sas_address,omitempty"`
+	// Expanders contains the names of the SAS expanders between the HBA and
+	// the disk, from the one attached to the HBA down to the one the disk is
+	// attached to
+	Expanders []string `json:"expanders,omitempty"`
+	// EnclosureSlot is a pointer to the enclosure slot the disk sits in, if
+	// an SES device reports it. The enclosure of the slot is available as
+	// EnclosureSlot.Enclosure.
+	EnclosureSlot *EnclosureSlot `json:"-"`
+	// Enclosure and Slot are the name of the enclosure and the number of the
+	// slot the disk sits in, or empty and -1 if they are unknown
+	Enclosure string `json:"enclosure,omitempty"`
+	Slot      int    `json:"slot"`
+}
@@ -0,0 +1,245 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/pci"
+)
+
+var (
+	regexSCSIAddress = regexp.MustCompile(`^(\d+):(\d+):(\d+):(\d+)$`)
+	regexSCSIHost    = regexp.MustCompile(`^host(\d+)$`)
+	regexPCIAddress  = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+)
+
+// parseSCSIAddress parses a H:C:T:L SCSI address, e.g. `2:0:5:0`
+func parseSCSIAddress(s string) (SCSIAddress, bool) {
+	m := regexSCSIAddress.FindStringSubmatch(s)
+	if m == nil {
+		return SCSIAddress{}, false
+	}
+	addr := SCSIAddress{}
+	addr.Host, _ = strconv.Atoi(m[1])
+	addr.Channel, _ = strconv.Atoi(m[2])
+	addr.Target, _ = strconv.Atoi(m[3])
+	addr.LUN, _ = strconv.ParseUint(m[4], 10, 64)
+	return addr, true
+}
+
+// sasPath describes the devices found along the sysfs path of a SCSI or SAS
+// device, e.g.
+// /sys/devices/pci0000:00/0000:00:01.0/0000:03:00.0/host2/port-2:0/expander-2:0/port-2:0:5/end_device-2:0:5/target2:0:5/2:0:5:0
+type sasPath struct {
+	pciAddress string
+	host       string
+	expanders  []string
+	endDevice  string
+}
+
+// parseSASPath returns the devices found along the supplied sysfs path, up
+// to but excluding the component named stop, unless stop is empty
+func parseSASPath(path string, stop string) sasPath {
+	sp := sasPath{}
+	for _, c := range strings.Split(path, string(os.PathSeparator)) {
+		switch {
+		case stop != "" && c == stop:
+			return sp
+		case regexPCIAddress.MatchString(c):
+			sp.pciAddress = c
+		case regexSCSIHost.MatchString(c):
+			sp.host = c
+		case strings.HasPrefix(c, "expander-"):
+			sp.expanders = append(sp.expanders, c)
+		case strings.HasPrefix(c, "end_device-"):
+			sp.endDevice = c
+		}
+	}
+	return sp
+}
+
+// scsiHosts returns the SCSI hosts found in /sys/class/scsi_host
+func scsiHosts(paths *linuxpath.Paths) []*SCSIHost {
+	entries, err := os.ReadDir(paths.SysClassSCSIHost)
+	if err != nil {
+		return nil
+	}
+	hosts := []*SCSIHost{}
+	for _, entry := range entries {
+		name := entry.Name()
+		m := regexSCSIHost.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		path := filepath.Join(paths.SysClassSCSIHost, name)
+		h := &SCSIHost{
+			Name:       name,
+			Driver:     sysfsAttr(path, "proc_name"),
+			SASAddress: sysfsAttr(path, "host_sas_address"),
+		}
+		h.ID, _ = strconv.Atoi(m[1])
+		if dest, err := filepath.EvalSymlinks(path); err == nil {
+			h.PCIAddress = parseSASPath(dest, name).pciAddress
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// sasExpanders returns the SAS expanders found in /sys/class/sas_expander
+func sasExpanders(paths *linuxpath.Paths) []*SASExpander {
+	entries, err := os.ReadDir(paths.SysClassSASExpander)
+	if err != nil {
+		return nil
+	}
+	expanders := []*SASExpander{}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(paths.SysClassSASExpander, name)
+		e := &SASExpander{
+			Name:       name,
+			SASAddress: sysfsAttr(filepath.Join(paths.SysClassSASDevice, name), "sas_address"),
+			Vendor:     sysfsAttr(path, "vendor_id"),
+			Product:    sysfsAttr(path, "product_id"),
+			Revision:   sysfsAttr(path, "product_rev"),
+		}
+		if dest, err := filepath.EvalSymlinks(path); err == nil {
+			sp := parseSASPath(dest, name)
+			e.Parent = sp.host
+			if len(sp.expanders) > 0 {
+				e.Parent = sp.expanders[len(sp.expanders)-1]
+			}
+		}
+		expanders = append(expanders, e)
+	}
+	return expanders
+}
+
+// enclosures returns the enclosures found in /sys/class/enclosure, along with
+// the state of their device slots. The LEDs are only read, never changed.
+func enclosures(paths *linuxpath.Paths) []*Enclosure {
+	entries, err := os.ReadDir(paths.SysClassEnclosure)
+	if err != nil {
+		return nil
+	}
+	out := []*Enclosure{}
+	for _, entry := range entries {
+		path := filepath.Join(paths.SysClassEnclosure, entry.Name())
+		e := &Enclosure{
+			Name:   entry.Name(),
+			ID:     sysfsAttr(path, "id"),
+			Vendor: sysfsAttr(path, "device/vendor"),
+			Model:  sysfsAttr(path, "device/model"),
+			Slots:  []*EnclosureSlot{},
+		}
+		children, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			// the components of the enclosure are the subdirectories having
+			// a status attribute
+			cpath := filepath.Join(path, child.Name())
+			if _, err := os.Stat(filepath.Join(cpath, "status")); err != nil {
+				continue
+			}
+			slot := &EnclosureSlot{
+				Name:      child.Name(),
+				Slot:      -1,
+				Status:    sysfsAttr(cpath, "status"),
+				LocateLED: sysfsAttr(cpath, "locate") == "1",
+				FaultLED:  sysfsAttr(cpath, "fault") == "1",
+				Enclosure: e,
+			}
+			if n, err := strconv.Atoi(sysfsAttr(cpath, "slot")); err == nil {
+				slot.Slot = n
+			}
+			// the device link of an occupied slot points to the SCSI device
+			// of the disk in it
+			if dev, err := filepath.EvalSymlinks(filepath.Join(cpath, "device")); err == nil {
+				slot.SASAddress = sysfsAttr(dev, "sas_address")
+				if names := deviceLinks(filepath.Join(dev, "block")); len(names) > 0 {
+					slot.Disk = names[0]
+				}
+			}
+			e.Slots = append(e.Slots, slot)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// diskSCSI returns the SCSI and SAS topology information of a disk, or nil if
+// the disk is not a SCSI device. The directory of the block device of a SCSI
+// disk is the block/$DEVICE subdirectory of the SCSI device, e.g.
+// /sys/devices/.../target2:0:5/2:0:5:0/block/sdc
+func diskSCSI(paths *linuxpath.Paths, disk string) *SCSIDevice {
+	dest, err := filepath.EvalSymlinks(filepath.Join(paths.SysBlock, disk))
+	if err != nil || filepath.Base(filepath.Dir(dest)) != "block" {
+		return nil
+	}
+	devPath := filepath.Dir(filepath.Dir(dest))
+	addr, ok := parseSCSIAddress(filepath.Base(devPath))
+	if !ok {
+		return nil
+	}
+	sp := parseSASPath(devPath, "")
+	d := &SCSIDevice{
+		Address:   addr,
+		HostName:  sp.host,
+		Expanders: sp.expanders,
+		Slot:      -1,
+	}
+	if sp.endDevice != "" {
+		d.SASAddress = sysfsAttr(filepath.Join(paths.SysClassSASDevice, sp.endDevice), "sas_address")
+	}
+	if d.SASAddress == "" {
+		d.SASAddress = sysfsAttr(devPath, "sas_address")
+	}
+	return d
+}
+
+// fillSCSI sets the SCSI hosts, SAS expanders and enclosures of the host
+// system, and links the SCSI disks to their host and enclosure slot
+func (i *Info) fillSCSI(paths *linuxpath.Paths, pciDevice func(string) *pci.Device) {
+	i.SCSIHosts = scsiHosts(paths)
+	i.SASExpanders = sasExpanders(paths)
+	i.Enclosures = enclosures(paths)
+
+	hosts := map[string]*SCSIHost{}
+	for _, h := range i.SCSIHosts {
+		hosts[h.Name] = h
+		if h.PCIAddress != "" {
+			h.PCI = pciDevice(h.PCIAddress)
+		}
+	}
+	slots := map[string]*EnclosureSlot{}
+	for _, e := range i.Enclosures {
+		for _, s := range e.Slots {
+			if s.Disk != "" {
+				slots[s.Disk] = s
+			}
+		}
+	}
+	for _, d := range i.Disks {
+		d.SCSI = diskSCSI(paths, d.Name)
+		if d.SCSI == nil {
+			continue
+		}
+		d.SCSI.Host = hosts[d.SCSI.HostName]
+		if s, ok := slots[d.Name]; ok {
+			d.SCSI.EnclosureSlot = s
+			d.SCSI.Enclosure = s.Enclosure.Name
+			d.SCSI.Slot = s.Slot
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DeviceMapperKind describes the subsystem that created a device-mapper
+// device
+type DeviceMapperKind int
+
+const (
+	// DeviceMapperKindUnknown means we could not determine what created the
+	// device-mapper device
+	DeviceMapperKindUnknown DeviceMapperKind = iota
+	// DeviceMapperKindLVM indicates an LVM logical volume
+	DeviceMapperKindLVM
+	// DeviceMapperKindLUKS indicates a dm-crypt mapping of a LUKS encrypted
+	// device
+	DeviceMapperKindLUKS
+	// DeviceMapperKindCrypt indicates a dm-crypt mapping not using LUKS, e.g.
+	// a plain dm-crypt device
+	DeviceMapperKindCrypt
+	// DeviceMapperKindMultipath indicates a dm-multipath map aggregating the
+	// paths to a device
+	DeviceMapperKindMultipath
+	// DeviceMapperKindPartition indicates a partition of another
+	// device-mapper device, e.g. of a dm-multipath map
+	DeviceMapperKindPartition
+)
+
+var (
+	deviceMapperKindString = map[DeviceMapperKind]string{
+		DeviceMapperKindUnknown:   "Unknown",
+		DeviceMapperKindLVM:       "LVM",
+		DeviceMapperKindLUKS:      "LUKS",
+		DeviceMapperKindCrypt:     "crypt",
+		DeviceMapperKindMultipath: "multipath",
+		DeviceMapperKindPartition: "partition",
+	}
+
+	// used by DeviceMapperKind::UnmarshalJSON, hence the lowercase keys
+	stringDeviceMapperKind = map[string]DeviceMapperKind{
+		"unknown":   DeviceMapperKindUnknown,
+		"lvm":       DeviceMapperKindLVM,
+		"luks":      DeviceMapperKindLUKS,
+		"crypt":     DeviceMapperKindCrypt,
+		"multipath": DeviceMapperKindMultipath,
+		"partition": DeviceMapperKindPartition,
+	}
+)
+
+func (k DeviceMapperKind) String() string {
+	return deviceMapperKindString[k]
+}
+
+// MarshalJSON lowercases the kind, like for the other enums of this package
+func (k DeviceMapperKind) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(k.String()))), nil
+}
+
+func (k *DeviceMapperKind) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringDeviceMapperKind[key]
+	if !ok {
+		return fmt.Errorf("unknown device-mapper kind: %q", key)
+	}
+	*k = val
+	return nil
+}
+
+// DeviceMapper describes a device-mapper device, e.g. `dm-0`
+type DeviceMapper struct {
+	// Name is the name of the mapping, e.g. `vg0-root`. The device is
+	// available as /dev/mapper/$NAME.
+	Name string `json:"name"`
+	// UUID is the identifier the subsystem creating the mapping gave it, e.g.
+	// `LVM-` followed by the volume group and logical volume UUIDs.
+	UUID string `json:"uuid"`
+	// Kind is the subsystem that created the mapping, derived from the
+	// prefix of UUID
+	Kind DeviceMapperKind `json:"kind"`
+	// VolumeGroup is the name of the LVM volume group of LVM logical volumes
+	VolumeGroup string `json:"volume_group,omitempty"`
+	// LogicalVolume is the name of the LVM logical volume of LVM logical
+	// volumes
+	LogicalVolume string `json:"logical_volume,omitempty"`
+}
+
+// MDRaidMember describes a member device of an MD RAID array
+type MDRaidMember struct {
+	// Name is the name of the member disk or partition, e.g. `sda1`
+	Name string `json:"name"`
+	// Slot is the role of the member in the array, or -1 for spares and
+	// failed members
+	Slot int `json:"slot"`
+	// State is the state of the member, e.g. `in_sync`, `spare` or `faulty`
+	State string `json:"state"`
+}
+
+// MDRaid describes a Linux software RAID (MD) array, e.g. `md0`
+type MDRaid struct {
+	// Level is the RAID level of the array, e.g. `raid1`
+	Level string `json:"level"`
+	// State is the state of the array, e.g. `clean`, `active` or `inactive`
+	State string `json:"state"`
+	// UUID is the identifier of the array
+	UUID string `json:"uuid,omitempty"`
+	// RaidDisks is the number of devices the array is built of, not counting
+	// spares
+	RaidDisks int `json:"raid_disks"`
+	// DegradedDisks is the number of devices missing from the array
+	DegradedDisks int `json:"degraded_disks"`
+	// ChunkSizeBytes is the chunk size of striped arrays
+	ChunkSizeBytes uint64 `json:"chunk_size_bytes,omitempty"`
+	// SyncAction is the current resynchronization activity of the array,
+	// e.g. `idle`, `resync` or `recover`
+	SyncAction string `json:"sync_action,omitempty"`
+	// Members contains an array of pointers to `MDRaidMember` structs, one
+	// for each member device of the array
+	Members []*MDRaidMember `json:"members"`
+}
+
+// disk returns the disk with the supplied name, or nil if there is none
+func (i *Info) disk(name string) *Disk {
+	for _, d := range i.Disks {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// partition returns the partition with the supplied name, or nil if there is
+// none
+func (i *Info) partition(name string) *Partition {
+	for _, d := range i.Disks {
+		for _, p := range d.Partitions {
+			if p.Name == name {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// PhysicalDisks returns the disks at the bottom of the storage stack of the
+// supplied disk or partition name, e.g. `dm-1` or the partition a filesystem
+// is mounted from. The slaves of the device are followed down to the disks
+// having no slaves. A disk with no slaves is its own physical disk.
+func (i *Info) PhysicalDisks(name string) []*Disk {
+	out := []*Disk{}
+	seen := map[string]bool{}
+	var walk func(string)
+	walk = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		d := i.disk(name)
+		if d == nil {
+			if p := i.partition(name); p != nil && p.Disk != nil {
+				walk(p.Disk.Name)
+			}
+			return
+		}
+		if len(d.Slaves) == 0 {
+			out = append(out, d)
+			return
+		}
+		for _, slave := range d.Slaves {
+			walk(slave)
+		}
+	}
+	walk(name)
+	return out
+}
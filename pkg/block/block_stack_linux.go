@@ -0,0 +1,132 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// deviceLinks returns the names of the devices linked from the supplied
+// holders or slaves directory of a disk or partition, e.g.
+// /sys/block/sda/sda2/holders/dm-0
+func deviceLinks(path string) []string {
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// diskDeviceMapper returns the device-mapper information of a disk from the
+// /sys/block/$DEVICE/dm directory, or nil if the disk is not a device-mapper
+// device
+func diskDeviceMapper(paths *linuxpath.Paths, disk string) *DeviceMapper {
+	path := filepath.Join(paths.SysBlock, disk, "dm")
+	name := sysfsAttr(path, "name")
+	if name == "" {
+		return nil
+	}
+	dm := &DeviceMapper{
+		Name: name,
+		UUID: sysfsAttr(path, "uuid"),
+	}
+	dm.Kind = deviceMapperKind(dm.UUID)
+	if dm.Kind == DeviceMapperKindLVM {
+		dm.VolumeGroup, dm.LogicalVolume = lvmNames(name)
+	}
+	return dm
+}
+
+// deviceMapperKind returns the kind of a device-mapper device from the prefix
+// its creator put in its UUID, e.g. `LVM-`, `CRYPT-LUKS2-` or `mpath-`.
+// Partitions of device-mapper devices created by kpartx have UUIDs like
+// `part1-mpath-3600508b400105e210000900000490000`.
+func deviceMapperKind(uuid string) DeviceMapperKind {
+	switch {
+	case strings.HasPrefix(uuid, "LVM-"):
+		return DeviceMapperKindLVM
+	case strings.HasPrefix(uuid, "CRYPT-LUKS"):
+		return DeviceMapperKindLUKS
+	case strings.HasPrefix(uuid, "CRYPT-"):
+		return DeviceMapperKindCrypt
+	case strings.HasPrefix(uuid, "mpath-"):
+		return DeviceMapperKindMultipath
+	case strings.HasPrefix(uuid, "part"):
+		return DeviceMapperKindPartition
+	}
+	return DeviceMapperKindUnknown
+}
+
+// lvmNames splits the device-mapper name of an LVM logical volume into the
+// volume group and logical volume names. LVM separates both with a single
+// dash and doubles the dashes in the names themselves, so that `vg--data-root`
+// is the `root` logical volume of the `vg-data` volume group.
+func lvmNames(name string) (string, string) {
+	for i := 0; i < len(name); i++ {
+		if name[i] != '-' {
+			continue
+		}
+		if i+1 < len(name) && name[i+1] == '-' {
+			i++
+			continue
+		}
+		return strings.ReplaceAll(name[:i], "--", "-"), strings.ReplaceAll(name[i+1:], "--", "-")
+	}
+	return strings.ReplaceAll(name, "--", "-"), ""
+}
+
+// diskMDRaid returns the MD RAID array information of a disk from the
+// /sys/block/$DEVICE/md directory, or nil if the disk is not an MD RAID
+// array. Each member device has a dev-$MEMBER subdirectory in there.
+func diskMDRaid(paths *linuxpath.Paths, disk string) *MDRaid {
+	path := filepath.Join(paths.SysBlock, disk, "md")
+	level := sysfsAttr(path, "level")
+	if level == "" {
+		return nil
+	}
+	md := &MDRaid{
+		Level:      level,
+		State:      sysfsAttr(path, "array_state"),
+		UUID:       sysfsAttr(path, "uuid"),
+		SyncAction: sysfsAttr(path, "sync_action"),
+		Members:    []*MDRaidMember{},
+	}
+	md.RaidDisks, _ = strconv.Atoi(sysfsAttr(path, "raid_disks"))
+	md.DegradedDisks, _ = strconv.Atoi(sysfsAttr(path, "degraded"))
+	md.ChunkSizeBytes, _ = strconv.ParseUint(sysfsAttr(path, "chunk_size"), 10, 64)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return md
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), "dev-")
+		if !ok {
+			continue
+		}
+		memberPath := filepath.Join(path, entry.Name())
+		member := &MDRaidMember{
+			Name:  name,
+			Slot:  -1,
+			State: sysfsAttr(memberPath, "state"),
+		}
+		// the slot of spares and failed members is "none"
+		if slot, err := strconv.Atoi(sysfsAttr(memberPath, "slot")); err == nil {
+			member.Slot = slot
+		}
+		md.Members = append(md.Members, member)
+	}
+	return md
+}
@@ -0,0 +1,174 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// statsSectorSize is the size of the sectors the I/O statistics count,
+// whatever the logical block size of the device
+const statsSectorSize = 512
+
+// DeviceStats contains the I/O counters of a disk or partition, accumulated
+// since the device appeared, as found in /sys/block/$DEVICE/stat. The ticks
+// are in milliseconds.
+type DeviceStats struct {
+	// Name is the name of the disk or partition, e.g. `sda` or `sda1`
+	Name string `json:"name"`
+	// Disk is the name of the disk of a partition, and is empty for disks
+	Disk string `json:"disk,omitempty"`
+
+	ReadsCompleted  uint64 `json:"reads_completed"`
+	ReadsMerged     uint64 `json:"reads_merged"`
+	SectorsRead     uint64 `json:"sectors_read"`
+	ReadTicksMs     uint64 `json:"read_ticks_ms"`
+	WritesCompleted uint64 `json:"writes_completed"`
+	WritesMerged    uint64 `json:"writes_merged"`
+	SectorsWritten  uint64 `json:"sectors_written"`
+	WriteTicksMs    uint64 `json:"write_ticks_ms"`
+	// InFlight is the number of requests issued to the device but not
+	// completed yet. It is not a counter.
+	InFlight uint64 `json:"in_flight"`
+	// IOTicksMs is the time the device had requests in flight
+	IOTicksMs uint64 `json:"io_ticks_ms"`
+	// TimeInQueueMs is the time all the requests spent in flight, which
+	// grows faster than the wall clock when several requests are in flight
+	TimeInQueueMs uint64 `json:"time_in_queue_ms"`
+	// The discard counters are only reported since Linux 4.18
+	DiscardsCompleted uint64 `json:"discards_completed"`
+	DiscardsMerged    uint64 `json:"discards_merged"`
+	SectorsDiscarded  uint64 `json:"sectors_discarded"`
+	DiscardTicksMs    uint64 `json:"discard_ticks_ms"`
+	// The flush counters are only reported since Linux 5.5
+	FlushesCompleted uint64 `json:"flushes_completed"`
+	FlushTicksMs     uint64 `json:"flush_ticks_ms"`
+}
+
+// Stats contains a sample of the I/O counters of the block devices of the
+// host system. Unlike Info, it describes how the devices are used, not what
+// they are, and is meant to be sampled repeatedly and fed to Rates.
+type Stats struct {
+	// Timestamp is the time the sample was taken at
+	Timestamp time.Time `json:"timestamp"`
+	// Devices contains an array of pointers to `DeviceStats` structs, one for
+	// each disk and partition
+	Devices []*DeviceStats `json:"devices"`
+}
+
+// NewStats returns a pointer to a Stats struct containing the current I/O
+// counters of the block devices of the host system.
+func NewStats(args ...any) (*Stats, error) {
+	ctx := config.ContextFromArgs(args...)
+	stats := &Stats{}
+	if err := stats.load(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Device returns the counters of the disk or partition with the supplied
+// name, or nil if there is none
+func (s *Stats) Device(name string) *DeviceStats {
+	for _, d := range s.Devices {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// DeviceRates contains the I/O rates of a disk or partition over the interval
+// between two Stats samples, like the ones `iostat -x` reports
+type DeviceRates struct {
+	// Name is the name of the disk or partition, e.g. `sda` or `sda1`
+	Name string `json:"name"`
+	// Disk is the name of the disk of a partition, and is empty for disks
+	Disk string `json:"disk,omitempty"`
+
+	ReadIOPS              float64 `json:"read_iops"`
+	WriteIOPS             float64 `json:"write_iops"`
+	DiscardIOPS           float64 `json:"discard_iops"`
+	FlushIOPS             float64 `json:"flush_iops"`
+	ReadBytesPerSecond    float64 `json:"read_bytes_per_second"`
+	WriteBytesPerSecond   float64 `json:"write_bytes_per_second"`
+	DiscardBytesPerSecond float64 `json:"discard_bytes_per_second"`
+	// UtilizationPercent is the share of the interval the device had
+	// requests in flight. It saturates at 100 for devices serving requests
+	// in parallel, like SSDs and RAID arrays, long before they do.
+	UtilizationPercent float64 `json:"utilization_percent"`
+	// AverageQueueSize is the average number of requests in flight
+	AverageQueueSize float64 `json:"average_queue_size"`
+	// The await values are the average time the requests completed over the
+	// interval took to be served, including the time spent queued, and are
+	// zero when no such request completed
+	ReadAwaitMs    float64 `json:"read_await_ms"`
+	WriteAwaitMs   float64 `json:"write_await_ms"`
+	DiscardAwaitMs float64 `json:"discard_await_ms"`
+	// AwaitMs is the average of ReadAwaitMs and WriteAwaitMs weighted by
+	// the number of reads and writes
+	AwaitMs float64 `json:"await_ms"`
+}
+
+// await returns the average time of the supplied number of requests, or zero
+// if there was none
+func await(ticks uint64, ios uint64) float64 {
+	if ios == 0 {
+		return 0
+	}
+	return float64(ticks) / float64(ios)
+}
+
+// Rates returns the I/O rates of the block devices between two samples, for
+// the devices found in both samples, in the order of the current sample. A
+// counter which went backwards in between counts as zero, see
+// util.CounterDelta.
+func Rates(prev *Stats, cur *Stats) ([]*DeviceRates, error) {
+	if prev == nil || cur == nil {
+		return nil, errors.New("two samples are needed to compute rates")
+	}
+	interval := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	if interval <= 0 {
+		return nil, errors.New("the current sample must be taken after the previous one")
+	}
+	intervalMs := interval * 1000
+	rates := make([]*DeviceRates, 0, len(cur.Devices))
+	for _, c := range cur.Devices {
+		p := prev.Device(c.Name)
+		if p == nil {
+			continue
+		}
+		reads := util.CounterDelta(p.ReadsCompleted, c.ReadsCompleted)
+		writes := util.CounterDelta(p.WritesCompleted, c.WritesCompleted)
+		discards := util.CounterDelta(p.DiscardsCompleted, c.DiscardsCompleted)
+		readTicks := util.CounterDelta(p.ReadTicksMs, c.ReadTicksMs)
+		writeTicks := util.CounterDelta(p.WriteTicksMs, c.WriteTicksMs)
+		r := &DeviceRates{
+			Name:                  c.Name,
+			Disk:                  c.Disk,
+			ReadIOPS:              float64(reads) / interval,
+			WriteIOPS:             float64(writes) / interval,
+			DiscardIOPS:           float64(discards) / interval,
+			FlushIOPS:             float64(util.CounterDelta(p.FlushesCompleted, c.FlushesCompleted)) / interval,
+			ReadBytesPerSecond:    float64(util.CounterDelta(p.SectorsRead, c.SectorsRead)*statsSectorSize) / interval,
+			WriteBytesPerSecond:   float64(util.CounterDelta(p.SectorsWritten, c.SectorsWritten)*statsSectorSize) / interval,
+			DiscardBytesPerSecond: float64(util.CounterDelta(p.SectorsDiscarded, c.SectorsDiscarded)*statsSectorSize) / interval,
+			UtilizationPercent:    min(100, float64(util.CounterDelta(p.IOTicksMs, c.IOTicksMs))*100/intervalMs),
+			AverageQueueSize:      float64(util.CounterDelta(p.TimeInQueueMs, c.TimeInQueueMs)) / intervalMs,
+			ReadAwaitMs:           await(readTicks, reads),
+			WriteAwaitMs:          await(writeTicks, writes),
+			DiscardAwaitMs:        await(util.CounterDelta(p.DiscardTicksMs, c.DiscardTicksMs), discards),
+			AwaitMs:               await(readTicks+writeTicks, reads+writes),
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
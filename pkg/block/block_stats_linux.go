@@ -0,0 +1,116 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+func (s *Stats) load(ctx context.Context) error {
+	paths := linuxpath.New(ctx)
+	entries, err := os.ReadDir(paths.SysBlock)
+	if err != nil {
+		return err
+	}
+	s.Timestamp = time.Now()
+	s.Devices = []*DeviceStats{}
+	for _, entry := range entries {
+		dname := entry.Name()
+		if isNVMePathDevice(dname) {
+			continue
+		}
+		path := filepath.Join(paths.SysBlock, dname)
+		ds, err := deviceStats(path)
+		if err != nil {
+			log.Debug(ctx, "failed to read I/O statistics of %s: %s", dname, err)
+			continue
+		}
+		ds.Name = dname
+		s.Devices = append(s.Devices, ds)
+
+		// The partitions of the disk are the subdirectories having a
+		// partition file, holding the partition number
+		children, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			cpath := filepath.Join(path, child.Name())
+			if _, err := os.Stat(filepath.Join(cpath, "partition")); err != nil {
+				continue
+			}
+			ps, err := deviceStats(cpath)
+			if err != nil {
+				log.Debug(ctx, "failed to read I/O statistics of %s: %s", child.Name(), err)
+				continue
+			}
+			ps.Name = child.Name()
+			ps.Disk = dname
+			s.Devices = append(s.Devices, ps)
+		}
+	}
+	return nil
+}
+
+// deviceStats reads the stat file of the disk or partition at the supplied
+// sysfs path
+func deviceStats(path string) (*DeviceStats, error) {
+	contents, err := os.ReadFile(filepath.Join(path, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	return parseDeviceStats(string(contents))
+}
+
+// parseDeviceStats parses the fields of a block device stat file, described
+// in Documentation/block/stat.rst of the kernel tree. Older kernels report
+// fewer fields, the missing ones are left to zero.
+func parseDeviceStats(contents string) (*DeviceStats, error) {
+	fields := strings.Fields(contents)
+	if len(fields) < 11 {
+		return nil, fmt.Errorf("expected at least 11 fields, but got %d", len(fields))
+	}
+	ds := &DeviceStats{}
+	counters := []*uint64{
+		&ds.ReadsCompleted,
+		&ds.ReadsMerged,
+		&ds.SectorsRead,
+		&ds.ReadTicksMs,
+		&ds.WritesCompleted,
+		&ds.WritesMerged,
+		&ds.SectorsWritten,
+		&ds.WriteTicksMs,
+		&ds.InFlight,
+		&ds.IOTicksMs,
+		&ds.TimeInQueueMs,
+		&ds.DiscardsCompleted,
+		&ds.DiscardsMerged,
+		&ds.SectorsDiscarded,
+		&ds.DiscardTicksMs,
+		&ds.FlushesCompleted,
+		&ds.FlushTicksMs,
+	}
+	for i, field := range fields {
+		if i >= len(counters) {
+			break
+		}
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %d %q: %w", i+1, field, err)
+		}
+		*counters[i] = v
+	}
+	return ds, nil
+}
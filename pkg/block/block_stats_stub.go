@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+func (s *Stats) load(_ context.Context) error {
+	return errors.New("block stats not implemented on " + runtime.GOOS)
+}
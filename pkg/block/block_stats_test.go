@@ -0,0 +1,101 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRates(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prev := &Stats{
+		Timestamp: start,
+		Devices: []*DeviceStats{
+			{
+				Name:            "sda",
+				ReadsCompleted:  1000,
+				SectorsRead:     80000,
+				ReadTicksMs:     2000,
+				WritesCompleted: 500,
+				SectorsWritten:  40000,
+				WriteTicksMs:    5000,
+				IOTicksMs:       10000,
+				TimeInQueueMs:   7000,
+			},
+			{
+				Name:           "sdb",
+				ReadsCompleted: 1 << 40,
+			},
+		},
+	}
+	cur := &Stats{
+		Timestamp: start.Add(2 * time.Second),
+		Devices: []*DeviceStats{
+			{
+				Name:              "sda",
+				ReadsCompleted:    1200,
+				SectorsRead:       80000 + 2048,
+				ReadTicksMs:       2400,
+				WritesCompleted:   600,
+				SectorsWritten:    40000 + 4096,
+				WriteTicksMs:      6600,
+				IOTicksMs:         11000,
+				TimeInQueueMs:     10000,
+				DiscardsCompleted: 4,
+				DiscardTicksMs:    10,
+				FlushesCompleted:  20,
+			},
+			{
+				// the counter was reset, e.g. the disk was replaced
+				Name:           "sdb",
+				ReadsCompleted: 10,
+			},
+			{
+				// appeared after the previous sample
+				Name:           "sdc",
+				ReadsCompleted: 10,
+			},
+		},
+	}
+
+	expected := []*DeviceRates{
+		{
+			Name:                "sda",
+			ReadIOPS:            100,
+			WriteIOPS:           50,
+			DiscardIOPS:         2,
+			FlushIOPS:           10,
+			ReadBytesPerSecond:  2048 * 512 / 2,
+			WriteBytesPerSecond: 4096 * 512 / 2,
+			UtilizationPercent:  50,
+			AverageQueueSize:    1.5,
+			ReadAwaitMs:         2,
+			WriteAwaitMs:        16,
+			DiscardAwaitMs:      2.5,
+			AwaitMs:             2000.0 / 300,
+		},
+		{
+			Name: "sdb",
+		},
+	}
+	rates, err := Rates(prev, cur)
+	if err != nil {
+		t.Fatalf("Expected no error computing rates, but got %v", err)
+	}
+	if !reflect.DeepEqual(rates, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected, rates)
+	}
+
+	if _, err = Rates(cur, prev); err == nil {
+		t.Fatalf("Expected an error computing rates of samples out of order, but got nil")
+	}
+	if _, err = Rates(nil, cur); err == nil {
+		t.Fatalf("Expected an error computing rates with a single sample, but got nil")
+	}
+}
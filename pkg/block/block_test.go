@@ -146,6 +146,27 @@ func TestBlockUnmarshal(t *testing.T) {
 	}
 }
 
+func TestNVMeTransportMarshalJSON(t *testing.T) {
+	tr := block.NVMeTransportLoop
+	if tr.String() != "Loop" {
+		t.Fatalf("Expected Loop, but got %s", tr)
+	}
+	data, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatalf("Expected no error marshaling NVMeTransport, but got %v", err)
+	}
+	if string(data) != `"loop"` {
+		t.Fatalf("Expected \"loop\", but got %s", data)
+	}
+	var got block.NVMeTransport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Expected no error unmarshaling NVMeTransport, but got %v", err)
+	}
+	if got != tr {
+		t.Fatalf("Expected %s, but got %s", tr, got)
+	}
+}
+
 func findDiskByName(disks []*block.Disk, name string) *block.Disk {
 	for _, disk := range disks {
 		if disk.Name == name {
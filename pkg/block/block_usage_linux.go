@@ -0,0 +1,71 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"syscall"
+
+	"github.com/jaypipes/ghw/internal/config"
+)
+
+// filesystemUsage returns the capacity usage of the filesystem housed on the
+// device whose sysfs directory is supplied, or nil if the filesystem is not
+// mounted or its usage cannot be determined.
+func filesystemUsage(
+	ctx context.Context,
+	sysDevicePath string,
+	mounts []*Mount,
+) *FilesystemUsage {
+	if len(mounts) == 0 {
+		return nil
+	}
+	// All the mounts of a device share the same filesystem, so the first one
+	// is as good as any
+	chroot := config.Chroot(ctx)
+	path := filepath.Join(chroot, mounts[0].Path)
+	if chroot != "/" {
+		// The mount table is the one of the inspected system. When that
+		// system is a snapshot or a copied tree, the mount path is a plain
+		// directory of another filesystem, so only trust it when it lives on
+		// the device itself, e.g. when the host root filesystem is bind
+		// mounted into a container.
+		var st syscall.Stat_t
+		if err := syscall.Stat(path, &st); err != nil {
+			return nil
+		}
+		if devNumber(uint64(st.Dev)) != sysfsAttr(sysDevicePath, "dev") {
+			return nil
+		}
+	}
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return nil
+	}
+	blockSize := uint64(fs.Frsize)
+	if blockSize == 0 {
+		blockSize = uint64(fs.Bsize)
+	}
+	return &FilesystemUsage{
+		TotalBytes:     fs.Blocks * blockSize,
+		UsedBytes:      (fs.Blocks - fs.Bfree) * blockSize,
+		AvailableBytes: fs.Bavail * blockSize,
+		TotalInodes:    fs.Files,
+		UsedInodes:     fs.Files - fs.Ffree,
+		FreeInodes:     fs.Ffree,
+	}
+}
+
+// devNumber returns the "major:minor" representation of a device number,
+// like found in /sys/block/$DEVICE/dev
+func devNumber(dev uint64) string {
+	major := ((dev >> 8) & 0xfff) | ((dev >> 32) &^ 0xfff)
+	minor := (dev & 0xff) | ((dev >> 12) &^ 0xff)
+	return fmt.Sprintf("%d:%d", major, minor)
+}
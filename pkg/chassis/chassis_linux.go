@@ -7,13 +7,20 @@ package chassis
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/jaypipes/ghw/pkg/linuxdmi"
 	"github.com/jaypipes/ghw/pkg/linuxdt"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
 func (i *Info) load(ctx context.Context) error {
+	if table, err := smbios.Load(ctx); err == nil {
+		if chassis := table.Chassis(); len(chassis) > 0 {
+			return i.loadSMBIOS(chassis[0])
+		}
+	}
 	if !linuxdmi.Available(ctx) && linuxdt.Available(ctx) {
 		return i.loadDeviceTree(ctx)
 	}
@@ -32,6 +39,23 @@ func (i *Info) load(ctx context.Context) error {
 	return nil
 }
 
+// loadSMBIOS populates chassis information from the first decoded SMBIOS
+// System Enclosure or Chassis structure.
+func (i *Info) loadSMBIOS(chassis *smbios.Chassis) error {
+	i.AssetTag = util.StringOrUnknown(chassis.AssetTag)
+	i.SerialNumber = util.StringOrUnknown(chassis.SerialNumber)
+	i.Type = strconv.Itoa(int(chassis.Type))
+	typeDesc, found := chassisTypeDescriptions[i.Type]
+	if !found {
+		typeDesc = util.UNKNOWN
+	}
+	i.TypeDescription = typeDesc
+	i.Vendor = util.StringOrUnknown(chassis.Manufacturer)
+	i.Version = util.StringOrUnknown(chassis.Version)
+
+	return nil
+}
+
 // loadDeviceTree populates chassis information from the DeviceTree on systems
 // without DMI/SMBIOS. The DeviceTree has no asset tag concept, and only some
 // boards expose a chassis-type, so several fields remain unknown.
@@ -6,36 +6,21 @@
 package chassis_test
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/internal/testutil"
 	"github.com/jaypipes/ghw/pkg/chassis"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
-// writeFiles writes name->content files (names may be nested subpaths) rooted at
-// dir, creating parent directories as needed.
-func writeFiles(t *testing.T, dir string, files map[string]string) {
-	t.Helper()
-	for name, content := range files {
-		path := filepath.Join(dir, name)
-		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-			t.Fatal(err)
-		}
-	}
-}
-
 // deviceTreeChroot builds a chroot whose DeviceTree base holds the given
 // properties and which has no DMI, so the DeviceTree fallback is exercised.
 func deviceTreeChroot(t *testing.T, props map[string]string) string {
 	t.Helper()
 	root := t.TempDir()
-	writeFiles(t, filepath.Join(root, "sys", "firmware", "devicetree", "base"), props)
+	testutil.WriteFiles(t, filepath.Join(root, "sys", "firmware", "devicetree", "base"), props)
 	return root
 }
 
@@ -100,13 +85,13 @@ func TestChassisDeviceTreeChassisType(t *testing.T) {
 func TestChassisDMITakesPrecedence(t *testing.T) {
 	root := t.TempDir()
 	// Both DMI and DeviceTree present: DMI must win.
-	writeFiles(t, filepath.Join(root, "sys", "class", "dmi", "id"), map[string]string{
+	testutil.WriteFiles(t, filepath.Join(root, "sys", "class", "dmi", "id"), map[string]string{
 		"chassis_vendor":  "Acme Corp\n",
 		"chassis_type":    "3\n",
 		"chassis_serial":  "DMI-SERIAL\n",
 		"chassis_version": "1.0\n",
 	})
-	writeFiles(t, filepath.Join(root, "sys", "firmware", "devicetree", "base"), map[string]string{
+	testutil.WriteFiles(t, filepath.Join(root, "sys", "firmware", "devicetree", "base"), map[string]string{
 		"compatible":    "raspberrypi,4-model-b\x00",
 		"serial-number": "DT-SERIAL\x00",
 	})
@@ -7,12 +7,193 @@
 package cpu
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/jaypipes/ghw/internal/config"
 	"github.com/jaypipes/ghw/pkg/marshal"
+	"github.com/jaypipes/ghw/pkg/util"
 )
 
+// Frequency describes the clock frequencies and the frequency scaling policy
+// of a logical processor or, when aggregated, of all the logical processors
+// of a core or processor package. Frequencies are in hertz and are 0 when
+// unknown.
+type Frequency struct {
+	// MinHz is the lowest frequency the hardware supports. For aggregates, it
+	// is the lowest MinHz of the logical processors.
+	MinHz uint64 `json:"min_hz"`
+	// MaxHz is the highest frequency the hardware supports, including any
+	// boost/turbo frequency. For aggregates, it is the highest MaxHz of the
+	// logical processors.
+	MaxHz uint64 `json:"max_hz"`
+	// BaseHz is the base (nominal, non-boosted) frequency. For aggregates, it
+	// is the highest BaseHz of the logical processors.
+	BaseHz uint64 `json:"base_hz"`
+	// CurrentHz is the frequency the logical processor was running at when
+	// it was inspected. For aggregates, it is the average CurrentHz of the
+	// logical processors.
+	CurrentHz uint64 `json:"current_hz"`
+	// ScalingMinHz is the lowest frequency the scaling governor may select
+	ScalingMinHz uint64 `json:"scaling_min_hz"`
+	// ScalingMaxHz is the highest frequency the scaling governor may select
+	ScalingMaxHz uint64 `json:"scaling_max_hz"`
+	// Driver is the name of the cpufreq scaling driver, e.g. "intel_pstate"
+	// or "acpi-cpufreq". For aggregates whose logical processors use
+	// different values, this and the other string fields contain the
+	// distinct values, sorted and separated by commas.
+	Driver string `json:"driver"`
+	// Governor is the name of the cpufreq scaling governor, e.g.
+	// "performance" or "schedutil"
+	Governor string `json:"governor"`
+	// EnergyPerformancePreference is the hint given to hardware-managed
+	// P-states about whether to favour performance or power savings, e.g.
+	// "balance_performance"
+	EnergyPerformancePreference string `json:"energy_performance_preference"`
+	// BoostSupported is true if the processor has a boost/turbo mode that
+	// the operating system can control
+	BoostSupported bool `json:"boost_supported"`
+	// BoostEnabled is true if the boost/turbo mode is currently enabled
+	BoostEnabled bool `json:"boost_enabled"`
+}
+
+// String returns a short string describing the Frequency
+func (f *Frequency) String() string {
+	return fmt.Sprintf(
+		"frequency (min %s, max %s, current %s, driver %s, governor %s)",
+		hzString(f.MinHz),
+		hzString(f.MaxHz),
+		hzString(f.CurrentHz),
+		util.StringOrUnknown(f.Driver),
+		util.StringOrUnknown(f.Governor),
+	)
+}
+
+// aggregateFrequencies combines the frequencies of several logical
+// processors into a single Frequency, or returns nil if freqs is empty.
+func aggregateFrequencies(freqs []*Frequency) *Frequency {
+	if len(freqs) == 0 {
+		return nil
+	}
+	agg := &Frequency{}
+	var curTotal, curCount uint64
+	drivers := []string{}
+	governors := []string{}
+	epps := []string{}
+	for _, f := range freqs {
+		if f.MinHz != 0 && (agg.MinHz == 0 || f.MinHz < agg.MinHz) {
+			agg.MinHz = f.MinHz
+		}
+		if f.ScalingMinHz != 0 && (agg.ScalingMinHz == 0 || f.ScalingMinHz < agg.ScalingMinHz) {
+			agg.ScalingMinHz = f.ScalingMinHz
+		}
+		agg.MaxHz = max(agg.MaxHz, f.MaxHz)
+		agg.ScalingMaxHz = max(agg.ScalingMaxHz, f.ScalingMaxHz)
+		agg.BaseHz = max(agg.BaseHz, f.BaseHz)
+		if f.CurrentHz != 0 {
+			curTotal += f.CurrentHz
+			curCount++
+		}
+		drivers = append(drivers, f.Driver)
+		governors = append(governors, f.Governor)
+		epps = append(epps, f.EnergyPerformancePreference)
+		agg.BoostSupported = agg.BoostSupported || f.BoostSupported
+		agg.BoostEnabled = agg.BoostEnabled || f.BoostEnabled
+	}
+	if curCount > 0 {
+		agg.CurrentHz = curTotal / curCount
+	}
+	agg.Driver = distinctStrings(drivers)
+	agg.Governor = distinctStrings(governors)
+	agg.EnergyPerformancePreference = distinctStrings(epps)
+	return agg
+}
+
+// distinctStrings returns the distinct non-empty values of items, sorted and
+// joined with commas
+func distinctStrings(items []string) string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, item := range items {
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func hzString(hz uint64) string {
+	if hz == 0 {
+		return util.UNKNOWN
+	}
+	if hz >= 1_000_000_000 {
+		return fmt.Sprintf("%.2fGHz", float64(hz)/1e9)
+	}
+	return fmt.Sprintf("%dMHz", hz/1_000_000)
+}
+
+// CoreType indicates the kind of core on processors combining cores with
+// different performance and power characteristics, such as Intel hybrid
+// processors (P-cores and E-cores) and ARM big.LITTLE/DynamIQ designs.
+type CoreType int
+
+const (
+	// CoreTypeUnknown indicates the core type could not be determined, which
+	// is always the case on processors whose cores are all identical.
+	CoreTypeUnknown CoreType = iota
+	// CoreTypePerformance indicates a core optimized for performance, e.g.
+	// an Intel P-core or an ARM "big" core.
+	CoreTypePerformance
+	// CoreTypeEfficiency indicates a core optimized for power efficiency,
+	// e.g. an Intel E-core or an ARM "LITTLE" core.
+	CoreTypeEfficiency
+)
+
+var (
+	coreTypeString = map[CoreType]string{
+		CoreTypeUnknown:     "Unknown",
+		CoreTypePerformance: "Performance",
+		CoreTypeEfficiency:  "Efficiency",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `coreTypeString`, matching CoreType:MarshalJSON.
+	stringCoreType = map[string]CoreType{
+		"unknown":     CoreTypeUnknown,
+		"performance": CoreTypePerformance,
+		"efficiency":  CoreTypeEfficiency,
+	}
+)
+
+func (t CoreType) String() string {
+	return coreTypeString[t]
+}
+
+// MarshalJSON serializes the CoreType as a lowercased string
+func (t CoreType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(t.String()))), nil
+}
+
+func (t *CoreType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringCoreType[key]
+	if !ok {
+		return fmt.Errorf("unknown core type: %q", key)
+	}
+	*t = val
+	return nil
+}
+
 // ProcessorCore describes a physical host processor core. A processor core is
 // a separate processing unit within some types of central processing units
 // (CPU).
@@ -33,6 +214,24 @@ type ProcessorCore struct {
 	// called the "thread siblings". Logical processor IDs are the *zero-based*
 	// index of the processor on the host and are *not* related to the core ID.
 	LogicalProcessors []int `json:"logical_processors"`
+	// Type is the kind of core on hybrid or heterogeneous processors, or
+	// CoreTypeUnknown when all cores of the host are identical
+	Type CoreType `json:"type"`
+	// Capacity is the relative compute capacity of the core as reported by
+	// the kernel on heterogeneous ARM systems, normalized so the most capable
+	// core of the host has a capacity of 1024. It is 0 when unknown.
+	Capacity int `json:"capacity"`
+	// ClusterID is the identifier of the cluster the core belongs to. It is
+	// only meaningful when the Processor has Clusters.
+	ClusterID int `json:"cluster_id"`
+	// DieID is the identifier of the die the core belongs to
+	DieID int `json:"die_id"`
+	// Frequency is the aggregated frequency information of the core's
+	// logical processors, or nil if unknown
+	Frequency *Frequency `json:"frequency,omitempty"`
+	// LogicalProcessorFrequencies contains the frequency information of
+	// each of the core's logical processors, keyed by logical processor ID
+	LogicalProcessorFrequencies map[int]*Frequency `json:"logical_processor_frequencies,omitempty"`
 }
 
 // String returns a short string indicating important information about the
@@ -46,6 +245,37 @@ func (c *ProcessorCore) String() string {
 	)
 }
 
+// ProcessorCluster describes a group of cores within a processor package
+// that share resources such as an L2 cache, a clock or a power domain. On ARM
+// big.LITTLE designs a cluster usually contains cores of a single type and on
+// Intel hybrid processors the E-cores are grouped in modules of four cores.
+type ProcessorCluster struct {
+	// ID is the cluster identifier the kernel gave this cluster. It is only
+	// unique within a die.
+	ID int `json:"id"`
+	// DieID is the identifier of the die the cluster is on
+	DieID int `json:"die_id"`
+	// CoreType is the type of the cluster's cores, or CoreTypeUnknown if the
+	// cluster mixes core types or the core type is unknown
+	CoreType CoreType `json:"core_type"`
+	// CoreIDs contains the IDs of the cores in the cluster
+	CoreIDs []int `json:"core_ids"`
+	// LogicalProcessors contains the IDs of the logical processors in the
+	// cluster
+	LogicalProcessors []int `json:"logical_processors"`
+}
+
+// String returns a short string describing the ProcessorCluster
+func (c *ProcessorCluster) String() string {
+	return fmt.Sprintf(
+		"processor cluster #%d (%d cores, %s), logical processors %v",
+		c.ID,
+		len(c.CoreIDs),
+		strings.ToLower(c.CoreType.String()),
+		c.LogicalProcessors,
+	)
+}
+
 // Processor describes a physical host central processing unit (CPU).
 type Processor struct {
 	// ID is the physical processor `uint32` ID according to the system
@@ -65,12 +295,38 @@ type Processor struct {
 	Vendor string `json:"vendor"`
 	// Model` is a string containing the vendor's model name
 	Model string `json:"model"`
+	// Family is the processor family number. On x86 this is the CPUID family
+	// and on ARM the architecture version.
+	Family int `json:"family"`
+	// ModelNumber is the processor model number. On x86 this is the CPUID
+	// model and on ARM the "CPU part" number.
+	ModelNumber int `json:"model_number"`
+	// Stepping is the processor stepping. On x86 this is the CPUID stepping
+	// and on ARM the "CPU revision".
+	Stepping int `json:"stepping"`
+	// Microcode is the revision of the microcode loaded on the processor,
+	// e.g. "0x2b000571", or "" if unknown
+	Microcode string `json:"microcode"`
+	// Microarchitecture is the name of the processor's microarchitecture or
+	// core design, e.g. "Sapphire Rapids", "Zen 4" or "Neoverse-N1", or "" if
+	// unknown
+	Microarchitecture string `json:"microarchitecture"`
 	// Capabilities is a slice of strings indicating the features the processor
 	// has enabled
 	Capabilities []string `json:"capabilities"`
 	// Cores is a slice of ProcessorCore` struct pointers that are packed onto
 	// this physical processor
 	Cores []*ProcessorCore `json:"cores"`
+	// Clusters is a slice of ProcessorCluster struct pointers describing how
+	// the cores are grouped. It is empty if the kernel does not report
+	// clusters or if every core is in its own cluster.
+	Clusters []*ProcessorCluster `json:"clusters,omitempty"`
+	// TotalDies is the number of dies in the processor package, or 0 if
+	// unknown
+	TotalDies uint32 `json:"total_dies"`
+	// Frequency is the aggregated frequency information of all the logical
+	// processors in the package, or nil if unknown
+	Frequency *Frequency `json:"frequency,omitempty"`
 }
 
 // CoreByID returns the ProcessorCore having the supplied ID.
@@ -134,6 +390,62 @@ type Info struct {
 	// Processors is a slice of Processor struct pointers, one for each
 	// physical processor package contained in the host
 	Processors []*Processor `json:"processors"`
+	// Vulnerabilities is a map, keyed by vulnerability name (e.g.
+	// "spectre_v2"), of the status the kernel reports for each hardware
+	// vulnerability it knows of, e.g. "Not affected", "Vulnerable" or
+	// "Mitigation: Enhanced IBRS"
+	Vulnerabilities map[string]string `json:"vulnerabilities,omitempty"`
+	// Online contains the IDs of the logical processors that are online and
+	// schedulable
+	Online []int `json:"online"`
+	// Offline contains the IDs of the logical processors that are offline,
+	// either because they were hot-unplugged or because they are possible but
+	// not present
+	Offline []int `json:"offline"`
+	// Possible contains the IDs of the logical processors the kernel has
+	// allocated resources for, including those that may be hot-plugged later
+	Possible []int `json:"possible"`
+	// Present contains the IDs of the logical processors that are physically
+	// present in the system
+	Present []int `json:"present"`
+	// Isolated contains the IDs of the logical processors isolated from the
+	// general scheduler with the isolcpus kernel parameter
+	Isolated []int `json:"isolated"`
+	// NohzFull contains the IDs of the logical processors running in
+	// adaptive-tick (nohz_full) mode
+	NohzFull []int `json:"nohz_full"`
+	// Cpuset describes the logical processors and memory nodes available to
+	// the calling process or to the cgroup selected with WithCgroup, or is nil
+	// if it cannot be determined
+	Cpuset *Cpuset `json:"cpuset,omitempty"`
+}
+
+// Cpuset describes the effective cpuset of a cgroup: the logical processors
+// and memory nodes the tasks in the cgroup may actually use.
+type Cpuset struct {
+	// Cgroup is the path of the cgroup relative to the root of the cgroup
+	// hierarchy, e.g. "/system.slice/docker-1234.scope"
+	Cgroup string `json:"cgroup"`
+	// CgroupVersion is the version (1 or 2) of the cgroup hierarchy the
+	// cpuset was read from
+	CgroupVersion int `json:"cgroup_version"`
+	// CPUs contains the IDs of the logical processors in the cpuset
+	CPUs []int `json:"cpus"`
+	// Mems contains the IDs of the memory (NUMA) nodes in the cpuset
+	Mems []int `json:"mems"`
+}
+
+// Vulnerable returns the sorted names of the vulnerabilities the host is
+// vulnerable to, that is those with a status starting with "Vulnerable".
+func (i *Info) Vulnerable() []string {
+	out := []string{}
+	for name, status := range i.Vulnerabilities {
+		if strings.HasPrefix(status, "Vulnerable") {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
 }
 
 // New returns a pointer to an Info struct that contains information about the
@@ -0,0 +1,168 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// hybridCoreTypes returns the type of each logical processor, keyed by
+// logical processor ID, on Intel hybrid processors. On those processors the
+// kernel registers a separate PMU for each core type, and
+// /sys/devices/cpu_core/cpus and /sys/devices/cpu_atom/cpus list the logical
+// processors of the P-cores and E-cores respectively. Returns nil on other
+// processors.
+func hybridCoreTypes(paths *linuxpath.Paths) map[int]CoreType {
+	var types map[int]CoreType
+	for pmu, coreType := range map[string]CoreType{
+		"cpu_core": CoreTypePerformance,
+		"cpu_atom": CoreTypeEfficiency,
+	} {
+		data, err := os.ReadFile(filepath.Join(paths.SysRoot, "devices", pmu, "cpus"))
+		if err != nil {
+			continue
+		}
+		lps, err := util.ParseCPUList(string(data))
+		if err != nil {
+			continue
+		}
+		if types == nil {
+			types = map[int]CoreType{}
+		}
+		for _, lpID := range lps {
+			types[lpID] = coreType
+		}
+	}
+	return types
+}
+
+// setCoreTypesFromCapacity classifies the cores of heterogeneous ARM systems
+// by their capacity: the cores with the lowest capacity are efficiency cores
+// and all others are performance cores. Nothing is changed if all cores have
+// the same (or an unknown) capacity.
+func setCoreTypesFromCapacity(procs []*Processor) {
+	minCap, maxCap := 0, 0
+	for _, p := range procs {
+		for _, c := range p.Cores {
+			if c.Capacity == 0 {
+				continue
+			}
+			if minCap == 0 || c.Capacity < minCap {
+				minCap = c.Capacity
+			}
+			maxCap = max(maxCap, c.Capacity)
+		}
+	}
+	if minCap == maxCap {
+		return
+	}
+	for _, p := range procs {
+		for _, c := range p.Cores {
+			switch {
+			case c.Capacity == 0:
+			case c.Capacity == minCap:
+				c.Type = CoreTypeEfficiency
+			default:
+				c.Type = CoreTypePerformance
+			}
+		}
+	}
+}
+
+// coreInCluster returns the processor's core having the supplied core,
+// cluster and die IDs, or nil if there is none
+func coreInCluster(proc *Processor, coreID, clusterID, dieID int) *ProcessorCore {
+	for _, c := range proc.Cores {
+		if c.ID == coreID && c.ClusterID == clusterID && c.DieID == dieID {
+			return c
+		}
+	}
+	return nil
+}
+
+// clusterKey identifies a cluster within a processor package
+type clusterKey struct {
+	dieID     int
+	clusterID int
+}
+
+// setProcessorClusters groups the processor's cores into clusters using the
+// cluster and die IDs read from the cores' topology. hasClusters indicates
+// whether the kernel reported cluster IDs at all. Clusters are only reported
+// when at least one cluster contains more than one core, since otherwise
+// they add nothing to the core level.
+func setProcessorClusters(proc *Processor, hasClusters bool, hasDies bool) {
+	if hasDies {
+		dies := map[int]bool{}
+		for _, c := range proc.Cores {
+			dies[c.DieID] = true
+		}
+		proc.TotalDies = uint32(len(dies))
+	}
+	if !hasClusters {
+		return
+	}
+	clusters := map[clusterKey]*ProcessorCluster{}
+	for _, c := range proc.Cores {
+		key := clusterKey{dieID: c.DieID, clusterID: c.ClusterID}
+		cl, ok := clusters[key]
+		if !ok {
+			cl = &ProcessorCluster{
+				ID:       c.ClusterID,
+				DieID:    c.DieID,
+				CoreType: c.Type,
+			}
+			clusters[key] = cl
+		}
+		if cl.CoreType != c.Type {
+			cl.CoreType = CoreTypeUnknown
+		}
+		cl.CoreIDs = append(cl.CoreIDs, c.ID)
+		cl.LogicalProcessors = append(cl.LogicalProcessors, c.LogicalProcessors...)
+	}
+	if len(clusters) == len(proc.Cores) {
+		return
+	}
+	proc.Clusters = make([]*ProcessorCluster, 0, len(clusters))
+	for _, cl := range clusters {
+		sort.Ints(cl.CoreIDs)
+		sort.Ints(cl.LogicalProcessors)
+		proc.Clusters = append(proc.Clusters, cl)
+	}
+	sort.Slice(proc.Clusters, func(i, j int) bool {
+		a, b := proc.Clusters[i], proc.Clusters[j]
+		if a.DieID != b.DieID {
+			return a.DieID < b.DieID
+		}
+		return a.ID < b.ID
+	})
+}
+
+// logicalProcessorInt reads an integer from a file below the
+// /sys/devices/system/cpu/cpuN directory of a logical processor, e.g.
+// "topology/cluster_id" or "cpu_capacity". Unlike util.SafeIntFromFile, a
+// missing file is not worth a warning since these files only exist on some
+// architectures and kernel versions.
+func logicalProcessorInt(paths *linuxpath.Paths, lpID int, name string) (int, bool) {
+	path := filepath.Join(paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID), name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
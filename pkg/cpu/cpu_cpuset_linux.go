@@ -0,0 +1,201 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// setLogicalProcessorSets reads the logical processor sets the kernel
+// exposes in /sys/devices/system/cpu.
+func (i *Info) setLogicalProcessorSets(ctx context.Context) {
+	paths := linuxpath.New(ctx)
+	read := func(name string) []int {
+		path := filepath.Join(paths.SysDevicesSystemCPU, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return []int{}
+		}
+		lps, err := util.ParseCPUList(string(data))
+		if err != nil {
+			// nohz_full contains "(null)" on some kernels when the
+			// parameter is not set
+			log.Debug(ctx, "failed to parse %s: %s", path, err)
+			return []int{}
+		}
+		return lps
+	}
+	i.Online = read("online")
+	i.Offline = read("offline")
+	i.Possible = read("possible")
+	i.Present = read("present")
+	i.Isolated = read("isolated")
+	i.NohzFull = read("nohz_full")
+}
+
+// cgroupMounts contains the mount points of the cgroup hierarchies
+// containing the cpuset controller
+type cgroupMounts struct {
+	v1Cpuset string
+	v2       string
+}
+
+// findCgroupMounts returns the mount points, relative to the chroot, of the
+// cgroup v1 cpuset hierarchy and of the cgroup v2 unified hierarchy, read
+// from /proc/self/mounts. Lines look like:
+//
+//	cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+//	cgroup /sys/fs/cgroup/cpuset cgroup rw,nosuid,nodev,noexec,relatime,cpuset 0 0
+func findCgroupMounts(paths *linuxpath.Paths) cgroupMounts {
+	mounts := cgroupMounts{}
+	f, err := os.Open(paths.ProcMounts)
+	if err != nil {
+		return mounts
+	}
+	defer util.SafeClose(f)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		switch fields[2] {
+		case "cgroup2":
+			if mounts.v2 == "" {
+				mounts.v2 = fields[1]
+			}
+		case "cgroup":
+			for _, opt := range strings.Split(fields[3], ",") {
+				if opt == "cpuset" && mounts.v1Cpuset == "" {
+					mounts.v1Cpuset = fields[1]
+				}
+			}
+		}
+	}
+	return mounts
+}
+
+// processCgroups returns the cgroup v1 cpuset path and the cgroup v2 path of
+// the calling process, read from /proc/self/cgroup. Lines look like:
+//
+//	0::/user.slice/user-1000.slice/session-2.scope
+//	4:cpuset,cpu:/docker/8ac1f5e0
+func processCgroups(paths *linuxpath.Paths) (v1Cpuset string, v2 string, found bool) {
+	f, err := os.Open(paths.ProcSelfCgroup)
+	if err != nil {
+		return "", "", false
+	}
+	defer util.SafeClose(f)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2 = parts[2]
+			found = true
+			continue
+		}
+		for _, ctrl := range strings.Split(parts[1], ",") {
+			if ctrl == "cpuset" {
+				v1Cpuset = parts[2]
+				found = true
+			}
+		}
+	}
+	return v1Cpuset, v2, found
+}
+
+// cpusetGet returns the effective cpuset of the cgroup selected with
+// config.WithCgroup or, by default, of the calling process. The cgroup v1
+// cpuset hierarchy is preferred when it is mounted, since on hybrid setups
+// the cpuset controller cannot be enabled in the v2 hierarchy at the same
+// time.
+func cpusetGet(ctx context.Context) *Cpuset {
+	paths := linuxpath.New(ctx)
+	mounts := findCgroupMounts(paths)
+	if mounts.v1Cpuset == "" && mounts.v2 == "" {
+		// Snapshots and chroots without /proc/self/mounts: assume the
+		// unified hierarchy at its usual location
+		mounts.v2 = "/sys/fs/cgroup"
+	}
+
+	v1Path, v2Path := config.Cgroup(ctx), config.Cgroup(ctx)
+	if v1Path == "" {
+		var found bool
+		v1Path, v2Path, found = processCgroups(paths)
+		if !found {
+			v1Path, v2Path = "/", "/"
+		}
+	}
+
+	if mounts.v1Cpuset != "" && v1Path != "" {
+		dir := cgroupDir(ctx, paths, mounts.v1Cpuset, v1Path)
+		if cs := readCpuset(dir, "cpuset.effective_cpus", "cpuset.effective_mems"); cs != nil {
+			cs.Cgroup = v1Path
+			cs.CgroupVersion = 1
+			return cs
+		}
+	}
+	if mounts.v2 != "" && v2Path != "" {
+		// The cpuset controller may not be enabled for the cgroup, in which
+		// case its effective cpuset is the one of the closest ancestor having
+		// the controller enabled
+		root := cgroupDir(ctx, paths, mounts.v2, "/")
+		dir := cgroupDir(ctx, paths, mounts.v2, v2Path)
+		for {
+			if cs := readCpuset(dir, "cpuset.cpus.effective", "cpuset.mems.effective"); cs != nil {
+				cs.Cgroup = v2Path
+				cs.CgroupVersion = 2
+				return cs
+			}
+			if dir == root || !strings.HasPrefix(dir, root) {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	return nil
+}
+
+// cgroupDir returns the directory of the supplied cgroup below a cgroup
+// hierarchy mount point, honouring the chroot and any /sys path override.
+func cgroupDir(ctx context.Context, paths *linuxpath.Paths, mount string, cgroup string) string {
+	if rel, ok := strings.CutPrefix(mount, "/sys"); ok && (rel == "" || rel[0] == '/') {
+		return filepath.Join(paths.SysRoot, rel, cgroup)
+	}
+	return filepath.Join(config.Chroot(ctx), mount, cgroup)
+}
+
+// readCpuset reads the cpus and mems files of a cgroup directory, returning
+// nil if the cpus file does not exist or cannot be parsed.
+func readCpuset(dir string, cpusFile string, memsFile string) *Cpuset {
+	data, err := os.ReadFile(filepath.Join(dir, cpusFile))
+	if err != nil {
+		return nil
+	}
+	cpus, err := util.ParseCPUList(string(data))
+	if err != nil {
+		return nil
+	}
+	mems := []int{}
+	if data, err := os.ReadFile(filepath.Join(dir, memsFile)); err == nil {
+		if ids, err := util.ParseCPUList(string(data)); err == nil {
+			mems = ids
+		}
+	}
+	return &Cpuset{CPUs: cpus, Mems: mems}
+}
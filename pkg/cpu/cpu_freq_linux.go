@@ -0,0 +1,148 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// boostState describes the host-wide boost/turbo setting
+type boostState struct {
+	supported bool
+	enabled   bool
+}
+
+// hostBoostState returns the host-wide boost/turbo setting. The acpi-cpufreq
+// driver exposes it in /sys/devices/system/cpu/cpufreq/boost while the
+// intel_pstate driver exposes the inverse in
+// /sys/devices/system/cpu/intel_pstate/no_turbo.
+func hostBoostState(paths *linuxpath.Paths) boostState {
+	if v, err := readSysfsUint(filepath.Join(paths.SysDevicesSystemCPU, "cpufreq", "boost")); err == nil {
+		return boostState{supported: true, enabled: v == 1}
+	}
+	if v, err := readSysfsUint(filepath.Join(paths.SysDevicesSystemCPU, "intel_pstate", "no_turbo")); err == nil {
+		return boostState{supported: true, enabled: v == 0}
+	}
+	return boostState{}
+}
+
+// logicalProcessorFrequency returns the frequency information of a logical
+// processor, or nil if nothing is known about it. Information is read from
+// the /sys/devices/system/cpu/cpuN/cpufreq directory (a link to the cpufreq
+// policy the logical processor belongs to), which contains:
+//
+//	cpuinfo_min_freq, cpuinfo_max_freq: hardware limits, in kHz
+//	scaling_min_freq, scaling_max_freq: governor limits, in kHz
+//	scaling_cur_freq: current frequency, in kHz
+//	base_frequency: base frequency, in kHz (intel_pstate only)
+//	scaling_driver, scaling_governor
+//	energy_performance_preference (intel_pstate, amd-pstate-epp)
+//	boost: per-policy boost setting (amd-pstate and recent kernels)
+//
+// When there is no cpufreq driver, which is common for virtual machines, the
+// current frequency is taken from the "cpu MHz" attribute of the logical
+// processor in /proc/cpuinfo.
+func logicalProcessorFrequency(
+	paths *linuxpath.Paths,
+	lpID int,
+	lp *logicalProcessor,
+	boost boostState,
+) *Frequency {
+	cpuPath := filepath.Join(paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID))
+	freqPath := filepath.Join(cpuPath, "cpufreq")
+	f := &Frequency{
+		BoostSupported: boost.supported,
+		BoostEnabled:   boost.enabled,
+	}
+	found := false
+	readKHz := func(name string, dest *uint64) {
+		if v, err := readSysfsUint(filepath.Join(freqPath, name)); err == nil {
+			*dest = v * 1000
+			found = true
+		}
+	}
+	readString := func(name string, dest *string) {
+		if data, err := os.ReadFile(filepath.Join(freqPath, name)); err == nil {
+			*dest = strings.TrimSpace(string(data))
+			found = true
+		}
+	}
+	readKHz("cpuinfo_min_freq", &f.MinHz)
+	readKHz("cpuinfo_max_freq", &f.MaxHz)
+	readKHz("scaling_min_freq", &f.ScalingMinHz)
+	readKHz("scaling_max_freq", &f.ScalingMaxHz)
+	readKHz("base_frequency", &f.BaseHz)
+	readKHz("scaling_cur_freq", &f.CurrentHz)
+	if f.CurrentHz == 0 {
+		// cpuinfo_cur_freq is the frequency reported by the hardware and is
+		// only readable by root
+		readKHz("cpuinfo_cur_freq", &f.CurrentHz)
+	}
+	readString("scaling_driver", &f.Driver)
+	readString("scaling_governor", &f.Governor)
+	readString("energy_performance_preference", &f.EnergyPerformancePreference)
+	if v, err := readSysfsUint(filepath.Join(freqPath, "boost")); err == nil {
+		f.BoostSupported = true
+		f.BoostEnabled = v == 1
+	}
+
+	if f.BaseHz == 0 {
+		// ACPI CPPC platforms (many ARM64 servers, AMD) report the nominal
+		// frequency in MHz
+		if v, err := readSysfsUint(filepath.Join(cpuPath, "acpi_cppc", "nominal_freq")); err == nil && v > 0 {
+			f.BaseHz = v * 1_000_000
+			found = true
+		}
+	}
+
+	if lp != nil {
+		if f.CurrentHz == 0 {
+			// s390x reports "cpu MHz dynamic" and "cpu MHz static" instead
+			for _, key := range []string{"cpu MHz", "cpu MHz dynamic"} {
+				if hz := mhzToHz(lp.Attrs[key]); hz > 0 {
+					f.CurrentHz = hz
+					found = true
+					break
+				}
+			}
+		}
+		if f.BaseHz == 0 {
+			if hz := mhzToHz(lp.Attrs["cpu MHz static"]); hz > 0 {
+				f.BaseHz = hz
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return f
+}
+
+// mhzToHz converts a possibly fractional MHz string like "2200.000" to Hz,
+// returning 0 if the string cannot be parsed
+func mhzToHz(mhz string) uint64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(mhz), 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return uint64(math.Round(v * 1_000_000))
+}
+
+func readSysfsUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
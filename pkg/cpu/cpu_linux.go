@@ -30,6 +30,9 @@ var (
 
 func (i *Info) load(ctx context.Context) error {
 	i.Processors = processorsGet(ctx)
+	i.Vulnerabilities = vulnerabilitiesGet(ctx)
+	i.setLogicalProcessorSets(ctx)
+	i.Cpuset = cpusetGet(ctx)
 	var totCores uint32
 	var totThreads uint32
 	for _, p := range i.Processors {
@@ -47,6 +50,9 @@ func processorsGet(ctx context.Context) []*Processor {
 	paths := linuxpath.New(ctx)
 
 	lps := logicalProcessorsFromProcCPUInfo(ctx)
+	boost := hostBoostState(paths)
+	hybridTypes := hybridCoreTypes(paths)
+	hasClusters, hasDies := false, false
 	// keyed by processor ID (physical_package_id)
 	procs := map[int]*Processor{}
 
@@ -132,21 +138,39 @@ func processorsGet(ctx context.Context) []*Processor {
 				proc.Vendor = lp.Attrs["vendor_id"]
 			} else if len(lp.Attrs["isa"]) != 0 { // RISCV64
 				proc.Vendor = lp.Attrs["isa"]
-			} else if lp.Attrs["CPU implementer"] == "0x41" { // ARM
-				proc.Vendor = "ARM"
+			} else if impl, ok := armImplementers[cpuinfoInt(lp.Attrs["CPU implementer"])]; ok { // ARM
+				proc.Vendor = impl
 			}
+			// 4. Identification
+			setProcessorIdentification(proc, lp)
 			procs[procID] = proc
 		}
 
 		coreID := coreIDFromLogicalProcessorID(ctx, lpID)
-		core := proc.CoreByID(coreID)
+		// The kernel reports a cluster ID of -1 when the firmware does not
+		// describe clusters
+		clusterID, dieID := 0, 0
+		if id, ok := logicalProcessorInt(paths, lpID, "topology/cluster_id"); ok && id >= 0 {
+			clusterID = id
+			hasClusters = true
+		}
+		if id, ok := logicalProcessorInt(paths, lpID, "topology/die_id"); ok && id >= 0 {
+			dieID = id
+			hasDies = true
+		}
+		// On ARM, core IDs are only unique within a cluster
+		core := coreInCluster(proc, coreID, clusterID, dieID)
 		if core == nil {
 			core = &ProcessorCore{
 				ID:                   coreID,
 				TotalHardwareThreads: 1,
 				// TODO(jaypipes): Remove NumThreads before v1.0
 				NumThreads: 1,
+				Type:       hybridTypes[lpID],
+				ClusterID:  clusterID,
+				DieID:      dieID,
 			}
+			core.Capacity, _ = logicalProcessorInt(paths, lpID, "cpu_capacity")
 			proc.Cores = append(proc.Cores, core)
 			proc.TotalCores += 1
 			// TODO(jaypipes): Remove NumCores before v1.0
@@ -160,17 +184,97 @@ func processorsGet(ctx context.Context) []*Processor {
 		// TODO(jaypipes) Remove NumThreads before v1.0
 		proc.NumThreads += 1
 		core.LogicalProcessors = append(core.LogicalProcessors, lpID)
+		if freq := logicalProcessorFrequency(paths, lpID, lps[lpID], boost); freq != nil {
+			if core.LogicalProcessorFrequencies == nil {
+				core.LogicalProcessorFrequencies = map[int]*Frequency{}
+			}
+			core.LogicalProcessorFrequencies[lpID] = freq
+		}
 	}
 	res := []*Processor{}
 	for _, p := range procs {
+		res = append(res, p)
+	}
+	if hybridTypes == nil {
+		setCoreTypesFromCapacity(res)
+	}
+	for _, p := range res {
+		procFreqs := []*Frequency{}
 		for _, c := range p.Cores {
 			sort.Ints(c.LogicalProcessors)
+			coreFreqs := []*Frequency{}
+			for _, lpID := range c.LogicalProcessors {
+				if freq, ok := c.LogicalProcessorFrequencies[lpID]; ok {
+					coreFreqs = append(coreFreqs, freq)
+				}
+			}
+			c.Frequency = aggregateFrequencies(coreFreqs)
+			procFreqs = append(procFreqs, coreFreqs...)
 		}
-		res = append(res, p)
+		p.Frequency = aggregateFrequencies(procFreqs)
+		setProcessorClusters(p, hasClusters, hasDies)
 	}
 	return res
 }
 
+// setProcessorIdentification sets the family, model number, stepping,
+// microcode and microarchitecture of the Processor from the /proc/cpuinfo
+// attributes of one of its logical processors.
+func setProcessorIdentification(proc *Processor, lp *logicalProcessor) {
+	if _, ok := lp.Attrs["cpu family"]; ok { // x86
+		proc.Family = cpuinfoInt(lp.Attrs["cpu family"])
+		proc.ModelNumber = cpuinfoInt(lp.Attrs["model"])
+		proc.Stepping = cpuinfoInt(lp.Attrs["stepping"])
+		proc.Microcode = lp.Attrs["microcode"]
+		proc.Microarchitecture = x86Microarchitecture(
+			lp.Attrs["vendor_id"], proc.Family, proc.ModelNumber, proc.Stepping,
+		)
+		return
+	}
+	if _, ok := lp.Attrs["CPU implementer"]; ok { // ARM
+		proc.Family = cpuinfoInt(lp.Attrs["CPU architecture"])
+		proc.ModelNumber = cpuinfoInt(lp.Attrs["CPU part"])
+		proc.Stepping = cpuinfoInt(lp.Attrs["CPU revision"])
+		proc.Microarchitecture = armMicroarchitecture(
+			cpuinfoInt(lp.Attrs["CPU implementer"]), proc.ModelNumber,
+		)
+	}
+}
+
+// cpuinfoInt parses a decimal or "0x"-prefixed hexadecimal /proc/cpuinfo
+// value, returning 0 if it cannot be parsed
+func cpuinfoInt(s string) int {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 0, 64)
+	if err != nil {
+		return 0
+	}
+	return int(v)
+}
+
+// vulnerabilitiesGet returns the status of the hardware vulnerabilities the
+// kernel knows of, read from the files in
+// /sys/devices/system/cpu/vulnerabilities. Each file is named after a
+// vulnerability and contains a status line like "Not affected" or
+// "Mitigation: Clear CPU buffers; SMT vulnerable".
+func vulnerabilitiesGet(ctx context.Context) map[string]string {
+	paths := linuxpath.New(ctx)
+	dir := filepath.Join(paths.SysDevicesSystemCPU, "vulnerabilities")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Debug(ctx, "failed to read %s: %s", dir, err)
+		return nil
+	}
+	vulns := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		vulns[entry.Name()] = strings.TrimSpace(string(data))
+	}
+	return vulns
+}
+
 // processorIDFromLogicalProcessorID returns the processor physical package ID
 // for the supplied logical processor ID
 func processorIDFromLogicalProcessorID(ctx context.Context, lpID int) int {
@@ -8,9 +8,11 @@ package cpu_test
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -311,3 +313,416 @@ Qualcomm Technologies, Inc QCM6125
 		t.Errorf("Expected 1 processor, got %d", len(info.Processors))
 	}
 }
+
+func TestCPUFrequency(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte(
+		"processor\t: 0\nvendor_id\t: GenuineIntel\ncpu MHz\t\t: 1200.000\n\n"+
+			"processor\t: 1\nvendor_id\t: GenuineIntel\ncpu MHz\t\t: 1200.000\n\n"+
+			"processor\t: 2\nvendor_id\t: GenuineIntel\ncpu MHz\t\t: 2400.500\n\n"))
+
+	sysCPU := filepath.Join(root, "sys", "devices", "system", "cpu")
+	// cpu0 and cpu1 are thread siblings on core 0, cpu2 is on core 1
+	for lpID, coreID := range []string{"0", "0", "1"} {
+		topo := filepath.Join(sysCPU, fmt.Sprintf("cpu%d", lpID), "topology")
+		writeCPUFile(t, filepath.Join(topo, "physical_package_id"), []byte("0\n"))
+		writeCPUFile(t, filepath.Join(topo, "core_id"), []byte(coreID+"\n"))
+	}
+	// cpu0 and cpu1 are managed by intel_pstate through their policies; cpu2
+	// has no cpufreq directory and falls back to /proc/cpuinfo
+	for lpID, cur := range []string{"800000", "3600000"} {
+		policy := filepath.Join(sysCPU, "cpufreq", fmt.Sprintf("policy%d", lpID))
+		for name, content := range map[string]string{
+			"cpuinfo_min_freq":              "400000",
+			"cpuinfo_max_freq":              "4700000",
+			"scaling_min_freq":              "800000",
+			"scaling_max_freq":              "4700000",
+			"scaling_cur_freq":              cur,
+			"base_frequency":                "2100000",
+			"scaling_driver":                "intel_pstate",
+			"scaling_governor":              []string{"powersave", "performance"}[lpID],
+			"energy_performance_preference": "balance_performance",
+		} {
+			writeCPUFile(t, filepath.Join(policy, name), []byte(content+"\n"))
+		}
+		link := filepath.Join(sysCPU, fmt.Sprintf("cpu%d", lpID), "cpufreq")
+		if err := os.Symlink(filepath.Join("..", "cpufreq", fmt.Sprintf("policy%d", lpID)), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCPUFile(t, filepath.Join(sysCPU, "intel_pstate", "no_turbo"), []byte("0\n"))
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.Processors) != 1 {
+		t.Fatalf("Expected 1 processor but got %d", len(info.Processors))
+	}
+	proc := info.Processors[0]
+
+	core := proc.CoreByID(0)
+	if core == nil || len(core.LogicalProcessorFrequencies) != 2 {
+		t.Fatalf("Expected core 0 with 2 logical processor frequencies, but got %+v", core)
+	}
+	lp0 := core.LogicalProcessorFrequencies[0]
+	if lp0.MinHz != 400_000_000 || lp0.MaxHz != 4_700_000_000 || lp0.BaseHz != 2_100_000_000 {
+		t.Errorf("unexpected cpu0 hardware limits: %+v", lp0)
+	}
+	if lp0.CurrentHz != 800_000_000 || lp0.ScalingMinHz != 800_000_000 {
+		t.Errorf("unexpected cpu0 scaling frequencies: %+v", lp0)
+	}
+	if lp0.Driver != "intel_pstate" || lp0.EnergyPerformancePreference != "balance_performance" {
+		t.Errorf("unexpected cpu0 scaling policy: %+v", lp0)
+	}
+	if !lp0.BoostSupported || !lp0.BoostEnabled {
+		t.Errorf("Expected boost to be supported and enabled on cpu0")
+	}
+	if got, want := core.Frequency.CurrentHz, uint64(2_200_000_000); got != want {
+		t.Errorf("Expected core 0 average frequency %d, but got %d", want, got)
+	}
+	if got, want := core.Frequency.Governor, "performance,powersave"; got != want {
+		t.Errorf("Expected core 0 governors %q, but got %q", want, got)
+	}
+
+	lp2 := proc.CoreByID(1).LogicalProcessorFrequencies[2]
+	if lp2 == nil || lp2.CurrentHz != 2_400_500_000 || lp2.Driver != "" {
+		t.Errorf("Expected cpu2 frequency from /proc/cpuinfo, but got %+v", lp2)
+	}
+
+	if proc.Frequency == nil {
+		t.Fatal("Expected package frequency, but got nil")
+	}
+	if proc.Frequency.MinHz != 400_000_000 || proc.Frequency.MaxHz != 4_700_000_000 {
+		t.Errorf("unexpected package frequency: %+v", proc.Frequency)
+	}
+}
+
+func TestCPUIdentification(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	tests := []struct {
+		name    string
+		cpuinfo string
+		vendor  string
+		family  int
+		model   int
+		step    int
+		ucode   string
+		uarch   string
+	}{
+		{
+			name: "sapphire rapids",
+			cpuinfo: "processor\t: 0\nvendor_id\t: GenuineIntel\ncpu family\t: 6\nmodel\t\t: 143\n" +
+				"model name\t: Intel(R) Xeon(R) Platinum 8480+\nstepping\t: 8\nmicrocode\t: 0x2b000571\n\n",
+			vendor: "GenuineIntel", family: 6, model: 0x8F, step: 8, ucode: "0x2b000571", uarch: "Sapphire Rapids",
+		},
+		{
+			name: "cascade lake",
+			cpuinfo: "processor\t: 0\nvendor_id\t: GenuineIntel\ncpu family\t: 6\nmodel\t\t: 85\n" +
+				"stepping\t: 7\nmicrocode\t: 0x5003604\n\n",
+			vendor: "GenuineIntel", family: 6, model: 0x55, step: 7, ucode: "0x5003604", uarch: "Cascade Lake",
+		},
+		{
+			name: "zen 4",
+			cpuinfo: "processor\t: 0\nvendor_id\t: AuthenticAMD\ncpu family\t: 25\nmodel\t\t: 17\n" +
+				"model name\t: AMD EPYC 9654 96-Core Processor\nstepping\t: 1\nmicrocode\t: 0xa10113e\n\n",
+			vendor: "AuthenticAMD", family: 0x19, model: 0x11, step: 1, ucode: "0xa10113e", uarch: "Zen 4",
+		},
+		{
+			name: "neoverse n1",
+			cpuinfo: "processor\t: 0\nFeatures\t: fp asimd\nCPU implementer\t: 0x41\nCPU architecture: 8\n" +
+				"CPU variant\t: 0x3\nCPU part\t: 0xd0c\nCPU revision\t: 1\n\n",
+			vendor: "ARM", family: 8, model: 0xD0C, step: 1, uarch: "Neoverse-N1",
+		},
+		{
+			name: "ampere one",
+			cpuinfo: "processor\t: 0\nFeatures\t: fp asimd\nCPU implementer\t: 0xc0\nCPU architecture: 8\n" +
+				"CPU variant\t: 0x0\nCPU part\t: 0xac3\nCPU revision\t: 0\n\n",
+			vendor: "Ampere", family: 8, model: 0xAC3, uarch: "Ampere-1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte(test.cpuinfo))
+			topo := filepath.Join(root, "sys", "devices", "system", "cpu", "cpu0", "topology")
+			writeCPUFile(t, filepath.Join(topo, "physical_package_id"), []byte("0\n"))
+			writeCPUFile(t, filepath.Join(topo, "core_id"), []byte("0\n"))
+
+			info, err := cpu.New(ghw.WithChroot(root))
+			if err != nil {
+				t.Fatalf("Expected nil err, but got %v", err)
+			}
+			if len(info.Processors) != 1 {
+				t.Fatalf("Expected 1 processor but got %d", len(info.Processors))
+			}
+			p := info.Processors[0]
+			if p.Vendor != test.vendor || p.Family != test.family || p.ModelNumber != test.model || p.Stepping != test.step {
+				t.Errorf("Expected %s family %d model %#x stepping %d, but got %s family %d model %#x stepping %d",
+					test.vendor, test.family, test.model, test.step, p.Vendor, p.Family, p.ModelNumber, p.Stepping)
+			}
+			if p.Microcode != test.ucode {
+				t.Errorf("Expected microcode %q, but got %q", test.ucode, p.Microcode)
+			}
+			if p.Microarchitecture != test.uarch {
+				t.Errorf("Expected microarchitecture %q, but got %q", test.uarch, p.Microarchitecture)
+			}
+		})
+	}
+}
+
+func TestCPUVulnerabilities(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte("processor\t: 0\n\n"))
+	sysCPU := filepath.Join(root, "sys", "devices", "system", "cpu")
+	writeCPUFile(t, filepath.Join(sysCPU, "cpu0", "topology", "core_id"), []byte("0\n"))
+	vulns := map[string]string{
+		"meltdown":   "Not affected",
+		"spectre_v2": "Mitigation: Enhanced / Automatic IBRS; IBPB: conditional; RSB filling",
+		"mds":        "Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable",
+		"retbleed":   "Vulnerable",
+	}
+	for name, status := range vulns {
+		writeCPUFile(t, filepath.Join(sysCPU, "vulnerabilities", name), []byte(status+"\n"))
+	}
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if !reflect.DeepEqual(info.Vulnerabilities, vulns) {
+		t.Errorf("Expected vulnerabilities %v, but got %v", vulns, info.Vulnerabilities)
+	}
+	if got, want := info.Vulnerable(), []string{"mds", "retbleed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected vulnerable to %v, but got %v", want, got)
+	}
+}
+
+// writeCPUTopology writes the topology files of the supplied logical
+// processors. Each entry of lps is {core_id, cluster_id, die_id}.
+func writeCPUTopology(t *testing.T, root string, lps [][3]int) {
+	t.Helper()
+	for lpID, ids := range lps {
+		topo := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "topology")
+		writeCPUFile(t, filepath.Join(topo, "physical_package_id"), []byte("0\n"))
+		writeCPUFile(t, filepath.Join(topo, "core_id"), []byte(fmt.Sprintf("%d\n", ids[0])))
+		writeCPUFile(t, filepath.Join(topo, "cluster_id"), []byte(fmt.Sprintf("%d\n", ids[1])))
+		writeCPUFile(t, filepath.Join(topo, "die_id"), []byte(fmt.Sprintf("%d\n", ids[2])))
+	}
+}
+
+func TestCPUHybridCoreTypes(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	cpuinfo := ""
+	for lpID := 0; lpID < 8; lpID++ {
+		cpuinfo += fmt.Sprintf("processor\t: %d\nvendor_id\t: GenuineIntel\n\n", lpID)
+	}
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo))
+	// Two SMT P-cores, each in its own cluster, and four E-cores sharing a
+	// cluster, as on an Alder Lake processor
+	writeCPUTopology(t, root, [][3]int{
+		{0, 0, 0}, {0, 0, 0}, {4, 4, 0}, {4, 4, 0},
+		{8, 8, 0}, {9, 8, 0}, {10, 8, 0}, {11, 8, 0},
+	})
+	writeCPUFile(t, filepath.Join(root, "sys", "devices", "cpu_core", "cpus"), []byte("0-3\n"))
+	writeCPUFile(t, filepath.Join(root, "sys", "devices", "cpu_atom", "cpus"), []byte("4-7\n"))
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.Processors) != 1 {
+		t.Fatalf("Expected 1 processor but got %d", len(info.Processors))
+	}
+	proc := info.Processors[0]
+	for coreID, want := range map[int]cpu.CoreType{
+		0: cpu.CoreTypePerformance, 4: cpu.CoreTypePerformance,
+		8: cpu.CoreTypeEfficiency, 11: cpu.CoreTypeEfficiency,
+	} {
+		if got := proc.CoreByID(coreID).Type; got != want {
+			t.Errorf("Expected core %d to be %s, but got %s", coreID, want, got)
+		}
+	}
+	if proc.TotalDies != 1 {
+		t.Errorf("Expected 1 die, but got %d", proc.TotalDies)
+	}
+	if len(proc.Clusters) != 3 {
+		t.Fatalf("Expected 3 clusters, but got %d", len(proc.Clusters))
+	}
+	ecores := proc.Clusters[2]
+	if ecores.ID != 8 || ecores.CoreType != cpu.CoreTypeEfficiency {
+		t.Errorf("Expected efficiency cluster #8, but got %s", ecores)
+	}
+	if !reflect.DeepEqual(ecores.CoreIDs, []int{8, 9, 10, 11}) || !reflect.DeepEqual(ecores.LogicalProcessors, []int{4, 5, 6, 7}) {
+		t.Errorf("unexpected efficiency cluster: %+v", ecores)
+	}
+}
+
+func TestCPUCapacityCoreTypes(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	cpuinfo := ""
+	for lpID := 0; lpID < 6; lpID++ {
+		cpuinfo += fmt.Sprintf("processor\t: %d\nCPU implementer\t: 0x41\n\n", lpID)
+	}
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo))
+	// A RK3399-like big.LITTLE layout: four Cortex-A53 in cluster 0 and two
+	// Cortex-A72 in cluster 1
+	writeCPUTopology(t, root, [][3]int{
+		{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}, {0, 1, 0}, {1, 1, 0},
+	})
+	for lpID, capacity := range []string{"485", "485", "485", "485", "1024", "1024"} {
+		writeCPUFile(t, filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "cpu_capacity"),
+			[]byte(capacity+"\n"))
+	}
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	if len(proc.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, but got %d", len(proc.Clusters))
+	}
+	little, big := proc.Clusters[0], proc.Clusters[1]
+	if little.CoreType != cpu.CoreTypeEfficiency || !reflect.DeepEqual(little.LogicalProcessors, []int{0, 1, 2, 3}) {
+		t.Errorf("unexpected LITTLE cluster: %s", little)
+	}
+	if big.CoreType != cpu.CoreTypePerformance || !reflect.DeepEqual(big.LogicalProcessors, []int{4, 5}) {
+		t.Errorf("unexpected big cluster: %s", big)
+	}
+}
+
+func TestCPULogicalProcessorSets(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte("processor\t: 0\n\n"))
+	sysCPU := filepath.Join(root, "sys", "devices", "system", "cpu")
+	writeCPUTopology(t, root, [][3]int{{0, 0, 0}})
+	for name, content := range map[string]string{
+		"online":    "0-5,7",
+		"offline":   "6,8-15",
+		"possible":  "0-15",
+		"present":   "0-7",
+		"isolated":  "4-5",
+		"nohz_full": "(null)",
+	} {
+		writeCPUFile(t, filepath.Join(sysCPU, name), []byte(content+"\n"))
+	}
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	for name, test := range map[string]struct{ got, want []int }{
+		"online":    {info.Online, []int{0, 1, 2, 3, 4, 5, 7}},
+		"offline":   {info.Offline, []int{6, 8, 9, 10, 11, 12, 13, 14, 15}},
+		"present":   {info.Present, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		"isolated":  {info.Isolated, []int{4, 5}},
+		"nohz_full": {info.NohzFull, []int{}},
+	} {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("Expected %s %v, but got %v", name, test.want, test.got)
+		}
+	}
+	if len(info.Possible) != 16 {
+		t.Errorf("Expected 16 possible logical processors, but got %v", info.Possible)
+	}
+}
+
+func TestCPUCpusetCgroupV2(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte("processor\t: 0\n\n"))
+	writeCPUTopology(t, root, [][3]int{{0, 0, 0}})
+	writeCPUFile(t, filepath.Join(root, "proc", "self", "mounts"), []byte(
+		"sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0\n"+
+			"cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0\n"))
+	writeCPUFile(t, filepath.Join(root, "proc", "self", "cgroup"), []byte(
+		"0::/kubepods.slice/pod1234/container5678\n"))
+	cgroup := filepath.Join(root, "sys", "fs", "cgroup")
+	writeCPUFile(t, filepath.Join(cgroup, "cpuset.cpus.effective"), []byte("0-15\n"))
+	writeCPUFile(t, filepath.Join(cgroup, "cpuset.mems.effective"), []byte("0-1\n"))
+	// The cpuset controller is enabled for the pod but not for the container
+	writeCPUFile(t, filepath.Join(cgroup, "kubepods.slice", "pod1234", "cpuset.cpus.effective"), []byte("2-3,10-11\n"))
+	writeCPUFile(t, filepath.Join(cgroup, "kubepods.slice", "pod1234", "cpuset.mems.effective"), []byte("0\n"))
+	writeCPUFile(t, filepath.Join(cgroup, "kubepods.slice", "pod1234", "container5678", "cgroup.procs"), []byte("1\n"))
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	want := &cpu.Cpuset{
+		Cgroup:        "/kubepods.slice/pod1234/container5678",
+		CgroupVersion: 2,
+		CPUs:          []int{2, 3, 10, 11},
+		Mems:          []int{0},
+	}
+	if !reflect.DeepEqual(info.Cpuset, want) {
+		t.Errorf("Expected cpuset %+v, but got %+v", want, info.Cpuset)
+	}
+
+	info, err = cpu.New(ghw.WithChroot(root), ghw.WithCgroup("/"))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if info.Cpuset == nil || len(info.Cpuset.CPUs) != 16 || info.Cpuset.Cgroup != "/" {
+		t.Errorf("Expected the root cgroup cpuset, but got %+v", info.Cpuset)
+	}
+}
+
+func TestCPUCpusetCgroupV1(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	root := t.TempDir()
+	writeCPUFile(t, filepath.Join(root, "proc", "cpuinfo"), []byte("processor\t: 0\n\n"))
+	writeCPUTopology(t, root, [][3]int{{0, 0, 0}})
+	writeCPUFile(t, filepath.Join(root, "proc", "self", "mounts"), []byte(
+		"cgroup2 /sys/fs/cgroup/unified cgroup2 rw,nosuid,nodev,noexec,relatime 0 0\n"+
+			"cgroup /sys/fs/cgroup/cpuset cgroup rw,nosuid,nodev,noexec,relatime,cpuset 0 0\n"))
+	writeCPUFile(t, filepath.Join(root, "proc", "self", "cgroup"), []byte(
+		"5:cpuset:/docker/8ac1f5e0\n0::/docker/8ac1f5e0\n"))
+	cpuset := filepath.Join(root, "sys", "fs", "cgroup", "cpuset", "docker", "8ac1f5e0")
+	writeCPUFile(t, filepath.Join(cpuset, "cpuset.effective_cpus"), []byte("1,3\n"))
+	writeCPUFile(t, filepath.Join(cpuset, "cpuset.effective_mems"), []byte("0\n"))
+
+	info, err := cpu.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	want := &cpu.Cpuset{
+		Cgroup:        "/docker/8ac1f5e0",
+		CgroupVersion: 1,
+		CPUs:          []int{1, 3},
+		Mems:          []int{0},
+	}
+	if !reflect.DeepEqual(info.Cpuset, want) {
+		t.Errorf("Expected cpuset %+v, but got %+v", want, info.Cpuset)
+	}
+}
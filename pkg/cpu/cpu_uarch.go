@@ -0,0 +1,220 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+// intelFamily6Microarchitectures maps the model numbers of Intel family 6
+// processors to their microarchitecture names.
+//
+// See https://en.wikichip.org/wiki/intel/cpuid and the Linux kernel's
+// arch/x86/include/asm/intel-family.h
+var intelFamily6Microarchitectures = map[int]string{
+	0x1A: "Nehalem", 0x1E: "Nehalem", 0x1F: "Nehalem", 0x2E: "Nehalem",
+	0x25: "Westmere", 0x2C: "Westmere", 0x2F: "Westmere",
+	0x2A: "Sandy Bridge", 0x2D: "Sandy Bridge",
+	0x3A: "Ivy Bridge", 0x3E: "Ivy Bridge",
+	0x3C: "Haswell", 0x3F: "Haswell", 0x45: "Haswell", 0x46: "Haswell",
+	0x3D: "Broadwell", 0x47: "Broadwell", 0x4F: "Broadwell", 0x56: "Broadwell",
+	0x4E: "Skylake", 0x5E: "Skylake", 0x55: "Skylake",
+	0x8E: "Kaby Lake", 0x9E: "Kaby Lake",
+	0xA5: "Comet Lake", 0xA6: "Comet Lake",
+	0x66: "Cannon Lake",
+	0x6A: "Ice Lake", 0x6C: "Ice Lake", 0x7D: "Ice Lake", 0x7E: "Ice Lake",
+	0x8C: "Tiger Lake", 0x8D: "Tiger Lake",
+	0xA7: "Rocket Lake",
+	0x97: "Alder Lake", 0x9A: "Alder Lake", 0xBE: "Alder Lake",
+	0xB7: "Raptor Lake", 0xBA: "Raptor Lake", 0xBF: "Raptor Lake",
+	0xAA: "Meteor Lake", 0xAC: "Meteor Lake",
+	0xBD: "Lunar Lake",
+	0xC5: "Arrow Lake", 0xC6: "Arrow Lake",
+	0x8F: "Sapphire Rapids",
+	0xCF: "Emerald Rapids",
+	0xAD: "Granite Rapids", 0xAE: "Granite Rapids",
+	0xAF: "Sierra Forest",
+	0xB6: "Grand Ridge",
+	0x37: "Silvermont", 0x4A: "Silvermont", 0x4D: "Silvermont", 0x5A: "Silvermont", 0x5D: "Silvermont",
+	0x4C: "Airmont",
+	0x5C: "Goldmont", 0x5F: "Goldmont",
+	0x7A: "Goldmont Plus",
+	0x86: "Tremont", 0x96: "Tremont", 0x9C: "Tremont",
+	0x57: "Knights Landing",
+	0x85: "Knights Mill",
+}
+
+// intelMicroarchitecture returns the microarchitecture name of an Intel
+// processor, or "" if unknown
+func intelMicroarchitecture(family, model, stepping int) string {
+	if family != 6 {
+		return ""
+	}
+	// Several generations share a model number and are told apart by their
+	// stepping
+	switch {
+	case model == 0x55 && stepping >= 0x0A:
+		return "Cooper Lake"
+	case model == 0x55 && stepping >= 0x05:
+		return "Cascade Lake"
+	case model == 0x9E && stepping >= 0x0A:
+		return "Coffee Lake"
+	}
+	return intelFamily6Microarchitectures[model]
+}
+
+// amdMicroarchitecture returns the microarchitecture name of an AMD or Hygon
+// processor, or "" if unknown
+//
+// See https://en.wikichip.org/wiki/amd/cpuid
+func amdMicroarchitecture(family, model int) string {
+	switch family {
+	case 0x10:
+		return "K10"
+	case 0x15:
+		switch {
+		case model < 0x10:
+			return "Bulldozer"
+		case model < 0x20:
+			return "Piledriver"
+		case model >= 0x30 && model < 0x40:
+			return "Steamroller"
+		case model >= 0x60 && model < 0x80:
+			return "Excavator"
+		}
+	case 0x16:
+		switch {
+		case model < 0x10:
+			return "Jaguar"
+		case model >= 0x30 && model < 0x40:
+			return "Puma"
+		}
+	case 0x17:
+		switch {
+		case model == 0x08 || model == 0x18:
+			return "Zen+"
+		case model < 0x30:
+			return "Zen"
+		default:
+			return "Zen 2"
+		}
+	case 0x18:
+		// Hygon Dhyana, a licensed Zen derivative
+		return "Zen"
+	case 0x19:
+		switch {
+		case model >= 0x10 && model < 0x20,
+			model >= 0x60 && model < 0x80,
+			model >= 0xA0 && model < 0xB0:
+			return "Zen 4"
+		default:
+			return "Zen 3"
+		}
+	case 0x1A:
+		return "Zen 5"
+	}
+	return ""
+}
+
+// x86Microarchitecture returns the microarchitecture name for the supplied
+// x86 CPUID vendor string, family, model and stepping, or "" if unknown
+func x86Microarchitecture(vendor string, family, model, stepping int) string {
+	switch vendor {
+	case "GenuineIntel":
+		return intelMicroarchitecture(family, model, stepping)
+	case "AuthenticAMD", "HygonGenuine":
+		return amdMicroarchitecture(family, model)
+	}
+	return ""
+}
+
+// armImplementers maps the ARM "CPU implementer" codes found in the MIDR
+// register to the implementer's name
+var armImplementers = map[int]string{
+	0x41: "ARM",
+	0x42: "Broadcom",
+	0x43: "Cavium",
+	0x46: "Fujitsu",
+	0x48: "HiSilicon",
+	0x4E: "NVIDIA",
+	0x50: "APM",
+	0x51: "Qualcomm",
+	0x61: "Apple",
+	0x6D: "Microsoft",
+	0xC0: "Ampere",
+}
+
+// armParts maps ARM "CPU implementer" and "CPU part" codes to the name of the
+// core design
+//
+// See the Linux kernel's arch/arm64/include/asm/cputype.h
+var armParts = map[int]map[int]string{
+	0x41: {
+		0xD03: "Cortex-A53",
+		0xD04: "Cortex-A35",
+		0xD05: "Cortex-A55",
+		0xD07: "Cortex-A57",
+		0xD08: "Cortex-A72",
+		0xD09: "Cortex-A73",
+		0xD0A: "Cortex-A75",
+		0xD0B: "Cortex-A76",
+		0xD0C: "Neoverse-N1",
+		0xD0D: "Cortex-A77",
+		0xD40: "Neoverse-V1",
+		0xD41: "Cortex-A78",
+		0xD44: "Cortex-X1",
+		0xD46: "Cortex-A510",
+		0xD47: "Cortex-A710",
+		0xD48: "Cortex-X2",
+		0xD49: "Neoverse-N2",
+		0xD4A: "Neoverse-E1",
+		0xD4B: "Cortex-A78C",
+		0xD4D: "Cortex-A715",
+		0xD4E: "Cortex-X3",
+		0xD4F: "Neoverse-V2",
+		0xD80: "Cortex-A520",
+		0xD81: "Cortex-A720",
+		0xD82: "Cortex-X4",
+		0xD84: "Neoverse-V3",
+		0xD8E: "Neoverse-N3",
+	},
+	0x43: {
+		0x0A1: "ThunderX",
+		0x0AF: "ThunderX2",
+	},
+	0x46: {
+		0x001: "A64FX",
+	},
+	0x48: {
+		0xD01: "TaiShan v110",
+	},
+	0x4E: {
+		0x004: "Carmel",
+	},
+	0x51: {
+		0x800: "Kryo 2XX Gold",
+		0x801: "Kryo 2XX Silver",
+		0x802: "Kryo 3XX Gold",
+		0x803: "Kryo 3XX Silver",
+		0x804: "Kryo 4XX Gold",
+		0x805: "Kryo 4XX Silver",
+		0xC00: "Falkor",
+		0x001: "Oryon",
+	},
+	0x61: {
+		0x022: "Icestorm",
+		0x023: "Firestorm",
+		0x032: "Blizzard",
+		0x033: "Avalanche",
+	},
+	0xC0: {
+		0xAC3: "Ampere-1",
+		0xAC4: "Ampere-1a",
+	},
+}
+
+// armMicroarchitecture returns the name of the core design for the supplied
+// ARM implementer and part codes, or "" if unknown
+func armMicroarchitecture(implementer, part int) string {
+	return armParts[implementer][part]
+}
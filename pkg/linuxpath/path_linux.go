@@ -63,26 +63,44 @@ func PathRootsFromContext(ctx context.Context) PathRoots {
 }
 
 type Paths struct {
-	SysRoot                string
-	VarLog                 string
-	ProcMeminfo            string
-	ProcCpuinfo            string
-	ProcMounts             string
-	SysKernelMMHugepages   string
-	SysBlock               string
-	SysDevicesSystemNode   string
-	SysDevicesSystemMemory string
-	SysDevicesSystemCPU    string
-	SysBusPciDevices       string
-	SysBusUsbDevices       string
-	SysClassDRM            string
-	SysClassDMI            string
-	SysClassNet            string
-	SysClassTPM            string
-	SysClassWatchdog       string
-	SysFirmwareDeviceTree  string
-	RunUdevData            string
-	DevWatchdog            string
+	SysRoot                        string
+	VarLog                         string
+	ProcMeminfo                    string
+	ProcCpuinfo                    string
+	ProcMounts                     string
+	ProcSelfCgroup                 string
+	ProcNetFibTrie                 string
+	ProcNetIfInet6                 string
+	ProcNetRoute                   string
+	ProcNetIPv6Route               string
+	ProcNetVLAN                    string
+	SysKernelMMHugepages           string
+	SysBlock                       string
+	SysDevicesSystemNode           string
+	SysDevicesSystemMemory         string
+	SysDevicesSystemCPU            string
+	SysDevicesSystemEDACMC         string
+	SysDevicesVirtualMemoryTiering string
+	SysBusPciDevices               string
+	SysBusUsbDevices               string
+	SysBusNdDevices                string
+	SysBusCxlDevices               string
+	SysClassDRM                    string
+	SysClassEnclosure              string
+	SysClassDMI                    string
+	SysClassNet                    string
+	SysClassNVMe                   string
+	SysClassNVMeSubsystem          string
+	SysClassSASDevice              string
+	SysClassSASExpander            string
+	SysClassSCSIHost               string
+	SysClassTPM                    string
+	SysClassWatchdog               string
+	SysFirmwareDeviceTree          string
+	SysFirmwareDMITables           string
+	RunUdevData                    string
+	DevRoot                        string
+	DevWatchdog                    string
 }
 
 // New returns a new Paths struct containing filepath fields relative to the
@@ -91,26 +109,44 @@ func New(ctx context.Context) *Paths {
 	roots := PathRootsFromContext(ctx)
 	chroot := config.Chroot(ctx)
 	return &Paths{
-		SysRoot:                filepath.Join(chroot, roots.Sys),
-		VarLog:                 filepath.Join(chroot, roots.Var, "log"),
-		ProcMeminfo:            filepath.Join(chroot, roots.Proc, "meminfo"),
-		ProcCpuinfo:            filepath.Join(chroot, roots.Proc, "cpuinfo"),
-		ProcMounts:             filepath.Join(chroot, roots.Proc, "self", "mounts"),
-		SysKernelMMHugepages:   filepath.Join(chroot, roots.Sys, "kernel", "mm", "hugepages"),
-		SysBlock:               filepath.Join(chroot, roots.Sys, "block"),
-		SysDevicesSystemNode:   filepath.Join(chroot, roots.Sys, "devices", "system", "node"),
-		SysDevicesSystemMemory: filepath.Join(chroot, roots.Sys, "devices", "system", "memory"),
-		SysDevicesSystemCPU:    filepath.Join(chroot, roots.Sys, "devices", "system", "cpu"),
-		SysBusPciDevices:       filepath.Join(chroot, roots.Sys, "bus", "pci", "devices"),
-		SysBusUsbDevices:       filepath.Join(chroot, roots.Sys, "bus", "usb", "devices"),
-		SysClassDRM:            filepath.Join(chroot, roots.Sys, "class", "drm"),
-		SysClassDMI:            filepath.Join(chroot, roots.Sys, "class", "dmi"),
-		SysClassNet:            filepath.Join(chroot, roots.Sys, "class", "net"),
-		SysClassTPM:            filepath.Join(chroot, roots.Sys, "class", "tpm"),
-		SysClassWatchdog:       filepath.Join(chroot, roots.Sys, "class", "watchdog"),
-		SysFirmwareDeviceTree:  filepath.Join(chroot, roots.Sys, "firmware", "devicetree", "base"),
-		RunUdevData:            filepath.Join(chroot, roots.Run, "udev", "data"),
-		DevWatchdog:            filepath.Join(chroot, roots.Dev, "watchdog"),
+		SysRoot:                        filepath.Join(chroot, roots.Sys),
+		VarLog:                         filepath.Join(chroot, roots.Var, "log"),
+		ProcMeminfo:                    filepath.Join(chroot, roots.Proc, "meminfo"),
+		ProcCpuinfo:                    filepath.Join(chroot, roots.Proc, "cpuinfo"),
+		ProcMounts:                     filepath.Join(chroot, roots.Proc, "self", "mounts"),
+		ProcSelfCgroup:                 filepath.Join(chroot, roots.Proc, "self", "cgroup"),
+		ProcNetFibTrie:                 filepath.Join(chroot, roots.Proc, "net", "fib_trie"),
+		ProcNetIfInet6:                 filepath.Join(chroot, roots.Proc, "net", "if_inet6"),
+		ProcNetRoute:                   filepath.Join(chroot, roots.Proc, "net", "route"),
+		ProcNetIPv6Route:               filepath.Join(chroot, roots.Proc, "net", "ipv6_route"),
+		ProcNetVLAN:                    filepath.Join(chroot, roots.Proc, "net", "vlan"),
+		SysKernelMMHugepages:           filepath.Join(chroot, roots.Sys, "kernel", "mm", "hugepages"),
+		SysBlock:                       filepath.Join(chroot, roots.Sys, "block"),
+		SysDevicesSystemNode:           filepath.Join(chroot, roots.Sys, "devices", "system", "node"),
+		SysDevicesSystemMemory:         filepath.Join(chroot, roots.Sys, "devices", "system", "memory"),
+		SysDevicesSystemCPU:            filepath.Join(chroot, roots.Sys, "devices", "system", "cpu"),
+		SysDevicesSystemEDACMC:         filepath.Join(chroot, roots.Sys, "devices", "system", "edac", "mc"),
+		SysDevicesVirtualMemoryTiering: filepath.Join(chroot, roots.Sys, "devices", "virtual", "memory_tiering"),
+		SysBusPciDevices:               filepath.Join(chroot, roots.Sys, "bus", "pci", "devices"),
+		SysBusUsbDevices:               filepath.Join(chroot, roots.Sys, "bus", "usb", "devices"),
+		SysBusNdDevices:                filepath.Join(chroot, roots.Sys, "bus", "nd", "devices"),
+		SysBusCxlDevices:               filepath.Join(chroot, roots.Sys, "bus", "cxl", "devices"),
+		SysClassDRM:                    filepath.Join(chroot, roots.Sys, "class", "drm"),
+		SysClassEnclosure:              filepath.Join(chroot, roots.Sys, "class", "enclosure"),
+		SysClassDMI:                    filepath.Join(chroot, roots.Sys, "class", "dmi"),
+		SysClassNet:                    filepath.Join(chroot, roots.Sys, "class", "net"),
+		SysClassNVMe:                   filepath.Join(chroot, roots.Sys, "class", "nvme"),
+		SysClassNVMeSubsystem:          filepath.Join(chroot, roots.Sys, "class", "nvme-subsystem"),
+		SysClassSASDevice:              filepath.Join(chroot, roots.Sys, "class", "sas_device"),
+		SysClassSASExpander:            filepath.Join(chroot, roots.Sys, "class", "sas_expander"),
+		SysClassSCSIHost:               filepath.Join(chroot, roots.Sys, "class", "scsi_host"),
+		SysClassTPM:                    filepath.Join(chroot, roots.Sys, "class", "tpm"),
+		SysClassWatchdog:               filepath.Join(chroot, roots.Sys, "class", "watchdog"),
+		SysFirmwareDeviceTree:          filepath.Join(chroot, roots.Sys, "firmware", "devicetree", "base"),
+		SysFirmwareDMITables:           filepath.Join(chroot, roots.Sys, "firmware", "dmi", "tables"),
+		RunUdevData:                    filepath.Join(chroot, roots.Run, "udev", "data"),
+		DevRoot:                        filepath.Join(chroot, roots.Dev),
+		DevWatchdog:                    filepath.Join(chroot, roots.Dev, "watchdog"),
 	}
 }
 
@@ -26,6 +26,29 @@ type Module struct {
 	SerialNumber string `json:"serial_number"`
 	SizeBytes    int64  `json:"size_bytes"`
 	Vendor       string `json:"vendor"`
+	PartNumber   string `json:"part_number"`
+	// Type is the memory technology of the module, e.g. "DDR4", "DDR5" or
+	// "LPDDR5"
+	Type string `json:"type"`
+	// FormFactor is the physical packaging of the module, e.g. "DIMM" or
+	// "SODIMM"
+	FormFactor string `json:"form_factor"`
+	// SpeedMTs is the maximum speed the module is rated for, in megatransfers
+	// per second, or 0 if unknown
+	SpeedMTs uint32 `json:"speed_mts"`
+	// ConfiguredSpeedMTs is the speed the memory controller has configured
+	// the module to run at, in megatransfers per second, or 0 if unknown
+	ConfiguredSpeedMTs uint32 `json:"configured_speed_mts"`
+	// Rank is the number of ranks on the module, or 0 if unknown
+	Rank uint8 `json:"rank"`
+	// DataWidthBits is the data width of the module, in bits, or 0 if unknown
+	DataWidthBits uint16 `json:"data_width_bits"`
+	// TotalWidthBits is the total width of the module, in bits, including
+	// any error correction bits, or 0 if unknown
+	TotalWidthBits uint16 `json:"total_width_bits"`
+	// Empty is true when the Module describes a memory slot with no module
+	// installed
+	Empty bool `json:"empty"`
 }
 
 // HugePageAmounts describes huge page info
@@ -75,9 +98,88 @@ func (a *Area) String() string {
 	return fmt.Sprintf("memory (%s physical, %s usable)", tpbs, tubs)
 }
 
+// EDACDIMM describes the error counters the kernel's EDAC (Error Detection
+// and Correction) subsystem keeps for a single DIMM, or a single rank on
+// memory controllers that report errors per rank.
+type EDACDIMM struct {
+	Index int `json:"index"`
+	// Label is the silkscreen label of the slot, e.g. "CPU_SrcID#0_MC#0_Chan#0_DIMM#0"
+	Label string `json:"label"`
+	// Location is the position of the DIMM in the memory controller's
+	// hierarchy, e.g. "channel 0 slot 0"
+	Location  string `json:"location"`
+	SizeBytes int64  `json:"size_bytes"`
+	// MemoryType is the type of memory reported by the EDAC driver, e.g.
+	// "Registered-DDR4"
+	MemoryType string `json:"memory_type"`
+	// Mode is the error detection and correction mode, e.g. "S4ECD4ED" or
+	// "None"
+	Mode                string `json:"mode"`
+	CorrectableErrors   int64  `json:"correctable_errors"`
+	UncorrectableErrors int64  `json:"uncorrectable_errors"`
+}
+
+// EDACController describes a memory controller registered with the kernel's
+// EDAC subsystem, along with its error counters.
+type EDACController struct {
+	Index int `json:"index"`
+	// Name is the name of the EDAC driver's controller, e.g. "Skylake Socket#0 IMC#0"
+	Name                string `json:"name"`
+	SizeBytes           int64  `json:"size_bytes"`
+	CorrectableErrors   int64  `json:"correctable_errors"`
+	UncorrectableErrors int64  `json:"uncorrectable_errors"`
+	// CorrectableErrorsNoInfo is the number of correctable errors that could
+	// not be attributed to a specific DIMM
+	CorrectableErrorsNoInfo int64 `json:"correctable_errors_no_info"`
+	// UncorrectableErrorsNoInfo is the number of uncorrectable errors that
+	// could not be attributed to a specific DIMM
+	UncorrectableErrorsNoInfo int64 `json:"uncorrectable_errors_no_info"`
+	// SecondsSinceReset is the number of seconds since the error counters
+	// were last reset
+	SecondsSinceReset int64       `json:"seconds_since_reset"`
+	DIMMs             []*EDACDIMM `json:"dimms"`
+}
+
+// ECC describes the error correction capabilities of the host's memory and
+// the memory errors detected so far.
+type ECC struct {
+	// ErrorCorrection is the type of error correction the firmware reports
+	// for system memory, e.g. "None", "Single-bit ECC" or "Multi-bit ECC"
+	ErrorCorrection string `json:"error_correction"`
+	// Enabled is true if the memory is able to detect errors, either
+	// according to the firmware or to the EDAC driver
+	Enabled bool `json:"enabled"`
+	// Controllers contains the memory controllers registered with the
+	// kernel's EDAC subsystem. It is empty if no EDAC driver is loaded.
+	Controllers []*EDACController `json:"controllers"`
+}
+
+// CorrectableErrors returns the total number of correctable errors reported
+// by all memory controllers
+func (e *ECC) CorrectableErrors() int64 {
+	var total int64
+	for _, mc := range e.Controllers {
+		total += mc.CorrectableErrors
+	}
+	return total
+}
+
+// UncorrectableErrors returns the total number of uncorrectable errors
+// reported by all memory controllers
+func (e *ECC) UncorrectableErrors() int64 {
+	var total int64
+	for _, mc := range e.Controllers {
+		total += mc.UncorrectableErrors
+	}
+	return total
+}
+
 // Info contains information about the memory on a host system.
 type Info struct {
 	Area
+	// ECC contains error correction and error counter information. It is nil
+	// when neither the firmware nor the kernel reports anything about it.
+	ECC *ECC `json:"ecc,omitempty"`
 }
 
 // New returns an Info struct that describes the memory on a host system.
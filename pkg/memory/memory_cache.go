@@ -124,9 +124,28 @@ type Cache struct {
 	Type CacheType `json:"type"`
 	// SizeBytes indicates the size of the cache in bytes.
 	SizeBytes uint64 `json:"size_bytes"`
+	// ID is the identifier of the cache among the caches of the same level
+	// and type, or -1 if the kernel does not report it. On AMD processors
+	// the ID of the L3 caches identifies the core complex (CCX).
+	ID int `json:"id"`
+	// LineSizeBytes is the size in bytes of a cache line (the coherency line
+	// size).
+	LineSizeBytes uint32 `json:"line_size_bytes"`
+	// WaysOfAssociativity is the number of ways of the cache. Fully
+	// associative caches report 0.
+	WaysOfAssociativity uint32 `json:"ways_of_associativity"`
+	// NumberOfSets is the number of sets of the cache.
+	NumberOfSets uint32 `json:"number_of_sets"`
+	// PhysicalLinePartition is the number of physical cache lines sharing
+	// the same address tag.
+	PhysicalLinePartition uint32 `json:"physical_line_partition"`
 	// The set of logical processors (hardware threads) that have access to
 	// this cache.
 	LogicalProcessors []uint32 `json:"logical_processors"`
+	// NodeIDs contains the IDs of the NUMA nodes whose logical processors
+	// share this cache. Only set for the host-wide cache list, since a cache
+	// may be shared by several nodes, e.g. with Sub-NUMA Clustering.
+	NodeIDs []int `json:"node_ids,omitempty"`
 }
 
 func (c *Cache) String() string {
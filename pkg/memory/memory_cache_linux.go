@@ -80,32 +80,12 @@ func CachesForNode(ctx context.Context, nodeID int) ([]*Cache, error) {
 			// The cache information is repeated for each node, so here, we
 			// just ensure that we only have a one Cache object for each
 			// unique combination of level, type and processor map
-			level := memoryCacheLevel(ctx, paths, nodeID, lpID, cacheIndex)
-			cacheType := memoryCacheType(ctx, paths, nodeID, lpID, cacheIndex)
-			cacheID := memoryCacheID(ctx, paths, nodeID, lpID, cacheIndex)
-
-			// Generate cache key for deduplication.
-			// Use cache ID if available (modern kernels), otherwise fall back
-			// to shared_cpu_map for older kernels. This ensures that caches
-			// shared across NUMA nodes (e.g., L3 in Sub-NUMA Clustering) are
-			// correctly merged into a single cache object.
-			var cacheKey string
-			if cacheID != -1 {
-				cacheKey = fmt.Sprintf("%d-%d-%d", level, cacheType, cacheID)
+			indexPath := paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex)
+			cacheKey, cache := memoryCacheKey(ctx, indexPath)
+			if existing, exists := caches[cacheKey]; exists {
+				cache = existing
 			} else {
-				sharedCpuMap := memoryCacheSharedCPUMap(ctx, paths, nodeID, lpID, cacheIndex)
-				cacheKey = fmt.Sprintf("%d-%d-%s", level, cacheType, sharedCpuMap)
-			}
-
-			cache, exists := caches[cacheKey]
-			if !exists {
-				size := memoryCacheSize(ctx, paths, nodeID, lpID, level)
-				cache = &Cache{
-					Level:             uint8(level),
-					Type:              cacheType,
-					SizeBytes:         uint64(size) * uint64(unitutil.KB),
-					LogicalProcessors: make([]uint32, 0),
-				}
+				memoryCacheDetails(ctx, indexPath, cache)
 				caches[cacheKey] = cache
 			}
 			cache.LogicalProcessors = append(
@@ -127,15 +107,157 @@ func CachesForNode(ctx context.Context, nodeID int) ([]*Cache, error) {
 	return cacheVals, nil
 }
 
+// Caches returns the deduplicated list of the host's memory caches, read from
+// the /sys/devices/system/cpu/cpuX/cache/indexY directories of all logical
+// processors. Unlike CachesForNode, caches shared by the logical processors
+// of several NUMA nodes are reported once, with the IDs of those nodes.
+func Caches(ctx context.Context) ([]*Cache, error) {
+	paths := linuxpath.New(ctx)
+	files, err := os.ReadDir(paths.SysDevicesSystemCPU)
+	if err != nil {
+		return nil, err
+	}
+	lpNodes := logicalProcessorNodes(paths)
+	caches := make(map[string]*Cache)
+	nodeSets := make(map[*Cache]map[int]bool)
+	for _, file := range files {
+		filename := file.Name()
+		if !strings.HasPrefix(filename, "cpu") {
+			continue
+		}
+		lpID, err := strconv.Atoi(filename[3:])
+		if err != nil {
+			// cpufreq, cpuidle...
+			continue
+		}
+		cachePath := filepath.Join(paths.SysDevicesSystemCPU, filename, "cache")
+		cacheDirFiles, err := os.ReadDir(cachePath)
+		if err != nil {
+			continue
+		}
+		for _, cacheDirFile := range cacheDirFiles {
+			if !strings.HasPrefix(cacheDirFile.Name(), "index") {
+				continue
+			}
+			indexPath := filepath.Join(cachePath, cacheDirFile.Name())
+			cacheKey, cache := memoryCacheKey(ctx, indexPath)
+			if existing, exists := caches[cacheKey]; exists {
+				cache = existing
+			} else {
+				memoryCacheDetails(ctx, indexPath, cache)
+				caches[cacheKey] = cache
+				nodeSets[cache] = map[int]bool{}
+			}
+			cache.LogicalProcessors = append(cache.LogicalProcessors, uint32(lpID))
+			if nodeID, ok := lpNodes[lpID]; ok {
+				nodeSets[cache][nodeID] = true
+			}
+		}
+	}
+
+	cacheVals := make([]*Cache, 0, len(caches))
+	for _, c := range caches {
+		sort.Sort(SortByLogicalProcessorId(c.LogicalProcessors))
+		for nodeID := range nodeSets[c] {
+			c.NodeIDs = append(c.NodeIDs, nodeID)
+		}
+		sort.Ints(c.NodeIDs)
+		cacheVals = append(cacheVals, c)
+	}
+	sort.Sort(SortByCacheLevelTypeFirstProcessor(cacheVals))
+	return cacheVals, nil
+}
+
+// logicalProcessorNodes returns the NUMA node of each logical processor,
+// keyed by logical processor ID, using the cpuX entries of the
+// /sys/devices/system/node/nodeY directories.
+func logicalProcessorNodes(paths *linuxpath.Paths) map[int]int {
+	lpNodes := map[int]int{}
+	nodes, err := os.ReadDir(paths.SysDevicesSystemNode)
+	if err != nil {
+		return lpNodes
+	}
+	for _, node := range nodes {
+		if !strings.HasPrefix(node.Name(), "node") {
+			continue
+		}
+		nodeID, err := strconv.Atoi(node.Name()[4:])
+		if err != nil {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(paths.SysDevicesSystemNode, node.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), "cpu") {
+				continue
+			}
+			if lpID, err := strconv.Atoi(file.Name()[3:]); err == nil {
+				lpNodes[lpID] = nodeID
+			}
+		}
+	}
+	return lpNodes
+}
+
+// memoryCacheKey returns the key used to deduplicate the cache described by
+// the supplied cache index directory along with a new Cache holding its
+// level, type and ID.
+func memoryCacheKey(ctx context.Context, indexPath string) (string, *Cache) {
+	level := memoryCacheLevel(ctx, indexPath)
+	cacheType := memoryCacheType(ctx, indexPath)
+	cacheID := memoryCacheID(ctx, indexPath)
+	cache := &Cache{
+		Level:             uint8(level),
+		Type:              cacheType,
+		ID:                cacheID,
+		LogicalProcessors: make([]uint32, 0),
+	}
+
+	// Use cache ID if available (modern kernels), otherwise fall back to
+	// shared_cpu_map for older kernels. This ensures that caches shared
+	// across NUMA nodes (e.g., L3 in Sub-NUMA Clustering) are correctly
+	// merged into a single cache object.
+	if cacheID != -1 {
+		return fmt.Sprintf("%d-%d-%d", level, cacheType, cacheID), cache
+	}
+	sharedCpuMap := memoryCacheSharedCPUMap(ctx, indexPath)
+	return fmt.Sprintf("%d-%d-%s", level, cacheType, sharedCpuMap), cache
+}
+
+// memoryCacheDetails sets the size and geometry of the cache described by
+// the supplied cache index directory. The geometry files do not exist on all
+// architectures and are left at zero when missing.
+func memoryCacheDetails(ctx context.Context, indexPath string, cache *Cache) {
+	size := memoryCacheSize(ctx, indexPath)
+	if size > 0 {
+		cache.SizeBytes = uint64(size) * uint64(unitutil.KB)
+	}
+	cache.LineSizeBytes = memoryCacheAttr(indexPath, "coherency_line_size")
+	cache.WaysOfAssociativity = memoryCacheAttr(indexPath, "ways_of_associativity")
+	cache.NumberOfSets = memoryCacheAttr(indexPath, "number_of_sets")
+	cache.PhysicalLinePartition = memoryCacheAttr(indexPath, "physical_line_partition")
+}
+
+func memoryCacheAttr(indexPath string, name string) uint32 {
+	data, err := os.ReadFile(filepath.Join(indexPath, name))
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(v)
+}
+
 func memoryCacheLevel(
 	ctx context.Context,
-	paths *linuxpath.Paths,
-	nodeID int,
-	lpID int,
-	cacheIndex int,
+	indexPath string,
 ) int {
 	levelPath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
+		indexPath,
 		"level",
 	)
 	levelContents, err := os.ReadFile(levelPath)
@@ -155,13 +277,10 @@ func memoryCacheLevel(
 
 func memoryCacheID(
 	ctx context.Context,
-	paths *linuxpath.Paths,
-	nodeID int,
-	lpID int,
-	cacheIndex int,
+	indexPath string,
 ) int {
 	idPath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
+		indexPath,
 		"id",
 	)
 	idContents, err := os.ReadFile(idPath)
@@ -179,13 +298,10 @@ func memoryCacheID(
 
 func memoryCacheSize(
 	ctx context.Context,
-	paths *linuxpath.Paths,
-	nodeID int,
-	lpID int,
-	cacheIndex int,
+	indexPath string,
 ) int {
 	sizePath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
+		indexPath,
 		"size",
 	)
 	sizeContents, err := os.ReadFile(sizePath)
@@ -204,13 +320,10 @@ func memoryCacheSize(
 
 func memoryCacheType(
 	ctx context.Context,
-	paths *linuxpath.Paths,
-	nodeID int,
-	lpID int,
-	cacheIndex int,
+	indexPath string,
 ) CacheType {
 	typePath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
+		indexPath,
 		"type",
 	)
 	cacheTypeContents, err := os.ReadFile(typePath)
@@ -230,13 +343,10 @@ func memoryCacheType(
 
 func memoryCacheSharedCPUMap(
 	ctx context.Context,
-	paths *linuxpath.Paths,
-	nodeID int,
-	lpID int,
-	cacheIndex int,
+	indexPath string,
 ) string {
 	scpuPath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
+		indexPath,
 		"shared_cpu_map",
 	)
 	sharedCpuMap, err := os.ReadFile(scpuPath)
@@ -0,0 +1,129 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/testutil"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+)
+
+// writeSNCCacheTree writes the cache hierarchy of a processor with two
+// logical processors, each in its own Sub-NUMA Clustering node, sharing a
+// single L3 cache
+func writeSNCCacheTree(t *testing.T, root string) {
+	t.Helper()
+	cpuDir := filepath.Join(root, "sys", "devices", "system", "cpu")
+	nodeDir := filepath.Join(root, "sys", "devices", "system", "node")
+	for lpID := 0; lpID < 2; lpID++ {
+		cache := fmt.Sprintf("cpu%d/cache/", lpID)
+		testutil.WriteSysfsFiles(t, cpuDir, map[string]string{
+			cache + "index0/level":                   "1",
+			cache + "index0/type":                    "Data",
+			cache + "index0/id":                      fmt.Sprint(lpID),
+			cache + "index0/size":                    "48K",
+			cache + "index0/coherency_line_size":     "64",
+			cache + "index0/ways_of_associativity":   "12",
+			cache + "index0/number_of_sets":          "64",
+			cache + "index0/physical_line_partition": "1",
+			cache + "index1/level":                   "1",
+			cache + "index1/type":                    "Instruction",
+			cache + "index1/id":                      fmt.Sprint(lpID),
+			cache + "index1/size":                    "32K",
+			cache + "index2/level":                   "2",
+			cache + "index2/type":                    "Unified",
+			cache + "index2/id":                      fmt.Sprint(lpID),
+			cache + "index2/size":                    "2048K",
+			cache + "index3/level":                   "3",
+			cache + "index3/type":                    "Unified",
+			cache + "index3/id":                      "0",
+			cache + "index3/size":                    "107520K",
+			cache + "index3/coherency_line_size":     "64",
+			cache + "index3/ways_of_associativity":   "15",
+			cache + "index3/number_of_sets":          "114688",
+			cache + "index3/physical_line_partition": "1",
+		})
+		node := filepath.Join(nodeDir, fmt.Sprintf("node%d", lpID))
+		if err := os.MkdirAll(node, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(
+			filepath.Join("..", "..", "cpu", fmt.Sprintf("cpu%d", lpID)),
+			filepath.Join(node, fmt.Sprintf("cpu%d", lpID)),
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCachesHostWide(t *testing.T) {
+	root := t.TempDir()
+	writeSNCCacheTree(t, root)
+	ctx := config.WithChroot(root)(context.TODO())
+
+	caches, err := Caches(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	// L1d, L1i and L2 for each logical processor and a single L3
+	if len(caches) != 7 {
+		t.Fatalf("Expected 7 caches, but got %d: %v", len(caches), caches)
+	}
+	l1d := caches[2]
+	if l1d.Level != 1 || l1d.Type != CacheTypeData || l1d.SizeBytes != 48*uint64(unitutil.KB) {
+		t.Errorf("unexpected L1d cache: %+v", l1d)
+	}
+	if l1d.LineSizeBytes != 64 || l1d.WaysOfAssociativity != 12 || l1d.NumberOfSets != 64 || l1d.PhysicalLinePartition != 1 {
+		t.Errorf("unexpected L1d cache geometry: %+v", l1d)
+	}
+	if !reflect.DeepEqual(l1d.NodeIDs, []int{0}) {
+		t.Errorf("Expected L1d cache to be in node 0, but got %v", l1d.NodeIDs)
+	}
+	l2 := caches[4]
+	if l2.Level != 2 || l2.SizeBytes != 2048*uint64(unitutil.KB) {
+		t.Errorf("unexpected L2 cache: %+v", l2)
+	}
+	l3 := caches[6]
+	if l3.Level != 3 || l3.ID != 0 || l3.SizeBytes != 107520*uint64(unitutil.KB) {
+		t.Errorf("unexpected L3 cache: %+v", l3)
+	}
+	if !reflect.DeepEqual(l3.LogicalProcessors, []uint32{0, 1}) {
+		t.Errorf("Expected L3 cache shared by logical processors 0 and 1, but got %v", l3.LogicalProcessors)
+	}
+	if !reflect.DeepEqual(l3.NodeIDs, []int{0, 1}) {
+		t.Errorf("Expected L3 cache shared by nodes 0 and 1, but got %v", l3.NodeIDs)
+	}
+}
+
+func TestCachesForNodeSize(t *testing.T) {
+	root := t.TempDir()
+	writeSNCCacheTree(t, root)
+	ctx := config.WithChroot(root)(context.TODO())
+
+	caches, err := CachesForNode(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	// Each cache's size must be read from its own index directory, not from
+	// the index matching its level
+	want := map[CacheType]uint64{CacheTypeData: 48, CacheTypeInstruction: 32}
+	for _, c := range caches {
+		if size, ok := want[c.Type]; ok && c.SizeBytes != size*uint64(unitutil.KB) {
+			t.Errorf("Expected %s cache size %dK, but got %d bytes", c, size, c.SizeBytes)
+		}
+	}
+	if len(caches) != 4 {
+		t.Fatalf("Expected 4 caches, but got %d", len(caches))
+	}
+}
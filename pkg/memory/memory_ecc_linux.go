@@ -0,0 +1,179 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/smbios"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+var (
+	// regexEDACMCDirname matches a memory controller directory in
+	// /sys/devices/system/edac/mc
+	regexEDACMCDirname = regexp.MustCompile(`^mc(\d+)$`)
+	// regexEDACDIMMDirname matches a DIMM or rank directory of a memory
+	// controller. Drivers that can attribute errors to a DIMM create dimmX
+	// directories, others create one rankX directory per rank.
+	regexEDACDIMMDirname = regexp.MustCompile(`^(?:dimm|rank)(\d+)$`)
+)
+
+// memoryECC returns the error correction information for the host, combining
+// the error correction type from the SMBIOS Physical Memory Array structures
+// (when table is not nil) with the error counters of the EDAC memory
+// controllers. It returns nil if neither source has anything to report.
+func memoryECC(ctx context.Context, paths *linuxpath.Paths, table *smbios.Table) *ECC {
+	ecc := &ECC{ErrorCorrection: util.UNKNOWN}
+	haveFirmwareInfo := false
+	if table != nil {
+		for _, array := range table.PhysicalMemoryArrays() {
+			if array.Use != smbios.MemoryArrayUseSystemMemory {
+				continue
+			}
+			ecc.ErrorCorrection = array.ErrorCorrection.String()
+			ecc.Enabled = array.ErrorCorrection.Detects()
+			haveFirmwareInfo = true
+			break
+		}
+	}
+
+	ctrls, err := edacControllers(paths.SysDevicesSystemEDACMC)
+	if err != nil && !os.IsNotExist(err) {
+		log.Debug(ctx, "unable to read EDAC memory controllers: %v", err)
+	}
+	ecc.Controllers = ctrls
+	if !haveFirmwareInfo {
+		if len(ctrls) == 0 {
+			return nil
+		}
+		ecc.Enabled = edacModeEnabled(ctrls)
+	}
+	return ecc
+}
+
+// edacModeEnabled returns true if any DIMM of the supplied controllers has an
+// EDAC mode other than "None".
+func edacModeEnabled(ctrls []*EDACController) bool {
+	for _, mc := range ctrls {
+		for _, dimm := range mc.DIMMs {
+			if dimm.Mode != "" && dimm.Mode != "None" && dimm.Mode != "Unknown" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// edacControllers reads the memory controllers registered with the EDAC
+// subsystem. The layout of the sysfs tree is:
+//
+// /sys/devices/system/edac/mc/mc0/
+// ├── ce_count
+// ├── ce_noinfo_count
+// ├── dimm0
+// │   ├── dimm_ce_count
+// │   ├── dimm_edac_mode
+// │   ├── dimm_label
+// │   ├── dimm_location
+// │   ├── dimm_mem_type
+// │   ├── dimm_ue_count
+// │   └── size
+// ├── mc_name
+// ├── seconds_since_reset
+// ├── size_mb
+// ├── ue_count
+// └── ue_noinfo_count
+//
+// See https://www.kernel.org/doc/html/latest/admin-guide/ras.html
+func edacControllers(dir string) ([]*EDACController, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ctrls := make([]*EDACController, 0, len(entries))
+	for _, entry := range entries {
+		matches := regexEDACMCDirname.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(matches[1])
+		mcPath := filepath.Join(dir, entry.Name())
+		mc := &EDACController{
+			Index:                     index,
+			Name:                      readEDACString(filepath.Join(mcPath, "mc_name")),
+			SizeBytes:                 readEDACInt64(filepath.Join(mcPath, "size_mb")) * unitutil.MB,
+			CorrectableErrors:         readEDACInt64(filepath.Join(mcPath, "ce_count")),
+			UncorrectableErrors:       readEDACInt64(filepath.Join(mcPath, "ue_count")),
+			CorrectableErrorsNoInfo:   readEDACInt64(filepath.Join(mcPath, "ce_noinfo_count")),
+			UncorrectableErrorsNoInfo: readEDACInt64(filepath.Join(mcPath, "ue_noinfo_count")),
+			SecondsSinceReset:         readEDACInt64(filepath.Join(mcPath, "seconds_since_reset")),
+			DIMMs:                     edacDIMMs(mcPath),
+		}
+		ctrls = append(ctrls, mc)
+	}
+	sort.Slice(ctrls, func(i, j int) bool {
+		return ctrls[i].Index < ctrls[j].Index
+	})
+	return ctrls, nil
+}
+
+// edacDIMMs reads the DIMM (or rank) subdirectories of an EDAC memory
+// controller directory.
+func edacDIMMs(mcPath string) []*EDACDIMM {
+	dimms := []*EDACDIMM{}
+	entries, err := os.ReadDir(mcPath)
+	if err != nil {
+		return dimms
+	}
+	for _, entry := range entries {
+		matches := regexEDACDIMMDirname.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(matches[1])
+		dimmPath := filepath.Join(mcPath, entry.Name())
+		dimms = append(dimms, &EDACDIMM{
+			Index:               index,
+			Label:               readEDACString(filepath.Join(dimmPath, "dimm_label")),
+			Location:            readEDACString(filepath.Join(dimmPath, "dimm_location")),
+			SizeBytes:           readEDACInt64(filepath.Join(dimmPath, "size")) * unitutil.MB,
+			MemoryType:          readEDACString(filepath.Join(dimmPath, "dimm_mem_type")),
+			Mode:                readEDACString(filepath.Join(dimmPath, "dimm_edac_mode")),
+			CorrectableErrors:   readEDACInt64(filepath.Join(dimmPath, "dimm_ce_count")),
+			UncorrectableErrors: readEDACInt64(filepath.Join(dimmPath, "dimm_ue_count")),
+		})
+	}
+	sort.Slice(dimms, func(i, j int) bool {
+		return dimms[i].Index < dimms[j].Index
+	})
+	return dimms
+}
+
+func readEDACString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readEDACInt64(path string) int64 {
+	val, err := strconv.ParseInt(readEDACString(path), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
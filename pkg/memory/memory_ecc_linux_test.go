@@ -0,0 +1,90 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/testutil"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+)
+
+func TestMemoryECCFromEDAC(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteSysfsFiles(t, filepath.Join(root, "sys", "devices", "system", "edac", "mc"), map[string]string{
+		"mc1/mc_name":                  "Skylake Socket#1 IMC#0",
+		"mc1/size_mb":                  "32768",
+		"mc1/ce_count":                 "0",
+		"mc1/ue_count":                 "0",
+		"mc0/mc_name":                  "Skylake Socket#0 IMC#0",
+		"mc0/size_mb":                  "65536",
+		"mc0/ce_count":                 "7",
+		"mc0/ue_count":                 "1",
+		"mc0/ce_noinfo_count":          "2",
+		"mc0/ue_noinfo_count":          "0",
+		"mc0/seconds_since_reset":      "86400",
+		"mc0/dimm0/dimm_label":         "CPU_SrcID#0_MC#0_Chan#0_DIMM#0",
+		"mc0/dimm0/dimm_location":      "channel 0 slot 0",
+		"mc0/dimm0/size":               "32768",
+		"mc0/dimm0/dimm_mem_type":      "Registered-DDR4",
+		"mc0/dimm0/dimm_edac_mode":     "S4ECD4ED",
+		"mc0/dimm0/dimm_ce_count":      "5",
+		"mc0/dimm0/dimm_ue_count":      "1",
+		"mc0/dimm1/dimm_label":         "CPU_SrcID#0_MC#0_Chan#1_DIMM#0",
+		"mc0/dimm1/dimm_edac_mode":     "S4ECD4ED",
+		"mc0/dimm1/dimm_ce_count":      "0",
+		"mc0/dimm1/dimm_ue_count":      "0",
+		"power/async":                  "disabled",
+		"mc0/not-a-dimm/dimm_ce_count": "100",
+	})
+
+	ctx := config.WithChroot(root)(context.TODO())
+	ecc := memoryECC(ctx, linuxpath.New(ctx), nil)
+	if ecc == nil {
+		t.Fatal("Expected ECC info, but got nil")
+	}
+	if !ecc.Enabled {
+		t.Errorf("Expected ECC to be enabled")
+	}
+	if len(ecc.Controllers) != 2 {
+		t.Fatalf("Expected 2 memory controllers, but got %d", len(ecc.Controllers))
+	}
+	mc := ecc.Controllers[0]
+	if mc.Index != 0 || mc.Name != "Skylake Socket#0 IMC#0" || mc.SizeBytes != 64*unitutil.GB {
+		t.Errorf("unexpected memory controller: %+v", mc)
+	}
+	if mc.CorrectableErrorsNoInfo != 2 || mc.SecondsSinceReset != 86400 {
+		t.Errorf("unexpected memory controller: %+v", mc)
+	}
+	if got := ecc.CorrectableErrors(); got != 7 {
+		t.Errorf("Expected 7 correctable errors, but got %d", got)
+	}
+	if got := ecc.UncorrectableErrors(); got != 1 {
+		t.Errorf("Expected 1 uncorrectable error, but got %d", got)
+	}
+	if len(mc.DIMMs) != 2 {
+		t.Fatalf("Expected 2 DIMMs, but got %d", len(mc.DIMMs))
+	}
+	dimm := mc.DIMMs[0]
+	if dimm.Location != "channel 0 slot 0" || dimm.SizeBytes != 32*unitutil.GB || dimm.MemoryType != "Registered-DDR4" {
+		t.Errorf("unexpected DIMM: %+v", dimm)
+	}
+	if dimm.CorrectableErrors != 5 || dimm.UncorrectableErrors != 1 {
+		t.Errorf("unexpected DIMM error counters: %+v", dimm)
+	}
+}
+
+func TestMemoryECCNotAvailable(t *testing.T) {
+	ctx := config.WithChroot(t.TempDir())(context.TODO())
+	if ecc := memoryECC(ctx, linuxpath.New(ctx), nil); ecc != nil {
+		t.Fatalf("Expected nil ECC info, but got %+v", ecc)
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/jaypipes/ghw/internal/log"
 	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/unitutil"
 	"github.com/jaypipes/ghw/pkg/util"
 )
@@ -45,7 +46,19 @@ func (i *Info) load(ctx context.Context) error {
 		return fmt.Errorf("Could not determine total usable bytes of memory")
 	}
 	i.TotalUsableBytes = tub
-	tpb := memTotalPhysicalBytes(paths)
+	tpb := int64(-1)
+	table, err := smbios.Load(ctx)
+	if err == nil {
+		i.Modules = memoryModulesFromSMBIOS(table.MemoryDevices())
+		tpb = memTotalPhysicalBytesFromSMBIOS(table)
+	} else {
+		log.Debug(ctx, "unable to load SMBIOS tables: %v", err)
+		table = nil
+	}
+	i.ECC = memoryECC(ctx, paths, table)
+	if tpb < 1 {
+		tpb = memTotalPhysicalBytes(paths)
+	}
 	i.TotalPhysicalBytes = tpb
 	if tpb < 1 {
 		log.Warn(ctx, warnCannotDeterminePhysicalMemory)
@@ -206,6 +219,57 @@ func memoryTotalPhysicalBytesFromPath(dir string, blockSizeBytes uint64) (int64,
 	return total, nil
 }
 
+// memTotalPhysicalBytesFromSMBIOS returns the sum of the sizes of all memory
+// modules installed in the host according to the SMBIOS Memory Device
+// structures, or -1 if the table describes no installed memory. This is the
+// most accurate source of installed physical memory, since it does not depend
+// on which memory blocks the kernel has onlined.
+func memTotalPhysicalBytesFromSMBIOS(table *smbios.Table) int64 {
+	var total int64
+	for _, dev := range table.MemoryDevices() {
+		// Logical non-volatile devices (NVDIMMs) are not system RAM.
+		if dev.Type == smbios.MemoryTypeLogicalNonVolatile {
+			continue
+		}
+		total += int64(dev.SizeBytes)
+	}
+	if total == 0 {
+		return -1
+	}
+	return total
+}
+
+// memoryModulesFromSMBIOS converts the SMBIOS Memory Device structures into
+// Modules. Every slot described by the firmware is returned, including empty
+// ones, which have Empty set to true.
+func memoryModulesFromSMBIOS(devs []*smbios.MemoryDevice) []*Module {
+	mods := make([]*Module, 0, len(devs))
+	for _, dev := range devs {
+		mod := &Module{
+			Label:    dev.BankLocator,
+			Location: dev.DeviceLocator,
+			Empty:    !dev.Installed,
+		}
+		if !dev.Installed {
+			mods = append(mods, mod)
+			continue
+		}
+		mod.SerialNumber = dev.SerialNumber
+		mod.SizeBytes = int64(dev.SizeBytes)
+		mod.Vendor = dev.Manufacturer
+		mod.PartNumber = dev.PartNumber
+		mod.Type = dev.Type.String()
+		mod.FormFactor = dev.FormFactor.String()
+		mod.SpeedMTs = dev.SpeedMTs
+		mod.ConfiguredSpeedMTs = dev.ConfiguredSpeedMTs
+		mod.Rank = dev.Rank
+		mod.DataWidthBits = dev.DataWidthBits
+		mod.TotalWidthBits = dev.TotalWidthBits
+		mods = append(mods, mod)
+	}
+	return mods
+}
+
 func memTotalPhysicalBytesFromSyslog(paths *linuxpath.Paths) int64 {
 	// In Linux, the total physical memory can be determined by looking at the
 	// output of dmidecode, however dmidecode requires root privileges to run,
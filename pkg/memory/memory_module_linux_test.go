@@ -0,0 +1,68 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package memory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/ghw/pkg/smbios"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+)
+
+func TestMemoryModulesFromSMBIOS(t *testing.T) {
+	devs := []*smbios.MemoryDevice{
+		{
+			DeviceLocator:      "DIMM_A1",
+			BankLocator:        "P0 CHANNEL A",
+			Installed:          true,
+			SizeBytes:          uint64(32 * unitutil.GB),
+			FormFactor:         0x09,
+			Type:               0x22,
+			SpeedMTs:           5600,
+			ConfiguredSpeedMTs: 4800,
+			Manufacturer:       "Samsung",
+			SerialNumber:       "03A1B2C3",
+			PartNumber:         "M321R4GA3BB6-CQKET",
+			Rank:               2,
+			DataWidthBits:      64,
+			TotalWidthBits:     80,
+		},
+		{
+			DeviceLocator: "DIMM_A2",
+			BankLocator:   "P0 CHANNEL A",
+			Manufacturer:  "NO DIMM",
+		},
+	}
+
+	got := memoryModulesFromSMBIOS(devs)
+	want := []*Module{
+		{
+			Label:              "P0 CHANNEL A",
+			Location:           "DIMM_A1",
+			SerialNumber:       "03A1B2C3",
+			SizeBytes:          32 * unitutil.GB,
+			Vendor:             "Samsung",
+			PartNumber:         "M321R4GA3BB6-CQKET",
+			Type:               "DDR5",
+			FormFactor:         "DIMM",
+			SpeedMTs:           5600,
+			ConfiguredSpeedMTs: 4800,
+			Rank:               2,
+			DataWidthBits:      64,
+			TotalWidthBits:     80,
+		},
+		{
+			Label:    "P0 CHANNEL A",
+			Location: "DIMM_A2",
+			Empty:    true,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %+v, but got %+v", want, got)
+	}
+}
@@ -8,6 +8,7 @@ package memory
 
 import (
 	"context"
+	"strings"
 
 	"github.com/yusufpapurcu/wmi"
 
@@ -54,13 +55,26 @@ func (i *Info) load(ctx context.Context) error {
 	i.Modules = make([]*Module, 0, len(win32MemDescriptions))
 	for _, description := range win32MemDescriptions {
 		totalPhysicalBytes += *description.Capacity
-		i.Modules = append(i.Modules, &Module{
+		mod := &Module{
 			Label:        *description.BankLabel,
 			Location:     *description.DeviceLocator,
 			SerialNumber: *description.SerialNumber,
 			SizeBytes:    int64(*description.Capacity),
 			Vendor:       *description.Manufacturer,
-		})
+		}
+		if description.PartNumber != nil {
+			mod.PartNumber = strings.TrimSpace(*description.PartNumber)
+		}
+		if description.Speed != nil {
+			mod.SpeedMTs = *description.Speed
+		}
+		if description.DataWidth != nil {
+			mod.DataWidthBits = *description.DataWidth
+		}
+		if description.TotalWidth != nil {
+			mod.TotalWidthBits = *description.TotalWidth
+		}
+		i.Modules = append(i.Modules, mod)
 	}
 	var totalUsableBytes uint64
 	for _, description := range win32OSDescriptions {
@@ -27,6 +27,139 @@ type NICCapability struct {
 	CanEnable bool `json:"can_enable"`
 }
 
+// NICAddress describes an IP address assigned to a NIC
+type NICAddress struct {
+	// IP is the address, e.g. "192.0.2.10" or "2001:db8::10"
+	IP string `json:"ip"`
+	// PrefixLength is the length of the network prefix of the address, e.g.
+	// 24 for 192.0.2.10/24
+	PrefixLength int `json:"prefix_length"`
+	// Scope is the scope of an IPv6 address, e.g. "global" or "link"
+	Scope string `json:"scope,omitempty"`
+}
+
+// String returns the address in CIDR notation, e.g. "192.0.2.10/24"
+func (a *NICAddress) String() string {
+	return fmt.Sprintf("%s/%d", a.IP, a.PrefixLength)
+}
+
+// NICKind is the kind of a virtual NIC, which is the name of the kernel
+// driver implementing it, as in `ip link add type <kind>`.
+type NICKind string
+
+const (
+	NICKindBond      NICKind = "bond"
+	NICKindBridge    NICKind = "bridge"
+	NICKindVLAN      NICKind = "vlan"
+	NICKindMACVLAN   NICKind = "macvlan"
+	NICKindIPVLAN    NICKind = "ipvlan"
+	NICKindVXLAN     NICKind = "vxlan"
+	NICKindVeth      NICKind = "veth"
+	NICKindTun       NICKind = "tun"
+	NICKindTap       NICKind = "tap"
+	NICKindTeam      NICKind = "team"
+	NICKindWireGuard NICKind = "wireguard"
+	NICKindDummy     NICKind = "dummy"
+)
+
+// NICBond describes the settings of a bond NIC, which aggregates the NICs
+// enslaved to it.
+type NICBond struct {
+	// Mode is the bonding mode, e.g. "active-backup" or "802.3ad".
+	Mode string `json:"mode"`
+	// ActiveSlave is the name of the slave currently carrying the traffic,
+	// in the active-backup, balance-alb and balance-tlb modes.
+	ActiveSlave string `json:"active_slave,omitempty"`
+	// MIIMon is the interval in milliseconds at which the link of the slaves
+	// is monitored, or 0 if it is not.
+	MIIMon int `json:"miimon"`
+	// XmitHashPolicy is the policy selecting the slave a packet is sent
+	// through, e.g. "layer2" or "layer3+4", in the balance-xor and 802.3ad
+	// modes.
+	XmitHashPolicy string `json:"xmit_hash_policy,omitempty"`
+	// LACPRate is the rate at which LACPDUs are requested from the partner,
+	// "slow" or "fast", in the 802.3ad mode.
+	LACPRate string `json:"lacp_rate,omitempty"`
+}
+
+// NICBridge describes the settings of a bridge NIC, which switches the
+// traffic between its ports.
+type NICBridge struct {
+	// STPEnabled is true if the bridge runs the Spanning Tree Protocol.
+	STPEnabled bool `json:"stp_enabled"`
+	// VLANFiltering is true if the bridge filters the traffic of its ports
+	// by VLAN.
+	VLANFiltering bool `json:"vlan_filtering"`
+}
+
+// NICVLAN describes the VLAN of a VLAN NIC.
+type NICVLAN struct {
+	// ID is the VLAN ID.
+	ID int `json:"id"`
+	// Protocol is the protocol of the VLAN tag, "802.1Q" or "802.1ad", when
+	// known.
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// NICVXLAN describes the settings of a VXLAN NIC. They are only known when
+// ghw can query the kernel, i.e. not from a snapshot.
+type NICVXLAN struct {
+	// VNI is the VXLAN Network Identifier.
+	VNI int `json:"vni"`
+	// Port is the destination UDP port of the VXLAN packets.
+	Port int `json:"port"`
+	// Remote is the IP address of the remote VTEP or of the multicast group
+	// the VXLAN packets are sent to, if any.
+	Remote string `json:"remote,omitempty"`
+	// Local is the source IP address of the VXLAN packets, if set.
+	Local string `json:"local,omitempty"`
+}
+
+// NICVirtualFunction describes an SR-IOV virtual function (VF) of a NIC and
+// the settings its physical function applies to it.
+type NICVirtualFunction struct {
+	// Index is the index of the VF within its physical function.
+	Index int `json:"index"`
+	// PCIAddress is the PCI address of the VF.
+	PCIAddress string `json:"pci_address"`
+	// Name is the name of the NIC of the VF, if the VF is bound to a network
+	// driver of the host rather than, e.g., passed through to a VM.
+	Name string `json:"name,omitempty"`
+	// MACAddress is the MAC address the physical function assigned to the
+	// VF, or is empty if none is assigned.
+	MACAddress string `json:"mac_address,omitempty"`
+	// VLAN is the ID of the VLAN the physical function tags the traffic of
+	// the VF with, or 0 if none.
+	VLAN int `json:"vlan,omitempty"`
+	// QoS is the 802.1p priority of the VLAN tag of the VF.
+	QoS int `json:"qos,omitempty"`
+	// SpoofCheck is true if the physical function drops the frames the VF
+	// sends with a source MAC address other than its own.
+	SpoofCheck bool `json:"spoof_check"`
+	// Trust is true if the VF is trusted, i.e. allowed to change its MAC
+	// address and to enable promiscuous mode.
+	Trust bool `json:"trust"`
+	// LinkState is the administrative link state of the VF, "auto" when it
+	// follows the link of the physical function, "enable" or "disable".
+	LinkState string `json:"link_state,omitempty"`
+}
+
+// NICSRIOV describes the SR-IOV configuration of a NIC which is an SR-IOV
+// physical function (PF). The settings of the virtual functions are only
+// known when ghw can query the kernel, i.e. not from a snapshot.
+type NICSRIOV struct {
+	// TotalVFs is the maximum number of virtual functions the NIC supports.
+	TotalVFs int `json:"total_vfs"`
+	// NumVFs is the number of virtual functions currently enabled.
+	NumVFs int `json:"num_vfs"`
+	// EswitchMode is the mode of the embedded switch of the NIC, "legacy"
+	// or "switchdev", or is empty if the NIC does not report it.
+	EswitchMode string `json:"eswitch_mode,omitempty"`
+	// VFs is a slice of pointers to `NICVirtualFunction` structs, one for
+	// each enabled virtual function, ordered by index.
+	VFs []*NICVirtualFunction `json:"vfs"`
+}
+
 // NIC contains information about a single Network Interface Controller (NIC).
 type NIC struct {
 	// Name is the string identifier the system gave this NIC.
@@ -66,6 +199,75 @@ type NIC struct {
 	// (during auto-negotiation) Forward Error Correction (FEC) modes for this
 	// NIC.
 	AdvertisedFECModes []string `json:"advertised_fec_modes,omitempty"`
+	// MTU is the Maximum Transmission Unit of this NIC, in bytes.
+	MTU int `json:"mtu"`
+	// OperState is the RFC 2863 operational state of this NIC, e.g. "up",
+	// "down" or "lowerlayerdown".
+	OperState string `json:"operstate"`
+	// HasCarrier is true if the physical link of this NIC is up. The carrier
+	// of a NIC which is administratively down is unknown and reported as
+	// false.
+	HasCarrier bool `json:"carrier"`
+	// IfIndex is the interface index the kernel gave this NIC.
+	IfIndex int `json:"ifindex"`
+	// Master is the name of the bond, bridge, team or VRF this NIC is
+	// enslaved to, if any.
+	Master string `json:"master,omitempty"`
+	// Slaves is a slice of the names of the NICs enslaved to this NIC, when
+	// this NIC is a bond, bridge, team or VRF.
+	Slaves []string `json:"slaves,omitempty"`
+	// Addresses is a slice of pointers to `NICAddress` structs, one for each
+	// IPv4 and IPv6 address assigned to this NIC. On the live system the IPv4
+	// addresses are queried from the kernel; in a snapshot they are matched
+	// to the NICs through their directly connected routes, which leaves out
+	// the addresses lacking such a route, e.g. /32 addresses. Snapshots only
+	// hold the addresses and routes when taken with the
+	// WithSnapshotNetAddressing option.
+	Addresses []*NICAddress `json:"addresses,omitempty"`
+	// IPv4DefaultRoute is true if the IPv4 default route goes through this
+	// NIC.
+	IPv4DefaultRoute bool `json:"ipv4_default_route"`
+	// IPv6DefaultRoute is true if the IPv6 default route goes through this
+	// NIC.
+	IPv6DefaultRoute bool `json:"ipv6_default_route"`
+	// SRIOV is a pointer to a `NICSRIOV` struct describing the SR-IOV
+	// virtual functions of this NIC, when this NIC is an SR-IOV physical
+	// function (PF), or nil otherwise.
+	SRIOV *NICSRIOV `json:"sriov,omitempty"`
+	// PhysFnPCIAddress is the PCI address of the physical function of this
+	// NIC, when this NIC is an SR-IOV virtual function (VF).
+	PhysFnPCIAddress string `json:"physfn_pci_address,omitempty"`
+	// PhysFn is the name of the NIC of the physical function of this NIC,
+	// when this NIC is an SR-IOV VF and its PF has a NIC on the host.
+	PhysFn string `json:"physfn,omitempty"`
+	// Kind is the kind of this NIC when this NIC is a virtual NIC, e.g.
+	// "bond", "bridge" or "vlan", or is empty for physical NICs and virtual
+	// NICs of unknown kind.
+	Kind NICKind `json:"kind,omitempty"`
+	// Parent is the name of the NIC this NIC is stacked on, when this NIC
+	// is a VLAN, MACVLAN or IPVLAN NIC.
+	Parent string `json:"parent,omitempty"`
+	// PeerIfIndex is the interface index of the other end of this NIC, when
+	// this NIC is a veth NIC. The peer may live in another network
+	// namespace, e.g. the one of a container.
+	PeerIfIndex int `json:"peer_ifindex,omitempty"`
+	// Peer is the name of the other end of this NIC, when this NIC is a veth
+	// NIC whose peer lives in the same network namespace.
+	Peer string `json:"peer,omitempty"`
+	// Bond is a pointer to a `NICBond` struct describing the bonding
+	// settings of this NIC, when this NIC is a bond. The NICs enslaved to the
+	// bond are listed in Slaves.
+	Bond *NICBond `json:"bond,omitempty"`
+	// Bridge is a pointer to a `NICBridge` struct describing the settings of
+	// this NIC, when this NIC is a bridge. The ports of the bridge are listed
+	// in Slaves.
+	Bridge *NICBridge `json:"bridge,omitempty"`
+	// VLAN is a pointer to a `NICVLAN` struct describing the VLAN of this
+	// NIC, when this NIC is a VLAN NIC.
+	VLAN *NICVLAN `json:"vlan,omitempty"`
+	// VXLAN is a pointer to a `NICVXLAN` struct describing the VXLAN
+	// settings of this NIC, when this NIC is a VXLAN NIC.
+	VXLAN *NICVXLAN `json:"vxlan,omitempty"`
 	// TODO(fromani): add other hw addresses (USB) when we support them
 }
 
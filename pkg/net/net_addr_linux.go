@@ -0,0 +1,346 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/bits"
+	gonet "net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+const (
+	// RTF_REJECT, set on the unreachable routes of the IPv6 routing table
+	rtfReject = 0x0200
+)
+
+// ipv6Scopes maps the scope of the addresses in /proc/net/if_inet6, masked
+// with IPV6_ADDR_SCOPE_MASK, to their name
+var ipv6Scopes = map[uint64]string{
+	0x00: "global",
+	0x10: "host",
+	0x20: "link",
+	0x40: "site",
+}
+
+// ipv4Route is a route of /proc/net/route
+type ipv4Route struct {
+	iface   string
+	dest    uint32
+	gateway uint32
+	mask    uint32
+}
+
+// parseIPv4Hex parses an IPv4 address of /proc/net/route, which the kernel
+// prints as a 32-bit integer in host byte order, e.g. 0100A8C0 for
+// 192.168.0.1 on little-endian hosts
+func parseIPv4Hex(s string) (uint32, bool) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	b := make([]byte, 4)
+	binary.NativeEndian.PutUint32(b, uint32(v))
+	return binary.BigEndian.Uint32(b), true
+}
+
+// parseIPv4Routes parses the routes of the main routing table in the format of
+// /proc/net/route
+func parseIPv4Routes(r io.Reader) []ipv4Route {
+	routes := []ipv4Route{}
+	scanner := bufio.NewScanner(r)
+	// Skip the header line
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		dest, ok1 := parseIPv4Hex(fields[1])
+		gateway, ok2 := parseIPv4Hex(fields[2])
+		mask, ok3 := parseIPv4Hex(fields[7])
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+		routes = append(routes, ipv4Route{iface: fields[0], dest: dest, gateway: gateway, mask: mask})
+	}
+	return routes
+}
+
+// parseFibTrieLocal returns the local IPv4 addresses of the host listed in a
+// /proc/net/fib_trie file, which look like the following:
+//
+//	+-- 192.168.0.0/24 2 0 2
+//	   |-- 192.168.0.0
+//	      /24 link UNICAST
+//	   |-- 192.168.0.12
+//	      /32 host LOCAL
+func parseFibTrieLocal(r io.Reader) []uint32 {
+	addrs := []uint32{}
+	seen := map[uint32]bool{}
+	var last gonet.IP
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "|-- ") {
+			last = gonet.ParseIP(strings.TrimPrefix(line, "|-- ")).To4()
+			continue
+		}
+		if last == nil || !strings.HasPrefix(line, "/32 host LOCAL") {
+			continue
+		}
+		addr := binary.BigEndian.Uint32(last)
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+func ipv4String(addr uint32) string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, addr)
+	return gonet.IP(b).String()
+}
+
+// ipv4Addresses returns the IPv4 addresses of the host by interface name,
+// for the procfs trees of a chroot or of a snapshot taken with the
+// WithSnapshotNetAddressing option, which lack the interfaces of the
+// addresses. The kernel exposes the local addresses, in
+// /proc/net/fib_trie, apart from the interfaces carrying them, so each
+// address is assigned to the interface of the most specific directly
+// connected route of /proc/net/route containing it. Addresses without such a
+// route, e.g. the /32 addresses some clouds assign, are left out.
+func ipv4Addresses(local []uint32, routes []ipv4Route) map[string][]*NICAddress {
+	out := map[string][]*NICAddress{}
+	for _, addr := range local {
+		var best *ipv4Route
+		for x := range routes {
+			r := &routes[x]
+			if r.gateway != 0 || r.mask == 0 || addr&r.mask != r.dest {
+				continue
+			}
+			if best == nil || bits.OnesCount32(r.mask) > bits.OnesCount32(best.mask) {
+				best = r
+			}
+		}
+		if best == nil {
+			continue
+		}
+		out[best.iface] = append(out[best.iface], &NICAddress{
+			IP:           ipv4String(addr),
+			PrefixLength: bits.OnesCount32(best.mask),
+		})
+	}
+	return out
+}
+
+// parseIfAddrMsg parses the payload of a RTM_NEWADDR message, a struct
+// ifaddrmsg followed by its attributes, returning the index of the interface
+// carrying the address along with the address
+func parseIfAddrMsg(b []byte) (int, *NICAddress, error) {
+	if len(b) < syscall.SizeofIfAddrmsg {
+		return 0, nil, fmt.Errorf("short ifaddrmsg")
+	}
+	attrs, err := parseNLAttrs(b[syscall.SizeofIfAddrmsg:])
+	if err != nil {
+		return 0, nil, err
+	}
+	var local, address []byte
+	for _, a := range attrs {
+		switch a.typ {
+		case syscall.IFA_LOCAL:
+			local = a.data
+		case syscall.IFA_ADDRESS:
+			address = a.data
+		}
+	}
+	// IFA_ADDRESS is the address of the peer on point-to-point interfaces,
+	// whose own address is IFA_LOCAL
+	ip := local
+	if ip == nil {
+		ip = address
+	}
+	if len(ip) != gonet.IPv4len && len(ip) != gonet.IPv6len {
+		return 0, nil, fmt.Errorf("unexpected address length %d", len(ip))
+	}
+	a := &NICAddress{
+		IP:           gonet.IP(ip).String(),
+		PrefixLength: int(b[1]),
+	}
+	return int(binary.NativeEndian.Uint32(b[4:8])), a, nil
+}
+
+// ipv4Addresses returns the IPv4 addresses of the host by interface index,
+// as the kernel reports them to a RTM_GETADDR dump request
+func (s *linkNetlink) ipv4Addresses() (map[int][]*NICAddress, error) {
+	if s.rtnl == nil {
+		return nil, fmt.Errorf("rtnetlink unavailable")
+	}
+	payload := make([]byte, syscall.SizeofIfAddrmsg)
+	payload[0] = syscall.AF_INET
+	replies, err := s.rtnl.dump(syscall.RTM_GETADDR, payload)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int][]*NICAddress{}
+	for _, reply := range replies {
+		index, a, err := parseIfAddrMsg(reply)
+		if err != nil {
+			return nil, err
+		}
+		out[index] = append(out[index], a)
+	}
+	return out, nil
+}
+
+// ifaceNames returns the names of the NICs of the sysfs tree by interface
+// index
+func ifaceNames(paths *linuxpath.Paths) map[int]string {
+	names := map[int]string{}
+	files, err := os.ReadDir(paths.SysClassNet)
+	if err != nil {
+		return names
+	}
+	for _, file := range files {
+		index, err := strconv.Atoi(readFile(filepath.Join(paths.SysClassNet, file.Name(), "ifindex")))
+		if err == nil {
+			names[index] = file.Name()
+		}
+	}
+	return names
+}
+
+// parseIfInet6 returns the IPv6 addresses by interface name listed in a
+// /proc/net/if_inet6 file, whose lines contain the address, the interface
+// index, the prefix length, the scope and the flags, all in hexadecimal, and
+// the interface name, e.g.
+//
+//	fe80000000000000021122fffe334455 02 40 20 80     eth0
+func parseIfInet6(r io.Reader) map[string][]*NICAddress {
+	out := map[string][]*NICAddress{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		b, err := hex.DecodeString(fields[0])
+		if err != nil || len(b) != gonet.IPv6len {
+			continue
+		}
+		prefix, err := strconv.ParseUint(fields[2], 16, 8)
+		if err != nil {
+			continue
+		}
+		scope, _ := strconv.ParseUint(fields[3], 16, 32)
+		a := &NICAddress{
+			IP:           gonet.IP(b).String(),
+			PrefixLength: int(prefix),
+			Scope:        ipv6Scopes[scope&0xf0],
+		}
+		out[fields[5]] = append(out[fields[5]], a)
+	}
+	return out
+}
+
+// parseIPv6DefaultRoutes returns the names of the interfaces carrying an IPv6
+// default route in a /proc/net/ipv6_route file, leaving out the unreachable
+// default routes the kernel adds on the loopback interface
+func parseIPv6DefaultRoutes(r io.Reader) map[string]bool {
+	out := map[string]bool{}
+	zero := strings.Repeat("0", 32)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[0] != zero || fields[1] != "00" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil || flags&rtfReject != 0 {
+			continue
+		}
+		out[fields[9]] = true
+	}
+	return out
+}
+
+// netAddressing is the addressing and routing information of the host, by
+// interface name
+type netAddressing struct {
+	addresses   map[string][]*NICAddress
+	ipv4Default map[string]bool
+	ipv6Default map[string]bool
+}
+
+// addressing reads the IP addresses and the default routes of the host. The
+// IPv4 addresses are queried from the kernel when links is not nil, i.e.
+// when examining the live system, and guessed from its routes otherwise.
+func addressing(ctx context.Context, paths *linuxpath.Paths, links *linkNetlink) *netAddressing {
+	a := &netAddressing{
+		addresses:   map[string][]*NICAddress{},
+		ipv4Default: map[string]bool{},
+		ipv6Default: map[string]bool{},
+	}
+	var routes []ipv4Route
+	if f, err := os.Open(paths.ProcNetRoute); err == nil {
+		routes = parseIPv4Routes(f)
+		util.SafeClose(f)
+	}
+	for _, r := range routes {
+		if r.dest == 0 && r.mask == 0 {
+			a.ipv4Default[r.iface] = true
+		}
+	}
+	native := false
+	if links != nil {
+		byIndex, err := links.ipv4Addresses()
+		if err != nil {
+			log.Debug(ctx, "cannot query the IPv4 addresses of the NICs: %s", err)
+		} else {
+			native = true
+			names := ifaceNames(paths)
+			for index, addrs := range byIndex {
+				if iface, ok := names[index]; ok {
+					a.addresses[iface] = append(a.addresses[iface], addrs...)
+				}
+			}
+		}
+	}
+	if !native {
+		if f, err := os.Open(paths.ProcNetFibTrie); err == nil {
+			for iface, addrs := range ipv4Addresses(parseFibTrieLocal(f), routes) {
+				a.addresses[iface] = append(a.addresses[iface], addrs...)
+			}
+			util.SafeClose(f)
+		}
+	}
+	if f, err := os.Open(paths.ProcNetIfInet6); err == nil {
+		for iface, addrs := range parseIfInet6(f) {
+			a.addresses[iface] = append(a.addresses[iface], addrs...)
+		}
+		util.SafeClose(f)
+	}
+	if f, err := os.Open(paths.ProcNetIPv6Route); err == nil {
+		a.ipv6Default = parseIPv6DefaultRoutes(f)
+		util.SafeClose(f)
+	}
+	return a
+}
@@ -0,0 +1,721 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// The native ethtool backend queries the kernel directly, through the ethtool
+// generic netlink family (Linux 5.6+) or, on older kernels, through the
+// SIOCETHTOOL ioctl. See include/uapi/linux/ethtool_netlink.h and
+// include/uapi/linux/ethtool.h for the layouts used below.
+
+const (
+	ethtoolGenlName        = "ethtool"
+	ethtoolGenlVersion     = 1
+	ethtoolMsgStrsetGet    = 1
+	ethtoolMsgLinkmodesGet = 4
+	ethtoolMsgFeaturesGet  = 11
+
+	// attributes shared by all requests
+	ethtoolAHeader        = 1
+	ethtoolAHeaderDevName = 2
+
+	ethtoolAStrsetStringsets  = 2
+	ethtoolAStringsetsSet     = 1
+	ethtoolAStringsetID       = 1
+	ethtoolAStringsetStrings  = 3
+	ethtoolAStringsString     = 1
+	ethtoolAStringIndex       = 1
+	ethtoolAStringValue       = 2
+	ethtoolALinkmodesAutoneg  = 2
+	ethtoolALinkmodesOurs     = 3
+	ethtoolALinkmodesSpeed    = 5
+	ethtoolALinkmodesDuplex   = 6
+	ethtoolAFeaturesHW        = 2
+	ethtoolAFeaturesActive    = 4
+	ethtoolAFeaturesNochange  = 5
+	ethtoolABitsetNomask      = 1
+	ethtoolABitsetBits        = 3
+	ethtoolABitsetBitsBit     = 1
+	ethtoolABitsetBitIndex    = 1
+	ethtoolABitsetBitName     = 2
+	ethtoolABitsetBitValue    = 3
+	ethtoolStringSetFeatures  = 4
+	ethtoolStringSetLinkModes = 9
+
+	siocEthtool          = 0x8946
+	ethtoolGStrings      = 0x1b
+	ethtoolGSSetInfo     = 0x37
+	ethtoolGFeatures     = 0x3a
+	ethtoolGLinkSettings = 0x4c
+	ethtoolGStringLen    = 32
+	// the length of struct ethtool_link_settings
+	ethtoolLinkSettingsLen = 48
+
+	ethtoolSpeedUnknown  = 0xffffffff
+	ethtoolDuplexHalf    = 0
+	ethtoolDuplexFull    = 1
+	ethtoolDuplexUnknown = 0xff
+)
+
+// ethtoolFeature is a feature of a NIC, named as the kernel names it, e.g.
+// `tx-checksum-ipv4`
+type ethtoolFeature struct {
+	name   string
+	active bool
+	fixed  bool
+}
+
+// ethtoolLinkModes is the link state and the link modes of a NIC, named as
+// the kernel names them, e.g. `1000baseT/Full`, `Autoneg` or `FEC_RS`
+type ethtoolLinkModes struct {
+	speed      uint32
+	duplex     uint8
+	autoneg    bool
+	supported  []string
+	advertised []string
+}
+
+// ethtoolFeatureGroups are the groups of features `ethtool -k` shows under a
+// legacy name, e.g. the checksum offloads of the transmit path as
+// `tx-checksumming`. The native backend reports the same capabilities.
+var ethtoolFeatureGroups = []struct {
+	name     string
+	features []string
+}{
+	{"rx-checksumming", []string{"rx-checksum"}},
+	{"tx-checksumming", []string{
+		"tx-checksum-ipv4", "tx-checksum-ip-generic", "tx-checksum-ipv6",
+		"tx-checksum-fcoe-crc", "tx-checksum-sctp",
+	}},
+	{"scatter-gather", []string{"tx-scatter-gather", "tx-scatter-gather-fraglist"}},
+	{"tcp-segmentation-offload", []string{
+		"tx-tcp-segmentation", "tx-tcp-ecn-segmentation",
+		"tx-tcp-mangleid-segmentation", "tx-tcp6-segmentation",
+	}},
+	{"udp-fragmentation-offload", []string{"tx-udp-fragmentation"}},
+	{"generic-segmentation-offload", []string{"tx-generic-segmentation"}},
+	{"generic-receive-offload", []string{"rx-gro"}},
+	{"large-receive-offload", []string{"rx-lro"}},
+	{"rx-vlan-offload", []string{"rx-vlan-hw-parse"}},
+	{"tx-vlan-offload", []string{"tx-vlan-hw-insert"}},
+	{"ntuple-filters", []string{"rx-ntuple-filter"}},
+	{"receive-hashing", []string{"rx-hashing"}},
+}
+
+// ethtoolCapabilities returns the capabilities of a NIC with the supplied
+// features, as `ethtool -k` shows them: the features of a legacy group are
+// shown under the name of the group, which is enabled if any of them is
+// active and is fixed if all of them are fixed, followed by the features
+// themselves when the group has several.
+func ethtoolCapabilities(features []ethtoolFeature) []*NICCapability {
+	byName := map[string]ethtoolFeature{}
+	for _, f := range features {
+		byName[f.name] = f
+	}
+	caps := []*NICCapability{}
+	grouped := map[string]bool{}
+	for _, g := range ethtoolFeatureGroups {
+		members := []ethtoolFeature{}
+		for _, name := range g.features {
+			if f, ok := byName[name]; ok {
+				members = append(members, f)
+				grouped[name] = true
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		group := &NICCapability{Name: g.name}
+		for _, f := range members {
+			group.IsEnabled = group.IsEnabled || f.active
+			group.CanEnable = group.CanEnable || !f.fixed
+		}
+		caps = append(caps, group)
+		if len(members) > 1 {
+			for _, f := range members {
+				caps = append(caps, &NICCapability{Name: f.name, IsEnabled: f.active, CanEnable: !f.fixed})
+			}
+		}
+	}
+	for _, f := range features {
+		if !grouped[f.name] {
+			caps = append(caps, &NICCapability{Name: f.name, IsEnabled: f.active, CanEnable: !f.fixed})
+		}
+	}
+	return caps
+}
+
+// ethtoolPorts are the link mode bits telling the physical ports of a NIC
+var ethtoolPorts = map[string]bool{
+	"TP": true, "AUI": true, "MII": true, "FIBRE": true, "BNC": true, "Backplane": true,
+}
+
+// splitLinkModes splits the supplied link mode bits into the link modes
+// proper, the ports and the FEC modes, leaving out the autonegotiation and
+// pause bits, the way `ethtool` shows them
+func splitLinkModes(bits []string) (modes []string, ports []string, fec []string) {
+	for _, bit := range bits {
+		switch {
+		case bit == "Autoneg" || bit == "Pause" || bit == "Asym_Pause":
+		case ethtoolPorts[bit]:
+			ports = append(ports, bit)
+		case bit == "FEC_NONE":
+			fec = append(fec, "None")
+		case strings.HasPrefix(bit, "FEC_"):
+			fec = append(fec, strings.TrimPrefix(bit, "FEC_"))
+		default:
+			modes = append(modes, bit)
+		}
+	}
+	return modes, ports, fec
+}
+
+// setEthtoolLinkModes sets the link fields and the auto-negotiation and
+// pause-frame-use capabilities of the NIC from its link modes
+func (n *NIC) setEthtoolLinkModes(lm *ethtoolLinkModes) {
+	has := func(bits []string, names ...string) bool {
+		for _, bit := range bits {
+			for _, name := range names {
+				if bit == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	n.Capabilities = append(n.Capabilities,
+		&NICCapability{
+			Name:      "auto-negotiation",
+			IsEnabled: lm.autoneg && has(lm.advertised, "Autoneg"),
+			CanEnable: has(lm.supported, "Autoneg"),
+		},
+		&NICCapability{
+			Name:      "pause-frame-use",
+			IsEnabled: has(lm.advertised, "Pause", "Asym_Pause"),
+			CanEnable: has(lm.supported, "Pause", "Asym_Pause"),
+		},
+	)
+
+	n.Speed = "Unknown!"
+	if lm.speed != ethtoolSpeedUnknown && lm.speed != 0 {
+		n.Speed = fmt.Sprintf("%dMb/s", lm.speed)
+	}
+	switch lm.duplex {
+	case ethtoolDuplexHalf:
+		n.Duplex = "Half"
+	case ethtoolDuplexFull:
+		n.Duplex = "Full"
+	default:
+		n.Duplex = "Unknown!"
+	}
+	n.SupportedLinkModes, n.SupportedPorts, n.SupportedFECModes = splitLinkModes(lm.supported)
+	n.AdvertisedLinkModes, _, n.AdvertisedFECModes = splitLinkModes(lm.advertised)
+}
+
+// ethtoolHeader returns the request header attribute for the supplied device
+func ethtoolHeader(dev string) []byte {
+	return appendNLAttr(nil, ethtoolAHeader|nlaFNested, appendNLAttr(nil, ethtoolAHeaderDevName, nlString(dev)))
+}
+
+// ethtoolBit is a bit of an ethtool bitset in its verbose form
+type ethtoolBit struct {
+	index uint32
+	name  string
+	// set is true if the bit is set in the value of the bitset
+	set bool
+}
+
+// parseEthtoolBitset parses the bits of a bitset in the verbose form, which
+// lists the bits set in the mask of the bitset, or the bits set in its value
+// when the bitset has no mask
+func parseEthtoolBitset(b []byte) ([]ethtoolBit, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	nomask := false
+	var bits []ethtoolBit
+	for _, a := range attrs {
+		switch a.typ {
+		case ethtoolABitsetNomask:
+			nomask = true
+		case ethtoolABitsetBits:
+			entries, err := parseNLAttrs(a.data)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if entry.typ != ethtoolABitsetBitsBit {
+					continue
+				}
+				fields, err := parseNLAttrs(entry.data)
+				if err != nil {
+					return nil, err
+				}
+				bit := ethtoolBit{}
+				for _, f := range fields {
+					switch f.typ {
+					case ethtoolABitsetBitIndex:
+						if len(f.data) >= 4 {
+							bit.index = binary.NativeEndian.Uint32(f.data)
+						}
+					case ethtoolABitsetBitName:
+						bit.name = nlAttrString(f.data)
+					case ethtoolABitsetBitValue:
+						bit.set = true
+					}
+				}
+				bits = append(bits, bit)
+			}
+		}
+	}
+	if nomask {
+		for x := range bits {
+			bits[x].set = true
+		}
+	}
+	return bits, nil
+}
+
+// parseEthtoolStrings parses the strings of the first string set of a
+// ETHTOOL_MSG_STRSET_GET reply, indexed by their position
+func parseEthtoolStrings(b []byte) ([]string, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	strs := []string{}
+	for _, a := range attrs {
+		if a.typ != ethtoolAStrsetStringsets {
+			continue
+		}
+		sets, err := parseNLAttrs(a.data)
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range sets {
+			fields, err := parseNLAttrs(set.data)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range fields {
+				if f.typ != ethtoolAStringsetStrings {
+					continue
+				}
+				entries, err := parseNLAttrs(f.data)
+				if err != nil {
+					return nil, err
+				}
+				for _, entry := range entries {
+					if entry.typ != ethtoolAStringsString {
+						continue
+					}
+					kv, err := parseNLAttrs(entry.data)
+					if err != nil {
+						return nil, err
+					}
+					var index uint32
+					var value string
+					for _, e := range kv {
+						switch e.typ {
+						case ethtoolAStringIndex:
+							if len(e.data) >= 4 {
+								index = binary.NativeEndian.Uint32(e.data)
+							}
+						case ethtoolAStringValue:
+							value = nlAttrString(e.data)
+						}
+					}
+					for uint32(len(strs)) <= index {
+						strs = append(strs, "")
+					}
+					strs[index] = value
+				}
+			}
+			return strs, nil
+		}
+	}
+	return strs, nil
+}
+
+// parseEthtoolFeatures parses a ETHTOOL_MSG_FEATURES_GET reply. A feature is
+// fixed if it is not in the changeable (hw) set or is in the nochange set.
+// The features are returned in the order of names, the names of the features
+// string set, followed by any feature the reply names but names lacks.
+func parseEthtoolFeatures(b []byte, names []string) ([]ethtoolFeature, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	sets := map[uint16]map[string]bool{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, a := range attrs {
+		switch a.typ {
+		case ethtoolAFeaturesHW, ethtoolAFeaturesActive, ethtoolAFeaturesNochange:
+			bits, err := parseEthtoolBitset(a.data)
+			if err != nil {
+				return nil, err
+			}
+			sets[a.typ] = map[string]bool{}
+			for _, bit := range bits {
+				sets[a.typ][bit.name] = bit.set
+				if !seen[bit.name] {
+					names = append(names, bit.name)
+					seen[bit.name] = true
+				}
+			}
+		}
+	}
+	features := []ethtoolFeature{}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		features = append(features, ethtoolFeature{
+			name:   name,
+			active: sets[ethtoolAFeaturesActive][name],
+			fixed:  !sets[ethtoolAFeaturesHW][name] || sets[ethtoolAFeaturesNochange][name],
+		})
+	}
+	return features, nil
+}
+
+// parseEthtoolLinkModes parses a ETHTOOL_MSG_LINKMODES_GET reply, whose
+// bitset of our link modes has the supported modes as mask and the
+// advertised ones as value
+func parseEthtoolLinkModes(b []byte) (*ethtoolLinkModes, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	lm := &ethtoolLinkModes{speed: ethtoolSpeedUnknown, duplex: ethtoolDuplexUnknown}
+	for _, a := range attrs {
+		switch a.typ {
+		case ethtoolALinkmodesAutoneg:
+			lm.autoneg = len(a.data) > 0 && a.data[0] != 0
+		case ethtoolALinkmodesSpeed:
+			if len(a.data) >= 4 {
+				lm.speed = binary.NativeEndian.Uint32(a.data)
+			}
+		case ethtoolALinkmodesDuplex:
+			if len(a.data) > 0 {
+				lm.duplex = a.data[0]
+			}
+		case ethtoolALinkmodesOurs:
+			bits, err := parseEthtoolBitset(a.data)
+			if err != nil {
+				return nil, err
+			}
+			for _, bit := range bits {
+				lm.supported = append(lm.supported, bit.name)
+				if bit.set {
+					lm.advertised = append(lm.advertised, bit.name)
+				}
+			}
+		}
+	}
+	return lm, nil
+}
+
+// ethtoolNetlink is a connection to the ethtool generic netlink family
+type ethtoolNetlink struct {
+	*genlSocket
+}
+
+// newEthtoolNetlink resolves the ethtool generic netlink family, which fails
+// on kernels older than 5.6
+func newEthtoolNetlink() (*ethtoolNetlink, error) {
+	s, err := newGenlSocket(ethtoolGenlName, ethtoolGenlVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &ethtoolNetlink{s}, nil
+}
+
+func (c *ethtoolNetlink) close() {
+	if c != nil {
+		c.genlSocket.close()
+	}
+}
+
+// stringSet returns the strings of a global string set, e.g. the names of
+// the features
+func (c *ethtoolNetlink) stringSet(id uint32) ([]string, error) {
+	set := appendNLAttr(nil, ethtoolAStringsetID, nlUint32(id))
+	sets := appendNLAttr(nil, ethtoolAStringsetsSet|nlaFNested, set)
+	reply, err := c.request(ethtoolMsgStrsetGet, appendNLAttr(nil, ethtoolAStrsetStringsets|nlaFNested, sets))
+	if err != nil {
+		return nil, err
+	}
+	return parseEthtoolStrings(reply)
+}
+
+func (c *ethtoolNetlink) features(dev string, names []string) ([]ethtoolFeature, error) {
+	reply, err := c.request(ethtoolMsgFeaturesGet, ethtoolHeader(dev))
+	if err != nil {
+		return nil, err
+	}
+	return parseEthtoolFeatures(reply, names)
+}
+
+func (c *ethtoolNetlink) linkModes(dev string) (*ethtoolLinkModes, error) {
+	reply, err := c.request(ethtoolMsgLinkmodesGet, ethtoolHeader(dev))
+	if err != nil {
+		return nil, err
+	}
+	return parseEthtoolLinkModes(reply)
+}
+
+// ethtoolIoctl issues SIOCETHTOOL requests, the interface ethtool used before
+// the netlink one
+type ethtoolIoctl struct {
+	fd int
+}
+
+func newEthtoolIoctl() (*ethtoolIoctl, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &ethtoolIoctl{fd: fd}, nil
+}
+
+func (c *ethtoolIoctl) close() {
+	if c != nil {
+		syscall.Close(c.fd)
+	}
+}
+
+// ifreq mirrors struct ifreq of net/if.h, with the ifr_data member
+type ifreq struct {
+	name [syscall.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// do issues the ethtool command whose structure is in data, the command
+// number being its first 32 bits
+func (c *ethtoolIoctl) do(dev string, data []byte) error {
+	if len(dev) >= syscall.IFNAMSIZ {
+		return fmt.Errorf("interface name %q too long", dev)
+	}
+	req := ifreq{data: unsafe.Pointer(&data[0])}
+	copy(req.name[:], dev)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(c.fd), siocEthtool, uintptr(unsafe.Pointer(&req)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// stringSet returns the strings of a string set of the device
+func (c *ethtoolIoctl) stringSet(dev string, id uint32) ([]string, error) {
+	// struct ethtool_sset_info, with room for one count
+	info := make([]byte, 20)
+	binary.NativeEndian.PutUint32(info[0:4], ethtoolGSSetInfo)
+	binary.NativeEndian.PutUint64(info[8:16], 1<<id)
+	if err := c.do(dev, info); err != nil {
+		return nil, err
+	}
+	if binary.NativeEndian.Uint64(info[8:16])&(1<<id) == 0 {
+		return nil, fmt.Errorf("string set %d not supported", id)
+	}
+	count := binary.NativeEndian.Uint32(info[16:20])
+
+	// struct ethtool_gstrings
+	gstrings := make([]byte, 12+count*ethtoolGStringLen)
+	binary.NativeEndian.PutUint32(gstrings[0:4], ethtoolGStrings)
+	binary.NativeEndian.PutUint32(gstrings[4:8], id)
+	binary.NativeEndian.PutUint32(gstrings[8:12], count)
+	if err := c.do(dev, gstrings); err != nil {
+		return nil, err
+	}
+	strs := make([]string, count)
+	for x := range strs {
+		s := gstrings[12+x*ethtoolGStringLen : 12+(x+1)*ethtoolGStringLen]
+		strs[x] = nlAttrString(s)
+	}
+	return strs, nil
+}
+
+func (c *ethtoolIoctl) features(dev string) ([]ethtoolFeature, error) {
+	names, err := c.stringSet(dev, ethtoolStringSetFeatures)
+	if err != nil {
+		return nil, err
+	}
+	// struct ethtool_gfeatures, followed by the available, requested,
+	// active and never_changed words of each block of 32 features
+	blocks := (len(names) + 31) / 32
+	gfeatures := make([]byte, 8+blocks*16)
+	binary.NativeEndian.PutUint32(gfeatures[0:4], ethtoolGFeatures)
+	binary.NativeEndian.PutUint32(gfeatures[4:8], uint32(blocks))
+	if err := c.do(dev, gfeatures); err != nil {
+		return nil, err
+	}
+	word := func(block int, field int) uint32 {
+		off := 8 + block*16 + field*4
+		return binary.NativeEndian.Uint32(gfeatures[off : off+4])
+	}
+	features := []ethtoolFeature{}
+	for x, name := range names {
+		bit := uint32(1) << (x % 32)
+		available := word(x/32, 0)&bit != 0
+		neverChanged := word(x/32, 3)&bit != 0
+		features = append(features, ethtoolFeature{
+			name:   name,
+			active: word(x/32, 2)&bit != 0,
+			fixed:  !available || neverChanged,
+		})
+	}
+	return features, nil
+}
+
+func (c *ethtoolIoctl) linkModes(dev string) (*ethtoolLinkModes, error) {
+	// struct ethtool_link_settings is followed by the supported,
+	// advertising and lp_advertising masks. The first request tells the
+	// number of 32-bit words of each mask, negated.
+	settings := make([]byte, ethtoolLinkSettingsLen)
+	binary.NativeEndian.PutUint32(settings[0:4], ethtoolGLinkSettings)
+	if err := c.do(dev, settings); err != nil {
+		return nil, err
+	}
+	nwords := -int(int8(settings[15]))
+	if nwords <= 0 {
+		return nil, fmt.Errorf("unexpected link mode mask size %d", nwords)
+	}
+	settings = make([]byte, ethtoolLinkSettingsLen+3*nwords*4)
+	binary.NativeEndian.PutUint32(settings[0:4], ethtoolGLinkSettings)
+	settings[15] = byte(nwords)
+	if err := c.do(dev, settings); err != nil {
+		return nil, err
+	}
+	names, err := c.stringSet(dev, ethtoolStringSetLinkModes)
+	if err != nil {
+		// kernels predating the link modes string set
+		names = ethtoolLinkModeNames
+	}
+	return parseEthtoolLinkSettings(settings, nwords, names), nil
+}
+
+// parseEthtoolLinkSettings parses a struct ethtool_link_settings followed by
+// its link mode masks of nwords 32-bit words each, naming the bits of the
+// masks after the supplied link mode names
+func parseEthtoolLinkSettings(settings []byte, nwords int, names []string) *ethtoolLinkModes {
+	lm := &ethtoolLinkModes{
+		speed:   binary.NativeEndian.Uint32(settings[4:8]),
+		duplex:  settings[8],
+		autoneg: settings[11] != 0,
+	}
+	isSet := func(mask int, x int) bool {
+		off := ethtoolLinkSettingsLen + (mask*nwords+x/32)*4
+		return binary.NativeEndian.Uint32(settings[off:off+4])&(1<<(x%32)) != 0
+	}
+	for x := 0; x < nwords*32 && x < len(names); x++ {
+		if isSet(0, x) {
+			lm.supported = append(lm.supported, names[x])
+		}
+		if isSet(1, x) {
+			lm.advertised = append(lm.advertised, names[x])
+		}
+	}
+	return lm
+}
+
+// ethtoolLinkModeNames are the names of the link mode bits known to the
+// kernels lacking the link modes string set, i.e. older than 5.6
+var ethtoolLinkModeNames = []string{
+	"10baseT/Half", "10baseT/Full", "100baseT/Half", "100baseT/Full",
+	"1000baseT/Half", "1000baseT/Full", "Autoneg", "TP", "AUI", "MII",
+	"FIBRE", "BNC", "10000baseT/Full", "Pause", "Asym_Pause",
+	"2500baseX/Full", "Backplane", "1000baseKX/Full", "10000baseKX4/Full",
+	"10000baseKR/Full", "10000baseR_FEC", "20000baseMLD2/Full",
+	"20000baseKR2/Full", "40000baseKR4/Full", "40000baseCR4/Full",
+	"40000baseSR4/Full", "40000baseLR4/Full", "56000baseKR4/Full",
+	"56000baseCR4/Full", "56000baseSR4/Full", "56000baseLR4/Full",
+	"25000baseCR/Full", "25000baseKR/Full", "25000baseSR/Full",
+	"50000baseCR2/Full", "50000baseKR2/Full", "100000baseKR4/Full",
+	"100000baseSR4/Full", "100000baseCR4/Full", "100000baseLR4_ER4/Full",
+	"50000baseSR2/Full", "1000baseX/Full", "10000baseCR/Full",
+	"10000baseSR/Full", "10000baseLR/Full", "10000baseLRM/Full",
+	"10000baseER/Full", "2500baseT/Full", "5000baseT/Full", "FEC_NONE",
+	"FEC_RS", "FEC_BASER", "50000baseKR/Full", "50000baseSR/Full",
+	"50000baseCR/Full", "50000baseLR_ER_FR/Full", "50000baseDR/Full",
+	"100000baseKR2/Full", "100000baseSR2/Full", "100000baseCR2/Full",
+	"100000baseLR2_ER2_FR2/Full", "100000baseDR2/Full",
+	"200000baseKR4/Full", "200000baseSR4/Full", "200000baseLR4_ER4_FR4/Full",
+	"200000baseDR4/Full", "200000baseCR4/Full", "100baseT1/Full",
+	"1000baseT1/Full",
+}
+
+// ethtoolNative queries NICs through the ethtool netlink family, or through
+// the SIOCETHTOOL ioctl when the kernel lacks the former
+type ethtoolNative struct {
+	nl       *ethtoolNetlink
+	ioctl    *ethtoolIoctl
+	features []string
+}
+
+// newEthtoolNative returns a native ethtool backend, or an error if neither
+// the netlink family nor the ioctl can be used
+func newEthtoolNative() (*ethtoolNative, error) {
+	e := &ethtoolNative{}
+	nl, nlErr := newEthtoolNetlink()
+	if nlErr == nil {
+		e.nl = nl
+		// names of all the features, including those the device lacks,
+		// which the features reply leaves out
+		e.features, _ = nl.stringSet(ethtoolStringSetFeatures)
+		return e, nil
+	}
+	ioctl, err := newEthtoolIoctl()
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", nlErr, err)
+	}
+	e.ioctl = ioctl
+	return e, nil
+}
+
+func (e *ethtoolNative) close() {
+	e.nl.close()
+	e.ioctl.close()
+}
+
+// fill sets the capabilities and link fields of the NIC. It fails only if
+// the device could not be queried at all; link modes are missing for many
+// virtual devices, whose speed and duplex are then left for sysfs to fill.
+func (e *ethtoolNative) fill(n *NIC, dev string) (bool, error) {
+	var features []ethtoolFeature
+	var lm *ethtoolLinkModes
+	var err, lmErr error
+	if e.nl != nil {
+		features, err = e.nl.features(dev, e.features)
+		lm, lmErr = e.nl.linkModes(dev)
+	} else {
+		features, err = e.ioctl.features(dev)
+		lm, lmErr = e.ioctl.linkModes(dev)
+	}
+	if err != nil {
+		return false, err
+	}
+	n.Capabilities = []*NICCapability{}
+	if lmErr == nil {
+		n.setEthtoolLinkModes(lm)
+	}
+	n.Capabilities = append(n.Capabilities, ethtoolCapabilities(features)...)
+	return lmErr == nil, nil
+}
@@ -0,0 +1,347 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	gonet "net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// The kind of a virtual NIC shows in sysfs as the DEVTYPE of its uevent for
+// most kinds, and as kind-specific attributes for the others. rtnetlink
+// reports the kind of every virtual NIC, along with the kind-specific
+// settings sysfs lacks, in the IFLA_LINKINFO attribute. See
+// include/uapi/linux/if_link.h and include/uapi/linux/if_tun.h.
+
+const (
+	iflaInfoKind     = 1
+	iflaInfoData     = 2
+	iflaVLANID       = 1
+	iflaVLANProtocol = 5
+	iflaVXLANID      = 1
+	iflaVXLANGroup   = 2
+	iflaVXLANLocal   = 4
+	iflaVXLANPort    = 15
+	iflaVXLANGroup6  = 16
+	iflaVXLANLocal6  = 17
+
+	iffTun = 0x0001
+	iffTap = 0x0002
+)
+
+var vlanProtocols = map[uint16]string{
+	0x8100: "802.1Q",
+	0x88a8: "802.1ad",
+}
+
+// linkInfo is the kind of a NIC and its kind-specific attributes, as
+// reported by rtnetlink
+type linkInfo struct {
+	kind string
+	data []nlAttr
+}
+
+// parseLinkInfo returns the kind of the NIC described by the attributes of an
+// RTM_NEWLINK message, or nil if the NIC has no kind, as physical NICs
+func parseLinkInfo(b []byte) (*linkInfo, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		if a.typ != syscall.IFLA_LINKINFO {
+			continue
+		}
+		infoAttrs, err := parseNLAttrs(a.data)
+		if err != nil {
+			return nil, err
+		}
+		li := &linkInfo{}
+		for _, ia := range infoAttrs {
+			switch ia.typ {
+			case iflaInfoKind:
+				li.kind = nlAttrString(ia.data)
+			case iflaInfoData:
+				if li.data, err = parseNLAttrs(ia.data); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if li.kind == "" {
+			return nil, nil
+		}
+		return li, nil
+	}
+	return nil, nil
+}
+
+// linkInfo returns the kind of the supplied NIC and its kind-specific
+// attributes
+func (s *linkNetlink) linkInfo(dev string) (*linkInfo, error) {
+	reply, err := s.getLink(dev, 0)
+	if err != nil {
+		return nil, err
+	}
+	return parseLinkInfo(reply)
+}
+
+// vlan returns the VLAN described by the attributes of a VLAN NIC
+func (li *linkInfo) vlan() *NICVLAN {
+	v := &NICVLAN{}
+	for _, a := range li.data {
+		if len(a.data) < 2 {
+			continue
+		}
+		switch a.typ {
+		case iflaVLANID:
+			v.ID = int(binary.NativeEndian.Uint16(a.data))
+		case iflaVLANProtocol:
+			// a __be16, as the ethertype of the frames
+			v.Protocol = vlanProtocols[binary.BigEndian.Uint16(a.data)]
+		}
+	}
+	return v
+}
+
+// vxlan returns the settings described by the attributes of a VXLAN NIC
+func (li *linkInfo) vxlan() *NICVXLAN {
+	v := &NICVXLAN{}
+	for _, a := range li.data {
+		switch {
+		case a.typ == iflaVXLANID && len(a.data) >= 4:
+			v.VNI = int(binary.NativeEndian.Uint32(a.data))
+		case a.typ == iflaVXLANPort && len(a.data) >= 2:
+			// a __be16, unlike the other integer attributes
+			v.Port = int(binary.BigEndian.Uint16(a.data))
+		case a.typ == iflaVXLANGroup || a.typ == iflaVXLANGroup6:
+			v.Remote = vxlanAddress(a.data)
+		case a.typ == iflaVXLANLocal || a.typ == iflaVXLANLocal6:
+			v.Local = vxlanAddress(a.data)
+		}
+	}
+	return v
+}
+
+// vxlanAddress returns the supplied IPv4 or IPv6 address, or "" for the
+// unspecified address
+func vxlanAddress(b []byte) string {
+	if len(b) != gonet.IPv4len && len(b) != gonet.IPv6len {
+		return ""
+	}
+	ip := gonet.IP(b)
+	if ip.IsUnspecified() {
+		return ""
+	}
+	return ip.String()
+}
+
+// parseProcVLAN returns the VLAN ID and the parent NIC of a VLAN NIC listed
+// in its /proc/net/vlan/$DEVICE file, which looks like the following:
+//
+//	eth0.100  VID: 100	 REORDER_HDR: 1  dev->priv_flags: 1
+//	         total frames received            0
+//	...
+//	Device: eth0
+func parseProcVLAN(r io.Reader) (int, string) {
+	id := -1
+	parent := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for x := 0; x+1 < len(fields); x++ {
+			switch fields[x] {
+			case "VID:":
+				if v, err := strconv.Atoi(fields[x+1]); err == nil {
+					id = v
+				}
+			case "Device:":
+				parent = fields[x+1]
+			}
+		}
+	}
+	return id, parent
+}
+
+// sysfsNICKind returns the kind of a virtual NIC as exposed in sysfs, or ""
+// for the kinds sysfs does not expose, e.g. veth, macvlan, team and dummy
+func sysfsNICKind(netPath string) NICKind {
+	if f, err := os.Open(filepath.Join(netPath, "uevent")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if devType, ok := strings.CutPrefix(scanner.Text(), "DEVTYPE="); ok {
+				_ = f.Close()
+				return NICKind(devType)
+			}
+		}
+		_ = f.Close()
+	}
+	if _, err := os.Stat(filepath.Join(netPath, "bonding")); err == nil {
+		return NICKindBond
+	}
+	if _, err := os.Stat(filepath.Join(netPath, "bridge")); err == nil {
+		return NICKindBridge
+	}
+	if flags, err := strconv.ParseUint(readFile(filepath.Join(netPath, "tun_flags")), 0, 32); err == nil {
+		if flags&iffTap != 0 {
+			return NICKindTap
+		}
+		if flags&iffTun != 0 {
+			return NICKindTun
+		}
+	}
+	return ""
+}
+
+// lowerNICs returns the names of the NICs the NIC at the supplied sysfs path
+// is stacked on, which the kernel links as lower_$DEVICE
+func lowerNICs(netPath string) []string {
+	links, _ := filepath.Glob(filepath.Join(netPath, "lower_*"))
+	names := []string{}
+	for _, link := range links {
+		names = append(names, strings.TrimPrefix(filepath.Base(link), "lower_"))
+	}
+	return names
+}
+
+// firstField returns the first field of the supplied sysfs attribute, e.g.
+// "802.3ad" for "802.3ad 4"
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// nicBond returns the settings of the bond at the supplied sysfs path
+func nicBond(netPath string) *NICBond {
+	bondPath := filepath.Join(netPath, "bonding")
+	b := &NICBond{
+		Mode:        firstField(readFile(filepath.Join(bondPath, "mode"))),
+		ActiveSlave: readFile(filepath.Join(bondPath, "active_slave")),
+	}
+	b.MIIMon, _ = strconv.Atoi(readFile(filepath.Join(bondPath, "miimon")))
+	switch b.Mode {
+	case "802.3ad":
+		b.LACPRate = firstField(readFile(filepath.Join(bondPath, "lacp_rate")))
+		fallthrough
+	case "balance-xor":
+		b.XmitHashPolicy = firstField(readFile(filepath.Join(bondPath, "xmit_hash_policy")))
+	}
+	return b
+}
+
+// nicBridge returns the settings of the bridge at the supplied sysfs path
+func nicBridge(netPath string) *NICBridge {
+	bridgePath := filepath.Join(netPath, "bridge")
+	stp := readFile(filepath.Join(bridgePath, "stp_state"))
+	return &NICBridge{
+		STPEnabled:    stp != "" && stp != "0",
+		VLANFiltering: readFile(filepath.Join(bridgePath, "vlan_filtering")) == "1",
+	}
+}
+
+// setNicKind sets the kind of a virtual NIC and its kind-specific fields,
+// from sysfs and, for the kinds and settings sysfs lacks, from rtnetlink
+// when available. The veth NICs sysfs does not tell the kind of are only
+// recognized once all the NICs are known, by pairNICPeers.
+func (n *NIC) setNicKind(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	links *linkNetlink,
+	dev string,
+) {
+	netPath := filepath.Join(paths.SysClassNet, dev)
+	n.Kind = sysfsNICKind(netPath)
+
+	var li *linkInfo
+	if links != nil {
+		var err error
+		if li, err = links.linkInfo(dev); err != nil {
+			log.Debug(ctx, "could not read the link info of %s: %s", dev, err)
+		}
+	}
+	if n.Kind == "" && li != nil {
+		n.Kind = NICKind(li.kind)
+	}
+	iflink, err := strconv.Atoi(readFile(filepath.Join(netPath, "iflink")))
+	if err != nil {
+		iflink = n.IfIndex
+	}
+
+	switch n.Kind {
+	case NICKindBond:
+		n.Bond = nicBond(netPath)
+	case NICKindBridge:
+		n.Bridge = nicBridge(netPath)
+	case NICKindVLAN:
+		if f, err := os.Open(filepath.Join(paths.ProcNetVLAN, dev)); err == nil {
+			var id int
+			if id, n.Parent = parseProcVLAN(f); id >= 0 {
+				n.VLAN = &NICVLAN{ID: id}
+			}
+			_ = f.Close()
+		}
+		if li != nil {
+			n.VLAN = li.vlan()
+		}
+	case NICKindVXLAN:
+		if li != nil {
+			n.VXLAN = li.vxlan()
+		}
+	case NICKindVeth:
+		n.PeerIfIndex = iflink
+	case "":
+		// a veth NIC has the interface index of its peer as iflink, as a
+		// stacked NIC has the one of its parent. pairNICPeers tells whether
+		// the NIC is a veth NIC.
+		if iflink != n.IfIndex && len(lowerNICs(netPath)) == 0 {
+			n.PeerIfIndex = iflink
+		}
+	}
+	switch n.Kind {
+	case NICKindVLAN, NICKindMACVLAN, NICKindIPVLAN:
+		if lower := lowerNICs(netPath); len(lower) > 0 {
+			n.Parent = lower[0]
+		}
+	}
+}
+
+// pairNICPeers recognizes the veth NICs sysfs does not tell the kind of, as
+// the pairs of virtual NICs having the interface index of each other as
+// iflink, and sets the name of the peer of the veth NICs
+func pairNICPeers(nics []*NIC) {
+	byIndex := map[int]*NIC{}
+	for _, nic := range nics {
+		byIndex[nic.IfIndex] = nic
+	}
+	for _, nic := range nics {
+		if nic.PeerIfIndex == 0 {
+			continue
+		}
+		peer, ok := byIndex[nic.PeerIfIndex]
+		if nic.Kind == "" {
+			if !ok || !peer.IsVirtual || peer.PeerIfIndex != nic.IfIndex {
+				nic.PeerIfIndex = 0
+				continue
+			}
+			nic.Kind = NICKindVeth
+		}
+		if ok && peer.PeerIfIndex == nic.IfIndex {
+			nic.Peer = peer.Name
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/jaypipes/ghw/internal/config"
@@ -39,14 +40,40 @@ func nics(ctx context.Context) []*NIC {
 		return nics
 	}
 
+	// The native ethtool backend queries the running kernel, which only
+	// knows about the NICs of the host when ghw examines the live system
+	// rather than, e.g., a snapshot. The same goes for the SR-IOV settings
+	// and the kinds of the virtual NICs.
+	var native *ethtoolNative
+	var links *linkNetlink
+	if config.Chroot(ctx) == "/" {
+		native, err = newEthtoolNative()
+		if err != nil {
+			log.Debug(ctx, "native ethtool backend unavailable: %s", err)
+			native = nil
+		} else {
+			defer native.close()
+		}
+		links, err = newLinkNetlink()
+		if err != nil {
+			log.Debug(ctx, "cannot query the links of the NICs: %s", err)
+			links = nil
+		} else {
+			defer links.close()
+		}
+	}
+
 	etAvailable := config.ToolsEnabled(ctx)
 	if etAvailable {
 		if etInstalled := ethtoolInstalled(); !etInstalled {
-			log.Warn(ctx, warnEthtoolNotInstalled)
+			if native == nil {
+				log.Warn(ctx, warnEthtoolNotInstalled)
+			}
 			etAvailable = false
 		}
 	}
 
+	addrs := addressing(ctx, paths, links)
 	for _, file := range files {
 		filename := file.Name()
 		// Ignore loopback and bonding_masters
@@ -69,21 +96,76 @@ func nics(ctx context.Context) []*NIC {
 		mac := netDeviceMacAddress(paths, filename)
 		nic.MacAddress = mac
 		nic.MACAddress = mac
-		if etAvailable {
-			nic.netDeviceParseEthtool(ctx, filename)
-		} else {
-			nic.Capabilities = []*NICCapability{}
-			// Sets NIC struct fields from data in SysFs
-			nic.setNicAttrSysFs(paths, filename)
-		}
+		nic.setNicAttrs(ctx, paths, native, etAvailable, filename)
 
 		nic.PCIAddress = netDevicePCIAddress(paths.SysClassNet, filename)
+		nic.setNicLinkAttrs(paths, filename, addrs)
+		nic.setNicSRIOV(ctx, paths, links, filename)
+		if isVirtual {
+			nic.setNicKind(ctx, paths, links, filename)
+		}
 
 		nics = append(nics, nic)
 	}
+
+	byName := map[string]*NIC{}
+	for _, nic := range nics {
+		byName[nic.Name] = nic
+	}
+	for _, nic := range nics {
+		if master, ok := byName[nic.Master]; ok {
+			master.Slaves = append(master.Slaves, nic.Name)
+		}
+	}
+	pairNICPeers(nics)
 	return nics
 }
 
+// setNicLinkAttrs sets the link state, the master and the addressing of the
+// NIC from sysfs and the supplied addressing of the host
+func (n *NIC) setNicLinkAttrs(paths *linuxpath.Paths, dev string, addrs *netAddressing) {
+	netPath := filepath.Join(paths.SysClassNet, dev)
+	n.MTU, _ = strconv.Atoi(readFile(filepath.Join(netPath, "mtu")))
+	n.IfIndex, _ = strconv.Atoi(readFile(filepath.Join(netPath, "ifindex")))
+	n.OperState = readFile(filepath.Join(netPath, "operstate"))
+	n.HasCarrier = readFile(filepath.Join(netPath, "carrier")) == "1"
+	if dest, err := os.Readlink(filepath.Join(netPath, "master")); err == nil {
+		n.Master = filepath.Base(dest)
+	}
+	n.Addresses = addrs.addresses[dev]
+	n.IPv4DefaultRoute = addrs.ipv4Default[dev]
+	n.IPv6DefaultRoute = addrs.ipv6Default[dev]
+}
+
+// setNicAttrs sets the capabilities and link fields of the NIC, querying the
+// kernel with the native ethtool backend when available. The ethtool CLI is
+// only run when the kernel could not be queried, and sysfs is the last resort.
+func (n *NIC) setNicAttrs(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	native *ethtoolNative,
+	etAvailable bool,
+	dev string,
+) {
+	if native != nil {
+		hasLink, err := native.fill(n, dev)
+		if err == nil {
+			if !hasLink {
+				n.setNicAttrSysFs(paths, dev)
+			}
+			return
+		}
+		log.Debug(ctx, "could not query %s with the native ethtool backend: %s", dev, err)
+	}
+	if etAvailable {
+		n.netDeviceParseEthtool(ctx, dev)
+		return
+	}
+	n.Capabilities = []*NICCapability{}
+	// Sets NIC struct fields from data in SysFs
+	n.setNicAttrSysFs(paths, dev)
+}
+
 func netDeviceMacAddress(paths *linuxpath.Paths, dev string) string {
 	// Instead of use udevadm, we can get the device's MAC address by examing
 	// the /sys/class/net/$DEVICE/address file in sysfs. However, for devices
@@ -11,10 +11,20 @@ package net
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	gonet "net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
 )
 
 func TestParseEthtoolFeature(t *testing.T) {
@@ -148,3 +158,686 @@ func TestParseNicAttrEthtool(t *testing.T) {
 		}
 	}
 }
+
+// ethtoolTestBitset returns a bitset attribute in the verbose form, with a
+// mask unless value is nil
+func ethtoolTestBitset(typ uint16, mask []string, value map[string]bool) []byte {
+	bits := []byte{}
+	for x, name := range mask {
+		bit := appendNLAttr(nil, ethtoolABitsetBitIndex, nlUint32(uint32(x)))
+		bit = appendNLAttr(bit, ethtoolABitsetBitName, nlString(name))
+		if value == nil || value[name] {
+			bit = appendNLAttr(bit, ethtoolABitsetBitValue, nil)
+		}
+		bits = appendNLAttr(bits, ethtoolABitsetBitsBit|nlaFNested, bit)
+	}
+	bitset := []byte{}
+	if value == nil {
+		bitset = appendNLAttr(bitset, ethtoolABitsetNomask, nil)
+	}
+	bitset = appendNLAttr(bitset, ethtoolABitsetBits|nlaFNested, bits)
+	return appendNLAttr(nil, typ|nlaFNested, bitset)
+}
+
+func TestEthtoolNetlinkFeatures(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	reply := ethtoolHeader("eth0")
+	reply = append(reply, ethtoolTestBitset(ethtoolAFeaturesHW, []string{
+		"tx-checksum-ipv4", "tx-scatter-gather", "rx-gro", "rx-hashing",
+	}, nil)...)
+	reply = append(reply, ethtoolTestBitset(ethtoolAFeaturesActive, []string{
+		"tx-checksum-ipv4", "rx-checksum", "tx-scatter-gather", "rx-gro", "highdma",
+	}, nil)...)
+	reply = append(reply, ethtoolTestBitset(ethtoolAFeaturesNochange, []string{"rx-hashing"}, nil)...)
+	// the features string set, as returned by ETHTOOL_MSG_STRSET_GET
+	strs := []byte{}
+	for x, name := range []string{
+		"tx-scatter-gather", "tx-checksum-ipv4", "tx-checksum-ipv6", "highdma",
+		"tx-scatter-gather-fraglist", "rx-gro", "rx-checksum", "rx-hashing",
+	} {
+		str := appendNLAttr(nil, ethtoolAStringIndex, nlUint32(uint32(x)))
+		str = appendNLAttr(str, ethtoolAStringValue, nlString(name))
+		strs = appendNLAttr(strs, ethtoolAStringsString|nlaFNested, str)
+	}
+	set := appendNLAttr(nil, ethtoolAStringsetID, nlUint32(ethtoolStringSetFeatures))
+	set = appendNLAttr(set, ethtoolAStringsetStrings|nlaFNested, strs)
+	strset := appendNLAttr(nil, ethtoolAStrsetStringsets|nlaFNested,
+		appendNLAttr(nil, ethtoolAStringsetsSet|nlaFNested, set))
+	names, err := parseEthtoolStrings(strset)
+	if err != nil || len(names) != 8 || names[3] != "highdma" {
+		t.Fatalf("Expected the 8 feature names, but got %v (%v)", names, err)
+	}
+
+	features, err := parseEthtoolFeatures(reply, names)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the features, but got %v", err)
+	}
+	expected := []*NICCapability{
+		{Name: "rx-checksumming", IsEnabled: true, CanEnable: false},
+		{Name: "tx-checksumming", IsEnabled: true, CanEnable: true},
+		{Name: "tx-checksum-ipv4", IsEnabled: true, CanEnable: true},
+		{Name: "tx-checksum-ipv6", IsEnabled: false, CanEnable: false},
+		{Name: "scatter-gather", IsEnabled: true, CanEnable: true},
+		{Name: "tx-scatter-gather", IsEnabled: true, CanEnable: true},
+		{Name: "tx-scatter-gather-fraglist", IsEnabled: false, CanEnable: false},
+		{Name: "generic-receive-offload", IsEnabled: true, CanEnable: true},
+		{Name: "receive-hashing", IsEnabled: false, CanEnable: false},
+		{Name: "highdma", IsEnabled: true, CanEnable: false},
+	}
+	if actual := ethtoolCapabilities(features); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected capabilities %v, but got %v", expected, actual)
+	}
+}
+
+func TestEthtoolNetlinkLinkModes(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	reply := ethtoolHeader("eth0")
+	reply = appendNLAttr(reply, ethtoolALinkmodesAutoneg, []byte{1})
+	reply = append(reply, ethtoolTestBitset(ethtoolALinkmodesOurs, []string{
+		"10baseT/Full", "1000baseT/Full", "Autoneg", "TP", "Pause", "FEC_NONE", "FEC_RS", "25000baseCR/Full",
+	}, map[string]bool{
+		"1000baseT/Full": true, "Autoneg": true, "TP": true, "FEC_RS": true, "25000baseCR/Full": true,
+	})...)
+	reply = appendNLAttr(reply, ethtoolALinkmodesSpeed, nlUint32(25000))
+	reply = appendNLAttr(reply, ethtoolALinkmodesDuplex, []byte{ethtoolDuplexFull})
+
+	lm, err := parseEthtoolLinkModes(reply)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the link modes, but got %v", err)
+	}
+	actual := &NIC{}
+	actual.setEthtoolLinkModes(lm)
+	expected := &NIC{
+		Speed:               "25000Mb/s",
+		Duplex:              "Full",
+		SupportedLinkModes:  []string{"10baseT/Full", "1000baseT/Full", "25000baseCR/Full"},
+		SupportedPorts:      []string{"TP"},
+		SupportedFECModes:   []string{"None", "RS"},
+		AdvertisedLinkModes: []string{"1000baseT/Full", "25000baseCR/Full"},
+		AdvertisedFECModes:  []string{"RS"},
+		Capabilities: []*NICCapability{
+			{Name: "auto-negotiation", IsEnabled: true, CanEnable: true},
+			{Name: "pause-frame-use", IsEnabled: false, CanEnable: true},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", *expected, *actual)
+	}
+}
+
+func TestEthtoolIoctlLinkModes(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	// the kernels lacking the link modes string set name the bits after the
+	// ethtool_link_mode_bit_indices enum
+	for name, bit := range map[string]int{
+		"10baseT/Half": 0, "1000baseT/Full": 5, "Autoneg": 6, "TP": 7, "Pause": 13,
+		"25000baseCR/Full": 31, "FEC_NONE": 49, "FEC_RS": 50, "1000baseT1/Full": 68,
+	} {
+		if ethtoolLinkModeNames[bit] != name {
+			t.Fatalf("Expected link mode bit %d to be %s, but got %s", bit, name, ethtoolLinkModeNames[bit])
+		}
+	}
+
+	// three words per mask, the supported, advertising and lp_advertising ones
+	const nwords = 3
+	settings := make([]byte, ethtoolLinkSettingsLen+3*nwords*4)
+	binary.NativeEndian.PutUint32(settings[0:4], ethtoolGLinkSettings)
+	binary.NativeEndian.PutUint32(settings[4:8], 25000)
+	settings[8] = ethtoolDuplexFull
+	settings[11] = 1
+	setBit := func(mask int, x int) {
+		off := ethtoolLinkSettingsLen + (mask*nwords+x/32)*4
+		binary.NativeEndian.PutUint32(settings[off:off+4], binary.NativeEndian.Uint32(settings[off:off+4])|1<<(x%32))
+	}
+	for _, x := range []int{1, 5, 6, 7, 13, 31, 49, 50} {
+		setBit(0, x)
+	}
+	for _, x := range []int{5, 6, 31, 50} {
+		setBit(1, x)
+	}
+	// a partner mode, which is not reported
+	setBit(2, 12)
+
+	lm := parseEthtoolLinkSettings(settings, nwords, ethtoolLinkModeNames)
+	actual := &NIC{}
+	actual.setEthtoolLinkModes(lm)
+	expected := &NIC{
+		Speed:               "25000Mb/s",
+		Duplex:              "Full",
+		SupportedLinkModes:  []string{"10baseT/Full", "1000baseT/Full", "25000baseCR/Full"},
+		SupportedPorts:      []string{"TP"},
+		SupportedFECModes:   []string{"None", "RS"},
+		AdvertisedLinkModes: []string{"1000baseT/Full", "25000baseCR/Full"},
+		AdvertisedFECModes:  []string{"RS"},
+		Capabilities: []*NICCapability{
+			{Name: "auto-negotiation", IsEnabled: true, CanEnable: true},
+			{Name: "pause-frame-use", IsEnabled: false, CanEnable: true},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", *expected, *actual)
+	}
+}
+
+// routeHex returns an IPv4 address the way /proc/net/route prints it, as a
+// 32-bit integer in host byte order
+func routeHex(ip string) string {
+	return fmt.Sprintf("%08X", binary.NativeEndian.Uint32(gonet.ParseIP(ip).To4()))
+}
+
+func TestNICAddressing(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	for dev, attrs := range map[string]map[string]string{
+		"bond0": {"mtu": "9000", "ifindex": "4", "operstate": "up", "carrier": "1"},
+		"eth0":  {"mtu": "9000", "ifindex": "2", "operstate": "up", "carrier": "1"},
+		"eth1":  {"mtu": "1500", "ifindex": "3", "operstate": "down"},
+	} {
+		_ = os.MkdirAll(filepath.Join(paths.SysClassNet, dev), 0755)
+		for attr, val := range attrs {
+			_ = os.WriteFile(filepath.Join(paths.SysClassNet, dev, attr), []byte(val+"\n"), 0644)
+		}
+	}
+	_ = os.Symlink("../bond0", filepath.Join(paths.SysClassNet, "eth0", "master"))
+
+	_ = os.MkdirAll(filepath.Dir(paths.ProcNetRoute), 0755)
+	route := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+		fmt.Sprintf("bond0\t00000000\t%s\t0003\t0\t0\t0\t00000000\t0\t0\t0\n", routeHex("192.0.2.1")) +
+		fmt.Sprintf("bond0\t%s\t00000000\t0001\t0\t0\t0\t%s\t0\t0\t0\n", routeHex("192.0.2.0"), routeHex("255.255.255.0")) +
+		fmt.Sprintf("eth1\t%s\t00000000\t0001\t0\t0\t0\t%s\t0\t0\t0\n", routeHex("10.0.0.0"), routeHex("255.0.0.0"))
+	_ = os.WriteFile(paths.ProcNetRoute, []byte(route), 0644)
+	fibTrie := `Main:
+  +-- 0.0.0.0/0 3 0 5
+     |-- 0.0.0.0
+        /0 universe UNICAST
+     +-- 10.0.0.0/8 2 0 2
+        |-- 10.0.0.0
+           /8 link UNICAST
+        |-- 10.1.2.3
+           /32 host LOCAL
+     +-- 127.0.0.0/8 2 0 2
+        |-- 127.0.0.1
+           /32 host LOCAL
+     +-- 192.0.2.0/24 2 0 2
+        |-- 192.0.2.0
+           /24 link UNICAST
+        |-- 192.0.2.10
+           /32 host LOCAL
+        |-- 192.0.2.255
+           /32 link BROADCAST
+Local:
+     +-- 192.0.2.0/24 2 0 2
+        |-- 192.0.2.10
+           /32 host LOCAL
+`
+	_ = os.WriteFile(paths.ProcNetFibTrie, []byte(fibTrie), 0644)
+	ifInet6 := `20010db8000000000000000000000010 04 40 00 80    bond0
+fe80000000000000021122fffe334455 04 40 20 80    bond0
+00000000000000000000000000000001 01 80 10 80       lo
+`
+	_ = os.WriteFile(paths.ProcNetIfInet6, []byte(ifInet6), 0644)
+	ipv6Route := `00000000000000000000000000000000 00 00000000000000000000000000000000 00 20010db8000000000000000000000001 00000400 00000001 00000000 00000003    bond0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo
+`
+	_ = os.WriteFile(paths.ProcNetIPv6Route, []byte(ipv6Route), 0644)
+
+	byName := map[string]*NIC{}
+	for _, nic := range nics(ctx) {
+		byName[nic.Name] = nic
+	}
+	bond := byName["bond0"]
+	if bond == nil || bond.MTU != 9000 || bond.IfIndex != 4 || bond.OperState != "up" || !bond.HasCarrier {
+		t.Fatalf("Expected bond0 up with carrier, MTU 9000 and ifindex 4, but got %+v", bond)
+	}
+	if !reflect.DeepEqual(bond.Slaves, []string{"eth0"}) || byName["eth0"].Master != "bond0" {
+		t.Fatalf("Expected eth0 to be enslaved to bond0, but got slaves %v", bond.Slaves)
+	}
+	expected := []*NICAddress{
+		{IP: "192.0.2.10", PrefixLength: 24},
+		{IP: "2001:db8::10", PrefixLength: 64, Scope: "global"},
+		{IP: "fe80::211:22ff:fe33:4455", PrefixLength: 64, Scope: "link"},
+	}
+	if !reflect.DeepEqual(bond.Addresses, expected) {
+		t.Fatalf("Expected bond0 addresses %v, but got %v", expected, bond.Addresses)
+	}
+	if !bond.IPv4DefaultRoute || !bond.IPv6DefaultRoute {
+		t.Fatalf("Expected bond0 to carry the default routes")
+	}
+
+	eth1 := byName["eth1"]
+	if eth1.HasCarrier || eth1.OperState != "down" || eth1.IPv4DefaultRoute || eth1.IPv6DefaultRoute {
+		t.Fatalf("Expected eth1 down without default route, but got %+v", eth1)
+	}
+	expected = []*NICAddress{{IP: "10.1.2.3", PrefixLength: 8}}
+	if !reflect.DeepEqual(eth1.Addresses, expected) {
+		t.Fatalf("Expected eth1 addresses %v, but got %v", expected, eth1.Addresses)
+	}
+}
+
+func TestParseIfAddrMsg(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	// a struct ifaddrmsg of a /30 address of interface 7, a point-to-point
+	// one whose IFA_ADDRESS is the address of the peer
+	msg := make([]byte, syscall.SizeofIfAddrmsg)
+	msg[0] = syscall.AF_INET
+	msg[1] = 30
+	binary.NativeEndian.PutUint32(msg[4:8], 7)
+	msg = appendNLAttr(msg, syscall.IFA_ADDRESS, gonet.ParseIP("192.0.2.2").To4())
+	msg = appendNLAttr(msg, syscall.IFA_LOCAL, gonet.ParseIP("192.0.2.1").To4())
+	msg = appendNLAttr(msg, syscall.IFA_LABEL, nlString("ppp0"))
+
+	index, addr, err := parseIfAddrMsg(msg)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the address, but got %v", err)
+	}
+	expected := &NICAddress{IP: "192.0.2.1", PrefixLength: 30}
+	if index != 7 || !reflect.DeepEqual(addr, expected) {
+		t.Fatalf("Expected %v of interface 7, but got %v of interface %d", expected, addr, index)
+	}
+
+	// a /32 address, which the routes of a snapshot cannot attribute
+	msg = make([]byte, syscall.SizeofIfAddrmsg)
+	msg[0] = syscall.AF_INET
+	msg[1] = 32
+	binary.NativeEndian.PutUint32(msg[4:8], 2)
+	msg = appendNLAttr(msg, syscall.IFA_ADDRESS, gonet.ParseIP("203.0.113.5").To4())
+	index, addr, err = parseIfAddrMsg(msg)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the address, but got %v", err)
+	}
+	expected = &NICAddress{IP: "203.0.113.5", PrefixLength: 32}
+	if index != 2 || !reflect.DeepEqual(addr, expected) {
+		t.Fatalf("Expected %v of interface 2, but got %v of interface %d", expected, addr, index)
+	}
+
+	if _, _, err = parseIfAddrMsg(msg[:4]); err == nil {
+		t.Fatalf("Expected an error parsing a short ifaddrmsg, but got nil")
+	}
+}
+
+// vfTestAttr returns an IFLA_VF_* attribute of the VF of the supplied index
+func vfTestAttr(typ uint16, vf uint32, vals ...uint32) []byte {
+	data := nlUint32(vf)
+	for _, v := range vals {
+		data = append(data, nlUint32(v)...)
+	}
+	return appendNLAttr(nil, typ, data)
+}
+
+func TestParseLinkVFs(t *testing.T) {
+	mac := make([]byte, 32)
+	copy(mac, []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55})
+	vf0 := appendNLAttr(nil, iflaVFMAC, append(nlUint32(0), mac...))
+	vf0 = append(vf0, vfTestAttr(iflaVFVLAN, 0, 100, 3)...)
+	vf0 = append(vf0, vfTestAttr(iflaVFSpoofChk, 0, 1)...)
+	vf0 = append(vf0, vfTestAttr(iflaVFTrust, 0, 0)...)
+	vf0 = append(vf0, vfTestAttr(iflaVFLinkState, 0, 2)...)
+	vf1 := appendNLAttr(nil, iflaVFMAC, append(nlUint32(1), make([]byte, 32)...))
+	vf1 = append(vf1, vfTestAttr(iflaVFVLAN, 1, 0, 0)...)
+	// drivers lacking the setting report -1
+	vf1 = append(vf1, vfTestAttr(iflaVFSpoofChk, 1, 0xffffffff)...)
+	vf1 = append(vf1, vfTestAttr(iflaVFTrust, 1, 1)...)
+	vf1 = append(vf1, vfTestAttr(iflaVFLinkState, 1, 0)...)
+	list := appendNLAttr(nil, iflaVFInfo|nlaFNested, vf0)
+	list = appendNLAttr(list, iflaVFInfo|nlaFNested, vf1)
+	b := appendNLAttr(nil, syscall.IFLA_IFNAME, nlString("ens1f0"))
+	b = appendNLAttr(b, syscall.IFLA_ADDRESS, []byte{0x0c, 0x42, 0xa1, 0x00, 0x00, 0x01})
+	b = appendNLAttr(b, iflaVFInfoList|nlaFNested, list)
+
+	vfs, err := parseLinkVFs(b)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	expected := []*NICVirtualFunction{
+		{Index: 0, MACAddress: "02:11:22:33:44:55", VLAN: 100, QoS: 3, SpoofCheck: true, LinkState: "disable"},
+		{Index: 1, Trust: true, LinkState: "auto"},
+	}
+	if !reflect.DeepEqual(vfs, expected) {
+		t.Fatalf("Expected VFs %+v, but got %+v", expected, vfs)
+	}
+
+	mode, err := parseEswitchMode(appendNLAttr(nil, devlinkAttrEswitchMode, []byte{1, 0}))
+	if err != nil || mode != "switchdev" {
+		t.Fatalf("Expected switchdev eswitch mode, but got %q (%v)", mode, err)
+	}
+}
+
+func TestNICSRIOV(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	// ens1f0 is the PF, whose VF 0 is bound to the host network driver as
+	// ens1f0v0 and VF 1 to vfio-pci, which creates no NIC
+	pciDir := filepath.Join(baseDir, "sys", "devices", "pci0000:3a", "0000:3a:00.0")
+	devs := map[string]string{
+		"ens1f0":   "0000:3b:00.0",
+		"ens1f0v0": "0000:3b:02.0",
+		"":         "0000:3b:02.1",
+	}
+	_ = os.MkdirAll(paths.SysClassNet, 0755)
+	_ = os.MkdirAll(filepath.Join(baseDir, "sys", "bus", "pci"), 0755)
+	for nic, addr := range devs {
+		_ = os.MkdirAll(filepath.Join(pciDir, addr), 0755)
+		_ = os.Symlink("../../../bus/pci", filepath.Join(pciDir, addr, "subsystem"))
+		if nic == "" {
+			continue
+		}
+		netDir := filepath.Join(pciDir, addr, "net", nic)
+		_ = os.MkdirAll(netDir, 0755)
+		_ = os.Symlink("../../../"+addr, filepath.Join(netDir, "device"))
+		rel, _ := filepath.Rel(paths.SysClassNet, netDir)
+		_ = os.Symlink(rel, filepath.Join(paths.SysClassNet, nic))
+	}
+	pfDir := filepath.Join(pciDir, "0000:3b:00.0")
+	_ = os.WriteFile(filepath.Join(pfDir, "sriov_totalvfs"), []byte("64\n"), 0644)
+	_ = os.WriteFile(filepath.Join(pfDir, "sriov_numvfs"), []byte("2\n"), 0644)
+	for idx, addr := range []string{"0000:3b:02.0", "0000:3b:02.1"} {
+		_ = os.Symlink("../"+addr, filepath.Join(pfDir, fmt.Sprintf("virtfn%d", idx)))
+		_ = os.Symlink("../0000:3b:00.0", filepath.Join(pciDir, addr, "physfn"))
+	}
+	modeDir := filepath.Join(pfDir, "net", "ens1f0", "compat", "devlink")
+	_ = os.MkdirAll(modeDir, 0755)
+	_ = os.WriteFile(filepath.Join(modeDir, "mode"), []byte("legacy\n"), 0644)
+
+	byName := map[string]*NIC{}
+	for _, nic := range nics(ctx) {
+		byName[nic.Name] = nic
+	}
+	pf := byName["ens1f0"]
+	if pf == nil || pf.SRIOV == nil {
+		t.Fatalf("Expected ens1f0 to be an SR-IOV PF, but got %+v", pf)
+	}
+	if pf.SRIOV.TotalVFs != 64 || pf.SRIOV.NumVFs != 2 || pf.SRIOV.EswitchMode != "legacy" {
+		t.Fatalf("Expected 2/64 VFs in legacy mode, but got %+v", pf.SRIOV)
+	}
+	expected := []*NICVirtualFunction{
+		{Index: 0, PCIAddress: "0000:3b:02.0", Name: "ens1f0v0"},
+		{Index: 1, PCIAddress: "0000:3b:02.1"},
+	}
+	if !reflect.DeepEqual(pf.SRIOV.VFs, expected) {
+		t.Fatalf("Expected VFs %+v, but got %+v", expected, pf.SRIOV.VFs)
+	}
+	if pf.PhysFnPCIAddress != "" {
+		t.Fatalf("Expected ens1f0 to have no physical function, but got %q", pf.PhysFnPCIAddress)
+	}
+
+	vf := byName["ens1f0v0"]
+	if vf == nil || vf.SRIOV != nil || vf.PhysFnPCIAddress != "0000:3b:00.0" || vf.PhysFn != "ens1f0" {
+		t.Fatalf("Expected ens1f0v0 to be a VF of ens1f0, but got %+v", vf)
+	}
+}
+
+func TestParseLinkInfo(t *testing.T) {
+	data := appendNLAttr(nil, iflaVXLANID, nlUint32(42))
+	data = appendNLAttr(data, iflaVXLANGroup, []byte{192, 0, 2, 1})
+	data = appendNLAttr(data, iflaVXLANLocal, []byte{0, 0, 0, 0})
+	data = appendNLAttr(data, iflaVXLANPort, []byte{0x12, 0xb5})
+	info := appendNLAttr(nil, iflaInfoKind, nlString("vxlan"))
+	info = appendNLAttr(info, iflaInfoData|nlaFNested, data)
+	b := appendNLAttr(nil, syscall.IFLA_IFNAME, nlString("vxlan42"))
+	b = appendNLAttr(b, syscall.IFLA_LINKINFO|nlaFNested, info)
+
+	li, err := parseLinkInfo(b)
+	if err != nil || li == nil || li.kind != "vxlan" {
+		t.Fatalf("Expected a vxlan link, but got %+v (%v)", li, err)
+	}
+	expected := &NICVXLAN{VNI: 42, Port: 4789, Remote: "192.0.2.1"}
+	if got := li.vxlan(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected VXLAN %+v, but got %+v", expected, got)
+	}
+
+	data = appendNLAttr(nil, iflaVLANID, []byte{100, 0})
+	data = appendNLAttr(data, iflaVLANProtocol, []byte{0x88, 0xa8})
+	info = appendNLAttr(nil, iflaInfoKind, nlString("vlan"))
+	info = appendNLAttr(info, iflaInfoData|nlaFNested, data)
+	li, err = parseLinkInfo(appendNLAttr(nil, syscall.IFLA_LINKINFO|nlaFNested, info))
+	if err != nil || li == nil {
+		t.Fatalf("Expected a vlan link, but got %+v (%v)", li, err)
+	}
+	if got := li.vlan(); got.ID != 100 || got.Protocol != "802.1ad" {
+		t.Fatalf("Expected VLAN 100 over 802.1ad, but got %+v", got)
+	}
+
+	// physical NICs have no link info
+	li, err = parseLinkInfo(appendNLAttr(nil, syscall.IFLA_IFNAME, nlString("eth0")))
+	if err != nil || li != nil {
+		t.Fatalf("Expected no link info, but got %+v (%v)", li, err)
+	}
+}
+
+func TestNICKinds(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	virtualDir := filepath.Join(baseDir, "sys", "devices", "virtual", "net")
+	pciDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:03.0", "net")
+	_ = os.MkdirAll(paths.SysClassNet, 0755)
+	for dev, attrs := range map[string]map[string]string{
+		"eth0":      {"ifindex": "2", "iflink": "2"},
+		"bond0":     {"ifindex": "3", "iflink": "3", "uevent": "DEVTYPE=bond\nINTERFACE=bond0\nIFINDEX=3"},
+		"bond0.100": {"ifindex": "4", "iflink": "3", "uevent": "DEVTYPE=vlan\nINTERFACE=bond0.100\nIFINDEX=4"},
+		"br0":       {"ifindex": "5", "iflink": "5", "uevent": "DEVTYPE=bridge\nINTERFACE=br0\nIFINDEX=5"},
+		"veth0":     {"ifindex": "6", "iflink": "7", "uevent": "INTERFACE=veth0\nIFINDEX=6"},
+		"veth1":     {"ifindex": "7", "iflink": "6", "uevent": "INTERFACE=veth1\nIFINDEX=7"},
+		"tap0":      {"ifindex": "8", "iflink": "8", "tun_flags": "0x1002"},
+		"mv0":       {"ifindex": "9", "iflink": "2"},
+		// a veth NIC whose peer lives in another network namespace
+		"veth2": {"ifindex": "10", "iflink": "2"},
+	} {
+		devDir := filepath.Join(virtualDir, dev)
+		if dev == "eth0" {
+			devDir = filepath.Join(pciDir, dev)
+		}
+		_ = os.MkdirAll(devDir, 0755)
+		for attr, val := range attrs {
+			_ = os.WriteFile(filepath.Join(devDir, attr), []byte(val+"\n"), 0644)
+		}
+		rel, _ := filepath.Rel(paths.SysClassNet, devDir)
+		_ = os.Symlink(rel, filepath.Join(paths.SysClassNet, dev))
+	}
+	for attr, val := range map[string]string{
+		"bond0/bonding/mode":             "802.3ad 4",
+		"bond0/bonding/miimon":           "100",
+		"bond0/bonding/lacp_rate":        "fast 1",
+		"bond0/bonding/xmit_hash_policy": "layer3+4 1",
+		"br0/bridge/stp_state":           "1",
+		"br0/bridge/vlan_filtering":      "0",
+	} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(virtualDir, attr)), 0755)
+		_ = os.WriteFile(filepath.Join(virtualDir, attr), []byte(val+"\n"), 0644)
+	}
+	_ = os.Symlink("../bond0", filepath.Join(virtualDir, "bond0.100", "lower_bond0"))
+	_ = os.Symlink("../bond0", filepath.Join(pciDir, "eth0", "master"))
+	_ = os.Symlink("../../../../pci0000:00/0000:00:03.0/net/eth0", filepath.Join(virtualDir, "mv0", "lower_eth0"))
+	_ = os.Symlink("../br0", filepath.Join(virtualDir, "veth0", "master"))
+	_ = os.MkdirAll(paths.ProcNetVLAN, 0755)
+	procVLAN := "bond0.100  VID: 100\t REORDER_HDR: 1  dev->priv_flags: 1\n" +
+		"         total frames received            0\n" +
+		"Device: bond0\n"
+	_ = os.WriteFile(filepath.Join(paths.ProcNetVLAN, "bond0.100"), []byte(procVLAN), 0644)
+
+	byName := map[string]*NIC{}
+	for _, nic := range nics(ctx) {
+		byName[nic.Name] = nic
+	}
+	bond := byName["bond0"]
+	expectedBond := &NICBond{Mode: "802.3ad", MIIMon: 100, XmitHashPolicy: "layer3+4", LACPRate: "fast"}
+	if bond.Kind != NICKindBond || !reflect.DeepEqual(bond.Bond, expectedBond) {
+		t.Fatalf("Expected bond0 to be a bond with %+v, but got %+v", expectedBond, bond.Bond)
+	}
+	if !reflect.DeepEqual(bond.Slaves, []string{"eth0"}) {
+		t.Fatalf("Expected bond0 slaves [eth0], but got %v", bond.Slaves)
+	}
+	vlan := byName["bond0.100"]
+	if vlan.Kind != NICKindVLAN || vlan.Parent != "bond0" || vlan.VLAN == nil || vlan.VLAN.ID != 100 {
+		t.Fatalf("Expected bond0.100 to be VLAN 100 over bond0, but got %+v", vlan)
+	}
+	br := byName["br0"]
+	if br.Kind != NICKindBridge || br.Bridge == nil || !br.Bridge.STPEnabled || br.Bridge.VLANFiltering {
+		t.Fatalf("Expected br0 to be a bridge running STP, but got %+v", br)
+	}
+	if !reflect.DeepEqual(br.Slaves, []string{"veth0"}) {
+		t.Fatalf("Expected br0 ports [veth0], but got %v", br.Slaves)
+	}
+	for dev, peer := range map[string]string{"veth0": "veth1", "veth1": "veth0"} {
+		nic := byName[dev]
+		if nic.Kind != NICKindVeth || nic.Peer != peer || nic.PeerIfIndex != byName[peer].IfIndex {
+			t.Fatalf("Expected %s to be the veth peer of %s, but got %+v", dev, peer, nic)
+		}
+	}
+	if tap := byName["tap0"]; tap.Kind != NICKindTap {
+		t.Fatalf("Expected tap0 to be a tap, but got %q", tap.Kind)
+	}
+	// sysfs does not tell MACVLAN NICs and veth NICs whose peer lives in
+	// another namespace apart from other kinds
+	for _, dev := range []string{"mv0", "veth2"} {
+		if nic := byName[dev]; nic.Kind != "" || nic.PeerIfIndex != 0 || nic.Parent != "" {
+			t.Fatalf("Expected %s to be of unknown kind, but got %+v", dev, nic)
+		}
+	}
+	if eth0 := byName["eth0"]; eth0.Kind != "" || eth0.Master != "bond0" {
+		t.Fatalf("Expected eth0 to be a physical NIC enslaved to bond0, but got %+v", eth0)
+	}
+}
+
+func TestParseEthtoolStats(t *testing.T) {
+	names := []string{"rx_queue_0_packets", "tx_queue_0_packets", "rx_csum_errors"}
+	b := make([]byte, 8+3*8)
+	binary.NativeEndian.PutUint32(b[0:4], ethtoolGStats)
+	// the driver reports fewer counters than it named
+	binary.NativeEndian.PutUint32(b[4:8], 2)
+	binary.NativeEndian.PutUint64(b[8:16], 1234)
+	binary.NativeEndian.PutUint64(b[16:24], 5678)
+
+	stats, err := parseEthtoolStats(b, names)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := map[string]uint64{"rx_queue_0_packets": 1234, "tx_queue_0_packets": 5678}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatalf("Expected %v, but got %v", expected, stats)
+	}
+
+	binary.NativeEndian.PutUint32(b[4:8], 4)
+	if _, err := parseEthtoolStats(b, names); err == nil {
+		t.Fatalf("Expected an error for more counters than names, but got nil")
+	}
+}
+
+func TestNICStats(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := context.TODO()
+	ctx = config.WithChroot(baseDir)(ctx)
+	ctx = config.WithDisableTools()(ctx)
+	paths := linuxpath.New(ctx)
+
+	writeStats := func(dev string, counters map[string]uint64) {
+		statsDir := filepath.Join(paths.SysClassNet, dev, "statistics")
+		_ = os.MkdirAll(statsDir, 0755)
+		for name, val := range counters {
+			if err := os.WriteFile(filepath.Join(statsDir, name), []byte(fmt.Sprintf("%d\n", val)), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeStats("eth0", map[string]uint64{
+		"rx_bytes":          1_000_000,
+		"rx_packets":        1_000,
+		"rx_errors":         10,
+		"rx_dropped":        4,
+		"rx_crc_errors":     7,
+		"tx_bytes":          500_000,
+		"tx_packets":        600,
+		"tx_errors":         0,
+		"tx_dropped":        2,
+		"tx_carrier_errors": 1,
+		"multicast":         12,
+	})
+	writeStats("lo", map[string]uint64{"rx_bytes": 1})
+	// a NIC lacking statistics is left out
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "eth1"), 0755)
+
+	prev := &Stats{}
+	if err := prev.load(ctx); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(prev.NICs) != 1 || prev.NIC("eth0") == nil {
+		t.Fatalf("Expected the statistics of eth0 only, but got %+v", prev.NICs)
+	}
+	eth0 := prev.NIC("eth0")
+	if eth0.RxBytes != 1_000_000 || eth0.RxPackets != 1_000 || eth0.RxCRCErrors != 7 ||
+		eth0.TxBytes != 500_000 || eth0.TxCarrierErrors != 1 || eth0.Multicast != 12 {
+		t.Fatalf("Expected the counters of the statistics files, but got %+v", eth0)
+	}
+	if eth0.RxNoHandler != 0 || eth0.Driver != nil {
+		t.Fatalf("Expected missing counters to be zero, but got %+v", eth0)
+	}
+
+	// tx_packets went down, as when the NIC is recreated between samples
+	writeStats("eth0", map[string]uint64{
+		"rx_bytes":   3_000_000,
+		"rx_packets": 3_000,
+		"rx_errors":  30,
+		"tx_packets": 100,
+	})
+	cur := &Stats{}
+	if err := cur.load(ctx); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	cur.Timestamp = prev.Timestamp.Add(2 * time.Second)
+	rates, err := Rates(prev, cur)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := []*NICRates{
+		{
+			Name:               "eth0",
+			RxBytesPerSecond:   1_000_000,
+			RxPacketsPerSecond: 1_000,
+			RxErrorsPerSecond:  10,
+		},
+	}
+	if !reflect.DeepEqual(rates, expected) {
+		t.Fatalf("Expected %+v, but got %+v", expected[0], rates)
+	}
+	if _, err := Rates(cur, prev); err == nil {
+		t.Fatalf("Expected an error for samples out of order, but got nil")
+	}
+	if _, err := Rates(nil, cur); err == nil {
+		t.Fatalf("Expected an error for a missing sample, but got nil")
+	}
+}
@@ -0,0 +1,310 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// The kernel interfaces ghw queries over netlink: the rtnetlink link messages
+// and the ethtool and devlink generic netlink families. See
+// include/uapi/linux/netlink.h and include/uapi/linux/genetlink.h for the
+// layouts used below. Netlink headers and attributes are in host byte order,
+// save for the few attributes the kernel declares as __be16 or __be32.
+
+const (
+	nlaFNested    = 0x8000
+	nlaTypeMask   = 0x3fff
+	nlmsgHdrLen   = 16
+	genlmsgHdrLen = 4
+	nlBufferSize  = 1 << 16
+
+	genlIDCtrl         = 0x10
+	ctrlCmdGetFamily   = 3
+	ctrlAttrFamilyID   = 1
+	ctrlAttrFamilyName = 2
+	devlinkGenlName    = "devlink"
+	devlinkGenlVersion = 1
+
+	iflaExtMask = 29
+)
+
+// nlAttr is a netlink attribute
+type nlAttr struct {
+	typ  uint16
+	data []byte
+}
+
+// parseNLAttrs parses the netlink attributes in the supplied buffer, with
+// the nested flag cleared from their types
+func parseNLAttrs(b []byte) ([]nlAttr, error) {
+	attrs := []nlAttr{}
+	for len(b) >= 4 {
+		l := int(binary.NativeEndian.Uint16(b[0:2]))
+		if l < 4 || l > len(b) {
+			return nil, fmt.Errorf("malformed netlink attribute of length %d", l)
+		}
+		attrs = append(attrs, nlAttr{
+			typ:  binary.NativeEndian.Uint16(b[2:4]) & nlaTypeMask,
+			data: b[4:l],
+		})
+		l = nlAlign(l)
+		if l > len(b) {
+			break
+		}
+		b = b[l:]
+	}
+	return attrs, nil
+}
+
+func nlAlign(l int) int {
+	return (l + 3) &^ 3
+}
+
+// appendNLAttr appends a netlink attribute holding the supplied data to b
+func appendNLAttr(b []byte, typ uint16, data []byte) []byte {
+	hdr := make([]byte, 4)
+	binary.NativeEndian.PutUint16(hdr[0:2], uint16(4+len(data)))
+	binary.NativeEndian.PutUint16(hdr[2:4], typ)
+	b = append(b, hdr...)
+	b = append(b, data...)
+	return append(b, make([]byte, nlAlign(len(data))-len(data))...)
+}
+
+func nlUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.NativeEndian.PutUint32(b, v)
+	return b
+}
+
+func nlString(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func nlAttrString(data []byte) string {
+	return strings.TrimRight(string(data), "\x00")
+}
+
+// nlSocket is a netlink socket of some protocol
+type nlSocket struct {
+	fd  int
+	seq uint32
+}
+
+func newNLSocket(proto int) (*nlSocket, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return &nlSocket{fd: fd}, nil
+}
+
+func (s *nlSocket) close() {
+	if s != nil {
+		syscall.Close(s.fd)
+	}
+}
+
+// send sends a message of the supplied type, flags and payload, with the
+// next sequence number
+func (s *nlSocket) send(typ uint16, flags uint16, payload []byte) error {
+	s.seq++
+	msg := make([]byte, nlmsgHdrLen, nlmsgHdrLen+len(payload))
+	msg = append(msg, payload...)
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:6], typ)
+	binary.NativeEndian.PutUint16(msg[6:8], flags)
+	binary.NativeEndian.PutUint32(msg[8:12], s.seq)
+	return syscall.Sendto(s.fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+}
+
+// nlError returns the error a NLMSG_ERROR message carries, or an error
+// telling the reply is empty for an acknowledgement
+func nlError(data []byte) error {
+	if len(data) >= 4 {
+		if errno := int32(binary.NativeEndian.Uint32(data[0:4])); errno != 0 {
+			return syscall.Errno(-errno)
+		}
+	}
+	return fmt.Errorf("empty netlink reply")
+}
+
+// roundTrip sends a request of the supplied message type and payload, and
+// returns the payload of the reply. Only the first message of the reply is
+// returned; see dump for the requests replied to with several messages.
+func (s *nlSocket) roundTrip(typ uint16, payload []byte) ([]byte, error) {
+	if err := s.send(typ, syscall.NLM_F_REQUEST, payload); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, nlBufferSize)
+	for {
+		n, _, err := syscall.Recvfrom(s.fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != s.seq {
+				continue
+			}
+			if m.Header.Type == syscall.NLMSG_ERROR {
+				return nil, nlError(m.Data)
+			}
+			return m.Data, nil
+		}
+	}
+}
+
+// dump sends a dump request of the supplied message type and payload, and
+// returns the payloads of the messages of the reply
+func (s *nlSocket) dump(typ uint16, payload []byte) ([][]byte, error) {
+	if err := s.send(typ, syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP, payload); err != nil {
+		return nil, err
+	}
+
+	replies := [][]byte{}
+	buf := make([]byte, nlBufferSize)
+	for {
+		n, _, err := syscall.Recvfrom(s.fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != s.seq {
+				continue
+			}
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				return nil, nlError(m.Data)
+			}
+			// buf is reused by the next read
+			replies = append(replies, append([]byte(nil), m.Data...))
+		}
+	}
+}
+
+// genlSocket is a connection to a generic netlink family
+type genlSocket struct {
+	*nlSocket
+	family  uint16
+	version uint8
+}
+
+// newGenlSocket opens a generic netlink socket and resolves the family of the
+// supplied name, which fails if the kernel lacks the family
+func newGenlSocket(name string, version uint8) (*genlSocket, error) {
+	s, err := newNLSocket(syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	c := &genlSocket{nlSocket: s, family: genlIDCtrl, version: 1}
+	reply, err := c.request(ctrlCmdGetFamily, appendNLAttr(nil, ctrlAttrFamilyName, nlString(name)))
+	if err != nil {
+		c.close()
+		return nil, fmt.Errorf("resolving the %s generic netlink family: %w", name, err)
+	}
+	attrs, err := parseNLAttrs(reply)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	c.family = 0
+	for _, a := range attrs {
+		if a.typ == ctrlAttrFamilyID && len(a.data) >= 2 {
+			c.family = binary.NativeEndian.Uint16(a.data)
+		}
+	}
+	if c.family == 0 {
+		c.close()
+		return nil, fmt.Errorf("no %s generic netlink family", name)
+	}
+	c.version = version
+	return c, nil
+}
+
+func (c *genlSocket) close() {
+	if c != nil {
+		c.nlSocket.close()
+	}
+}
+
+// request sends a generic netlink request with the supplied command and
+// attributes, and returns the attributes of the reply
+func (c *genlSocket) request(cmd uint8, attrs []byte) ([]byte, error) {
+	payload := make([]byte, genlmsgHdrLen, genlmsgHdrLen+len(attrs))
+	payload[0] = cmd
+	payload[1] = c.version
+	reply, err := c.roundTrip(c.family, append(payload, attrs...))
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < genlmsgHdrLen {
+		return nil, fmt.Errorf("short generic netlink reply")
+	}
+	return reply[genlmsgHdrLen:], nil
+}
+
+// linkNetlink queries the kernel for the links of the host, through
+// rtnetlink, and for the devices behind them, through devlink
+type linkNetlink struct {
+	rtnl    *nlSocket
+	devlink *genlSocket
+}
+
+// newLinkNetlink returns a client of the rtnetlink and devlink interfaces,
+// or an error if neither can be used
+func newLinkNetlink() (*linkNetlink, error) {
+	rtnl, rtnlErr := newNLSocket(syscall.NETLINK_ROUTE)
+	devlink, err := newGenlSocket(devlinkGenlName, devlinkGenlVersion)
+	if rtnlErr != nil && err != nil {
+		return nil, fmt.Errorf("%s; %w", rtnlErr, err)
+	}
+	return &linkNetlink{rtnl: rtnl, devlink: devlink}, nil
+}
+
+func (s *linkNetlink) close() {
+	s.rtnl.close()
+	s.devlink.close()
+}
+
+// getLink returns the attributes of the RTM_NEWLINK message describing the
+// supplied NIC, with the extra information of the supplied RTEXT_FILTER_*
+// mask
+func (s *linkNetlink) getLink(dev string, extMask uint32) ([]byte, error) {
+	if s.rtnl == nil {
+		return nil, fmt.Errorf("rtnetlink unavailable")
+	}
+	payload := make([]byte, syscall.SizeofIfInfomsg)
+	payload = appendNLAttr(payload, syscall.IFLA_IFNAME, nlString(dev))
+	if extMask != 0 {
+		payload = appendNLAttr(payload, iflaExtMask, nlUint32(extMask))
+	}
+	reply, err := s.rtnl.roundTrip(syscall.RTM_GETLINK, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < syscall.SizeofIfInfomsg {
+		return nil, fmt.Errorf("short rtnetlink reply")
+	}
+	return reply[syscall.SizeofIfInfomsg:], nil
+}
@@ -0,0 +1,260 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	gonet "net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// The settings the physical function applies to its VFs are only exposed by
+// the rtnetlink link messages, as in `ip link show`, and the mode of the
+// embedded switch by devlink, as in `devlink dev eswitch show`. See
+// include/uapi/linux/if_link.h and include/uapi/linux/devlink.h.
+
+const (
+	iflaVFInfoList       = 22
+	iflaVFInfo           = 1
+	iflaVFMAC            = 1
+	iflaVFVLAN           = 2
+	iflaVFSpoofChk       = 4
+	iflaVFLinkState      = 5
+	iflaVFTrust          = 9
+	rtextFilterVF        = 1 << 0
+	rtextFilterSkipStats = 1 << 3
+
+	devlinkCmdEswitchGet   = 29
+	devlinkAttrBusName     = 1
+	devlinkAttrDevName     = 2
+	devlinkAttrEswitchMode = 25
+)
+
+var vfLinkStates = map[uint32]string{
+	0: "auto",
+	1: "enable",
+	2: "disable",
+}
+
+var eswitchModes = map[uint16]string{
+	0: "legacy",
+	1: "switchdev",
+}
+
+// parseLinkVFs returns the VFs, with the settings the physical function
+// applies to them, listed in the attributes of an RTM_NEWLINK message
+func parseLinkVFs(b []byte) ([]*NICVirtualFunction, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return nil, err
+	}
+	// the hardware address attributes of the VFs are 32 bytes long, of
+	// which only the length of the address of the PF is meaningful
+	macLen := 6
+	var list []byte
+	for _, a := range attrs {
+		switch a.typ {
+		case syscall.IFLA_ADDRESS:
+			macLen = len(a.data)
+		case iflaVFInfoList:
+			list = a.data
+		}
+	}
+	infos, err := parseNLAttrs(list)
+	if err != nil {
+		return nil, err
+	}
+	vfs := []*NICVirtualFunction{}
+	for _, info := range infos {
+		if info.typ != iflaVFInfo {
+			continue
+		}
+		vfAttrs, err := parseNLAttrs(info.data)
+		if err != nil {
+			return nil, err
+		}
+		vf := &NICVirtualFunction{Index: -1}
+		for _, a := range vfAttrs {
+			// all the VF attributes start with the index of the VF
+			if len(a.data) < 8 {
+				continue
+			}
+			vf.Index = int(binary.NativeEndian.Uint32(a.data[0:4]))
+			val := binary.NativeEndian.Uint32(a.data[4:8])
+			switch a.typ {
+			case iflaVFMAC:
+				if len(a.data) >= 4+macLen {
+					vf.MACAddress = vfMACAddress(a.data[4 : 4+macLen])
+				}
+			case iflaVFVLAN:
+				vf.VLAN = int(val)
+				if len(a.data) >= 12 {
+					vf.QoS = int(binary.NativeEndian.Uint32(a.data[8:12]))
+				}
+			case iflaVFSpoofChk:
+				// drivers lacking the setting report -1
+				vf.SpoofCheck = val == 1
+			case iflaVFTrust:
+				vf.Trust = val == 1
+			case iflaVFLinkState:
+				vf.LinkState = vfLinkStates[val]
+			}
+		}
+		if vf.Index >= 0 {
+			vfs = append(vfs, vf)
+		}
+	}
+	return vfs, nil
+}
+
+// vfMACAddress returns the supplied hardware address, or "" for the all-zero
+// address of a VF without an assigned address
+func vfMACAddress(b []byte) string {
+	for _, c := range b {
+		if c != 0 {
+			return gonet.HardwareAddr(b).String()
+		}
+	}
+	return ""
+}
+
+// parseEswitchMode returns the eswitch mode in the attributes of a devlink
+// eswitch reply
+func parseEswitchMode(b []byte) (string, error) {
+	attrs, err := parseNLAttrs(b)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range attrs {
+		if a.typ == devlinkAttrEswitchMode && len(a.data) >= 2 {
+			return eswitchModes[binary.NativeEndian.Uint16(a.data)], nil
+		}
+	}
+	return "", fmt.Errorf("no eswitch mode in devlink reply")
+}
+
+// vfs returns the VFs of the physical function of the supplied NIC, with
+// the settings it applies to them
+func (s *linkNetlink) vfs(dev string) ([]*NICVirtualFunction, error) {
+	reply, err := s.getLink(dev, rtextFilterVF|rtextFilterSkipStats)
+	if err != nil {
+		return nil, err
+	}
+	return parseLinkVFs(reply)
+}
+
+// eswitchMode returns the eswitch mode of the devlink device of the PCI
+// device at the supplied address
+func (s *linkNetlink) eswitchMode(pciAddress string) (string, error) {
+	if s.devlink == nil {
+		return "", fmt.Errorf("devlink unavailable")
+	}
+	attrs := appendNLAttr(nil, devlinkAttrBusName, nlString("pci"))
+	attrs = appendNLAttr(attrs, devlinkAttrDevName, nlString(pciAddress))
+	reply, err := s.devlink.request(devlinkCmdEswitchGet, attrs)
+	if err != nil {
+		return "", err
+	}
+	return parseEswitchMode(reply)
+}
+
+// firstNetDevice returns the name of the first NIC of the PCI device at the
+// supplied sysfs path, or "" if the device has none
+func firstNetDevice(devPath string) string {
+	entries, err := os.ReadDir(filepath.Join(devPath, "net"))
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+	return entries[0].Name()
+}
+
+// setNicSRIOV sets the SR-IOV physical and virtual function fields of the
+// NIC. The PCI core links the device of a PF to its VFs with virtfnN links
+// and the device of a VF to its PF with a physfn link, and exposes the
+// sriov_totalvfs and sriov_numvfs attributes for the PFs only.
+func (n *NIC) setNicSRIOV(
+	ctx context.Context,
+	paths *linuxpath.Paths,
+	native *linkNetlink,
+	dev string,
+) {
+	devPath := filepath.Join(paths.SysClassNet, dev, "device")
+	if dest, err := os.Readlink(filepath.Join(devPath, "physfn")); err == nil {
+		n.PhysFnPCIAddress = filepath.Base(dest)
+		n.PhysFn = firstNetDevice(filepath.Join(devPath, "physfn"))
+	}
+
+	totalVFs, err := strconv.Atoi(readFile(filepath.Join(devPath, "sriov_totalvfs")))
+	if err != nil {
+		return
+	}
+	s := &NICSRIOV{
+		TotalVFs:    totalVFs,
+		EswitchMode: readFile(filepath.Join(paths.SysClassNet, dev, "compat", "devlink", "mode")),
+		VFs:         []*NICVirtualFunction{},
+	}
+	s.NumVFs, _ = strconv.Atoi(readFile(filepath.Join(devPath, "sriov_numvfs")))
+	links, _ := filepath.Glob(filepath.Join(devPath, "virtfn*"))
+	for _, link := range links {
+		idx, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), "virtfn"))
+		if err != nil {
+			continue
+		}
+		dest, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		s.VFs = append(s.VFs, &NICVirtualFunction{
+			Index:      idx,
+			PCIAddress: filepath.Base(dest),
+			Name:       firstNetDevice(link),
+		})
+	}
+	sort.Slice(s.VFs, func(i, j int) bool {
+		return s.VFs[i].Index < s.VFs[j].Index
+	})
+	n.SRIOV = s
+
+	if native == nil {
+		return
+	}
+	if s.EswitchMode == "" && n.PCIAddress != nil {
+		mode, err := native.eswitchMode(*n.PCIAddress)
+		if err != nil {
+			log.Debug(ctx, "could not read the eswitch mode of %s: %s", dev, err)
+		}
+		s.EswitchMode = mode
+	}
+	settings, err := native.vfs(dev)
+	if err != nil {
+		log.Debug(ctx, "could not read the VF settings of %s: %s", dev, err)
+		return
+	}
+	byIndex := map[int]*NICVirtualFunction{}
+	for _, vf := range settings {
+		byIndex[vf.Index] = vf
+	}
+	for _, vf := range s.VFs {
+		if cfg, ok := byIndex[vf.Index]; ok {
+			vf.MACAddress = cfg.MACAddress
+			vf.VLAN = cfg.VLAN
+			vf.QoS = cfg.QoS
+			vf.SpoofCheck = cfg.SpoofCheck
+			vf.Trust = cfg.Trust
+			vf.LinkState = cfg.LinkState
+		}
+	}
+}
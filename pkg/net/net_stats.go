@@ -0,0 +1,180 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// NICStats contains the counters of a NIC, as kept by the kernel since the
+// NIC was created, as found in /sys/class/net/$DEVICE/statistics.
+type NICStats struct {
+	// Name is the name of the NIC, e.g. `eth0`.
+	Name string `json:"name"`
+	// RxBytes is the number of bytes received.
+	RxBytes uint64 `json:"rx_bytes"`
+	// RxPackets is the number of packets received.
+	RxPackets uint64 `json:"rx_packets"`
+	// RxErrors is the number of packets received with errors, of which the
+	// Rx*Errors counters below detail the causes.
+	RxErrors uint64 `json:"rx_errors"`
+	// RxDropped is the number of packets received but not handed to the
+	// network stack, e.g. for lack of buffers.
+	RxDropped uint64 `json:"rx_dropped"`
+	// RxMissedErrors is the number of packets the NIC dropped because the
+	// host did not pick them up in time.
+	RxMissedErrors uint64 `json:"rx_missed_errors"`
+	// RxOverErrors is the number of packets received over the size the NIC
+	// accepts.
+	RxOverErrors uint64 `json:"rx_over_errors"`
+	// RxCRCErrors is the number of packets received with a bad checksum.
+	RxCRCErrors uint64 `json:"rx_crc_errors"`
+	// RxFrameErrors is the number of packets received misaligned.
+	RxFrameErrors uint64 `json:"rx_frame_errors"`
+	// RxFIFOErrors is the number of packets lost to an overrun of the
+	// receive FIFO of the NIC.
+	RxFIFOErrors uint64 `json:"rx_fifo_errors"`
+	// RxLengthErrors is the number of packets received with an invalid
+	// length.
+	RxLengthErrors uint64 `json:"rx_length_errors"`
+	// RxCompressed is the number of compressed packets received, for the
+	// NICs supporting compression, e.g. PPP.
+	RxCompressed uint64 `json:"rx_compressed"`
+	// RxNoHandler is the number of packets received which no protocol
+	// handled, e.g. on an inactive slave of a bond.
+	RxNoHandler uint64 `json:"rx_nohandler"`
+	// Multicast is the number of multicast packets received.
+	Multicast uint64 `json:"multicast"`
+	// TxBytes is the number of bytes transmitted.
+	TxBytes uint64 `json:"tx_bytes"`
+	// TxPackets is the number of packets transmitted.
+	TxPackets uint64 `json:"tx_packets"`
+	// TxErrors is the number of packets which failed to be transmitted, of
+	// which the Tx*Errors counters below detail the causes.
+	TxErrors uint64 `json:"tx_errors"`
+	// TxDropped is the number of packets dropped before transmission, e.g.
+	// for lack of buffers.
+	TxDropped uint64 `json:"tx_dropped"`
+	// TxAbortedErrors is the number of transmissions aborted by the NIC.
+	TxAbortedErrors uint64 `json:"tx_aborted_errors"`
+	// TxCarrierErrors is the number of transmissions failed for loss of
+	// carrier.
+	TxCarrierErrors uint64 `json:"tx_carrier_errors"`
+	// TxFIFOErrors is the number of transmissions failed to an underrun of
+	// the transmit FIFO of the NIC.
+	TxFIFOErrors uint64 `json:"tx_fifo_errors"`
+	// TxHeartbeatErrors is the number of heartbeat (SQE test) failures, on
+	// the NICs reporting them.
+	TxHeartbeatErrors uint64 `json:"tx_heartbeat_errors"`
+	// TxWindowErrors is the number of late collisions.
+	TxWindowErrors uint64 `json:"tx_window_errors"`
+	// TxCompressed is the number of compressed packets transmitted.
+	TxCompressed uint64 `json:"tx_compressed"`
+	// Collisions is the number of collisions on half-duplex links.
+	Collisions uint64 `json:"collisions"`
+	// Driver is a map, keyed by name, of the counters the driver of the NIC
+	// keeps on top of the above, often per queue, as in `ethtool -S`. It is
+	// only read when ghw can query the kernel, i.e. not from a snapshot, and
+	// is nil for the NICs whose driver keeps none.
+	Driver map[string]uint64 `json:"driver,omitempty"`
+}
+
+// Stats contains a sample of the counters of the NICs of the host system.
+// Unlike Info, it describes how the NICs are used, not what they are, and is
+// meant to be sampled repeatedly and fed to Rates.
+type Stats struct {
+	// Timestamp is the time the sample was taken at
+	Timestamp time.Time `json:"timestamp"`
+	// NICs contains an array of pointers to `NICStats` structs, one for each
+	// NIC
+	NICs []*NICStats `json:"nics"`
+}
+
+// NewStats returns a pointer to a Stats struct containing the current
+// counters of the NICs of the host system.
+func NewStats(args ...any) (*Stats, error) {
+	ctx := config.ContextFromArgs(args...)
+	stats := &Stats{}
+	if err := stats.load(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// NIC returns the counters of the NIC with the supplied name, or nil if there
+// is none
+func (s *Stats) NIC(name string) *NICStats {
+	for _, n := range s.NICs {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// NICRates contains the throughput and error rates of a NIC, per second,
+// over the interval between two Stats samples.
+type NICRates struct {
+	// Name is the name of the NIC, e.g. `eth0`.
+	Name string `json:"name"`
+	// RxBytesPerSecond is the number of bytes received per second.
+	RxBytesPerSecond float64 `json:"rx_bytes_per_second"`
+	// RxPacketsPerSecond is the number of packets received per second.
+	RxPacketsPerSecond float64 `json:"rx_packets_per_second"`
+	// RxErrorsPerSecond is the number of receive errors per second.
+	RxErrorsPerSecond float64 `json:"rx_errors_per_second"`
+	// RxDroppedPerSecond is the number of received packets dropped per second.
+	RxDroppedPerSecond float64 `json:"rx_dropped_per_second"`
+	// TxBytesPerSecond is the number of bytes transmitted per second.
+	TxBytesPerSecond float64 `json:"tx_bytes_per_second"`
+	// TxPacketsPerSecond is the number of packets transmitted per second.
+	TxPacketsPerSecond float64 `json:"tx_packets_per_second"`
+	// TxErrorsPerSecond is the number of transmit errors per second.
+	TxErrorsPerSecond float64 `json:"tx_errors_per_second"`
+	// TxDroppedPerSecond is the number of packets dropped before transmission
+	// per second.
+	TxDroppedPerSecond float64 `json:"tx_dropped_per_second"`
+}
+
+// Rates returns the rates of the NICs between two samples, for the NICs
+// found in both samples, in the order of the current sample. A counter which
+// went backwards in between counts as zero, see util.CounterDelta.
+func Rates(prev *Stats, cur *Stats) ([]*NICRates, error) {
+	if prev == nil || cur == nil {
+		return nil, errors.New("two samples are needed to compute rates")
+	}
+	interval := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	if interval <= 0 {
+		return nil, errors.New("the current sample must be taken after the previous one")
+	}
+	perSecond := func(prev uint64, cur uint64) float64 {
+		return float64(util.CounterDelta(prev, cur)) / interval
+	}
+	rates := make([]*NICRates, 0, len(cur.NICs))
+	for _, c := range cur.NICs {
+		p := prev.NIC(c.Name)
+		if p == nil {
+			continue
+		}
+		rates = append(rates, &NICRates{
+			Name:               c.Name,
+			RxBytesPerSecond:   perSecond(p.RxBytes, c.RxBytes),
+			RxPacketsPerSecond: perSecond(p.RxPackets, c.RxPackets),
+			RxErrorsPerSecond:  perSecond(p.RxErrors, c.RxErrors),
+			RxDroppedPerSecond: perSecond(p.RxDropped, c.RxDropped),
+			TxBytesPerSecond:   perSecond(p.TxBytes, c.TxBytes),
+			TxPacketsPerSecond: perSecond(p.TxPackets, c.TxPackets),
+			TxErrorsPerSecond:  perSecond(p.TxErrors, c.TxErrors),
+			TxDroppedPerSecond: perSecond(p.TxDropped, c.TxDropped),
+		})
+	}
+	return rates, nil
+}
@@ -0,0 +1,160 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// The kernel exposes the standard counters of every NIC, those of struct
+// rtnl_link_stats64, as files of /sys/class/net/$DEVICE/statistics. The
+// counters specific to the driver are only reported by the ETHTOOL_GSTATS
+// ioctl, named by the strings of the ETH_SS_STATS string set, as in
+// `ethtool -S`. See include/uapi/linux/if_link.h and
+// include/uapi/linux/ethtool.h.
+
+const (
+	ethtoolGStats          = 0x1d
+	ethtoolStringSetStats  = 1
+	ethtoolStatsHdrLen     = 8
+	ethtoolStatsCounterLen = 8
+)
+
+// statsFields returns the fields of the supplied NICStats, keyed by the name
+// of the sysfs statistics file holding their value
+func statsFields(s *NICStats) map[string]*uint64 {
+	return map[string]*uint64{
+		"rx_bytes":            &s.RxBytes,
+		"rx_packets":          &s.RxPackets,
+		"rx_errors":           &s.RxErrors,
+		"rx_dropped":          &s.RxDropped,
+		"rx_missed_errors":    &s.RxMissedErrors,
+		"rx_over_errors":      &s.RxOverErrors,
+		"rx_crc_errors":       &s.RxCRCErrors,
+		"rx_frame_errors":     &s.RxFrameErrors,
+		"rx_fifo_errors":      &s.RxFIFOErrors,
+		"rx_length_errors":    &s.RxLengthErrors,
+		"rx_compressed":       &s.RxCompressed,
+		"rx_nohandler":        &s.RxNoHandler,
+		"multicast":           &s.Multicast,
+		"tx_bytes":            &s.TxBytes,
+		"tx_packets":          &s.TxPackets,
+		"tx_errors":           &s.TxErrors,
+		"tx_dropped":          &s.TxDropped,
+		"tx_aborted_errors":   &s.TxAbortedErrors,
+		"tx_carrier_errors":   &s.TxCarrierErrors,
+		"tx_fifo_errors":      &s.TxFIFOErrors,
+		"tx_heartbeat_errors": &s.TxHeartbeatErrors,
+		"tx_window_errors":    &s.TxWindowErrors,
+		"tx_compressed":       &s.TxCompressed,
+		"collisions":          &s.Collisions,
+	}
+}
+
+// sysfsNICStats returns the counters in the supplied sysfs statistics
+// directory. The counters missing from the directory, as those older kernels
+// lack, are left at zero.
+func sysfsNICStats(statsPath string) (*NICStats, error) {
+	if _, err := os.Stat(statsPath); err != nil {
+		return nil, err
+	}
+	s := &NICStats{}
+	for name, field := range statsFields(s) {
+		if v, err := strconv.ParseUint(readFile(filepath.Join(statsPath, name)), 10, 64); err == nil {
+			*field = v
+		}
+	}
+	return s, nil
+}
+
+// parseEthtoolStats returns the counters of a struct ethtool_stats, keyed by
+// the supplied names
+func parseEthtoolStats(b []byte, names []string) (map[string]uint64, error) {
+	if len(b) < ethtoolStatsHdrLen {
+		return nil, fmt.Errorf("short ethtool_stats")
+	}
+	count := int(binary.NativeEndian.Uint32(b[4:8]))
+	if count > len(names) || len(b) < ethtoolStatsHdrLen+count*ethtoolStatsCounterLen {
+		return nil, fmt.Errorf("%d driver counters reported, expected at most %d", count, len(names))
+	}
+	stats := make(map[string]uint64, count)
+	for x := 0; x < count; x++ {
+		off := ethtoolStatsHdrLen + x*ethtoolStatsCounterLen
+		stats[names[x]] = binary.NativeEndian.Uint64(b[off : off+ethtoolStatsCounterLen])
+	}
+	return stats, nil
+}
+
+// stats returns the counters the driver of the device keeps, keyed by name
+func (c *ethtoolIoctl) stats(dev string) (map[string]uint64, error) {
+	names, err := c.stringSet(dev, ethtoolStringSetStats)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	// struct ethtool_stats
+	b := make([]byte, ethtoolStatsHdrLen+len(names)*ethtoolStatsCounterLen)
+	binary.NativeEndian.PutUint32(b[0:4], ethtoolGStats)
+	binary.NativeEndian.PutUint32(b[4:8], uint32(len(names)))
+	if err := c.do(dev, b); err != nil {
+		return nil, err
+	}
+	return parseEthtoolStats(b, names)
+}
+
+// load reads the counters of the NICs from the sysfs tree of the supplied
+// context, along with the counters of their drivers when examining the live
+// system
+func (s *Stats) load(ctx context.Context) error {
+	paths := linuxpath.New(ctx)
+	entries, err := os.ReadDir(paths.SysClassNet)
+	if err != nil {
+		return err
+	}
+	var ioctl *ethtoolIoctl
+	if config.Chroot(ctx) == "/" {
+		if ioctl, err = newEthtoolIoctl(); err != nil {
+			log.Debug(ctx, "cannot query the driver statistics of the NICs: %s", err)
+			ioctl = nil
+		} else {
+			defer ioctl.close()
+		}
+	}
+	s.Timestamp = time.Now()
+	s.NICs = []*NICStats{}
+	for _, entry := range entries {
+		name := entry.Name()
+		// the same NICs as in Info
+		if name == "lo" || name == "bonding_masters" {
+			continue
+		}
+		ns, err := sysfsNICStats(filepath.Join(paths.SysClassNet, name, "statistics"))
+		if err != nil {
+			log.Debug(ctx, "failed to read the statistics of %s: %s", name, err)
+			continue
+		}
+		ns.Name = name
+		if ioctl != nil {
+			if ns.Driver, err = ioctl.stats(name); err != nil {
+				log.Debug(ctx, "could not read the driver statistics of %s: %s", name, err)
+			}
+		}
+		s.NICs = append(s.NICs, ns)
+	}
+	return nil
+}
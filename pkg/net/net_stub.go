@@ -17,3 +17,7 @@ import (
 func (i *Info) load(ctx context.Context) error {
 	return errors.New("netFillInfo not implemented on " + runtime.GOOS)
 }
+
+func (s *Stats) load(ctx context.Context) error {
+	return errors.New("NIC statistics not implemented on " + runtime.GOOS)
+}
@@ -7,6 +7,7 @@ package net
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/yusufpapurcu/wmi"
@@ -73,3 +74,7 @@ func netIsVirtual(description win32NetworkAdapter) bool {
 
 	return !(*description.PhysicalAdapter)
 }
+
+func (s *Stats) load(ctx context.Context) error {
+	return errors.New("NIC statistics not implemented on windows")
+}
@@ -45,6 +45,12 @@ type Device struct {
 	// Useful for callers that want to do custom vendor/device/class matching
 	// beyond what the pcidb lookup provides.
 	Modalias string `json:"modalias,omitempty"`
+	// SRIOV describes the SR-IOV capability of the device, when the device
+	// is an SR-IOV physical function (PF). It is nil otherwise.
+	SRIOV *SRIOV `json:"sriov,omitempty"`
+	// PhysFnAddress is the PCI address of the physical function of the
+	// device, when the device is an SR-IOV virtual function (VF).
+	PhysFnAddress string `json:"physfn_address,omitempty"`
 
 	// Parent is the resolved parent Device pointer for this device (the
 	// PCIe upstream port or root complex device). It is nil for root
@@ -55,6 +61,40 @@ type Device struct {
 	// no particular order. Populated after enumeration; not included in
 	// JSON output.
 	Children []*Device `json:"-"`
+	// PhysFn is the resolved Device pointer for PhysFnAddress, or nil if the
+	// device is not a VF. Not included in JSON output.
+	PhysFn *Device `json:"-"`
+}
+
+// SRIOV describes the Single Root I/O Virtualization (SR-IOV) capability of
+// a PCI physical function (PF), which can expose a number of lightweight
+// virtual functions (VFs), each one a PCI device of its own.
+type SRIOV struct {
+	// TotalVFs is the maximum number of VFs the PF supports
+	TotalVFs int `json:"total_vfs"`
+	// NumVFs is the number of VFs currently enabled
+	NumVFs int `json:"num_vfs"`
+	// VFs contains the enabled VFs, ordered by index
+	VFs []*VirtualFunction `json:"vfs"`
+	// EswitchMode is the mode of the embedded switch of the PF, "legacy" or
+	// "switchdev", for the devices whose network driver reports it through
+	// sysfs. It is empty otherwise.
+	EswitchMode string `json:"eswitch_mode,omitempty"`
+}
+
+// VirtualFunction is an SR-IOV virtual function (VF) of a physical function
+type VirtualFunction struct {
+	// Index is the index of the VF within its PF, N for the virtfnN link
+	Index int `json:"index"`
+	// Address is the PCI address of the VF
+	Address string `json:"address"`
+	// Device is the resolved Device pointer for the VF. Not included in
+	// JSON output.
+	Device *Device `json:"-"`
+}
+
+func (s *SRIOV) String() string {
+	return fmt.Sprintf("SR-IOV (%d/%d VFs)", s.NumVFs, s.TotalVFs)
 }
 
 type devIdent struct {
@@ -75,6 +115,8 @@ type devMarshallable struct {
 	Interface     devIdent `json:"programming_interface"`
 	IOMMUGroup    string   `json:"iommu_group"`
 	Modalias      string   `json:"modalias,omitempty"`
+	SRIOV         *SRIOV   `json:"sriov,omitempty"`
+	PhysFnAddress string   `json:"physfn_address,omitempty"`
 }
 
 // NOTE(jaypipes) Device has a custom JSON marshaller because we don't want
@@ -111,8 +153,10 @@ func (d *Device) MarshalJSON() ([]byte, error) {
 			ID:   d.ProgrammingInterface.ID,
 			Name: d.ProgrammingInterface.Name,
 		},
-		IOMMUGroup: d.IOMMUGroup,
-		Modalias:   d.Modalias,
+		IOMMUGroup:    d.IOMMUGroup,
+		Modalias:      d.Modalias,
+		SRIOV:         d.SRIOV,
+		PhysFnAddress: d.PhysFnAddress,
 	}
 	return json.Marshal(dm)
 }
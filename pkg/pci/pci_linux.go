@@ -13,6 +13,7 @@ import (
 
 	"github.com/jaypipes/pcidb"
 
+	"github.com/jaypipes/ghw/internal/config"
 	"github.com/jaypipes/ghw/internal/log"
 	"github.com/jaypipes/ghw/pkg/linuxpath"
 	pciaddr "github.com/jaypipes/ghw/pkg/pci/address"
@@ -30,6 +31,9 @@ func (i *Info) load(ctx context.Context) error {
 	if path := os.Getenv("PCIDB_PATH"); path != "" {
 		pcidbOpt = pcidb.WithPath(path)
 	}
+	if i.db == nil {
+		i.db = config.PCIDB(ctx)
+	}
 	if i.db == nil {
 		db, err := pcidb.New(pcidbOpt)
 		if err != nil {
@@ -80,7 +84,8 @@ func getDeviceNUMANode(ctx context.Context, pciAddr *pciaddr.Address) *topology.
 	}
 
 	return &topology.Node{
-		ID: nodeIdx,
+		ID:         nodeIdx,
+		MemoryTier: -1,
 	}
 }
 
@@ -411,6 +416,8 @@ func (info *Info) getDevices(ctx context.Context) []*Device {
 		device.ParentAddress = getDeviceParentAddress(paths, pciAddr)
 		device.IOMMUGroup = getDeviceIommuGroup(paths, pciAddr)
 		device.Modalias = modalias
+		device.SRIOV = getDeviceSRIOV(paths, pciAddr)
+		device.PhysFnAddress = getDevicePhysFnAddress(paths, pciAddr)
 		devs = append(devs, device)
 	}
 	linkDeviceTree(devs)
@@ -419,7 +426,8 @@ func (info *Info) getDevices(ctx context.Context) []*Device {
 
 // linkDeviceTree resolves ParentAddress strings into Parent pointers and
 // fills in each device's Children list. Devices with no matching parent
-// in the slice (e.g. root complex devices) keep Parent == nil.
+// in the slice (e.g. root complex devices) keep Parent == nil. The SR-IOV
+// links between physical and virtual functions are resolved as well.
 func linkDeviceTree(devs []*Device) {
 	if len(devs) == 0 {
 		return
@@ -439,4 +447,5 @@ func linkDeviceTree(devs []*Device) {
 		d.Parent = parent
 		parent.Children = append(parent.Children, d)
 	}
+	linkSRIOV(byAddr)
 }
@@ -0,0 +1,107 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	pciaddr "github.com/jaypipes/ghw/pkg/pci/address"
+)
+
+// getDeviceSRIOV returns the SR-IOV capability of the device, or nil if the
+// device is not an SR-IOV physical function. The PCI core exposes the
+// sriov_totalvfs and sriov_numvfs attributes for the PFs only, along with a
+// virtfnN link to each enabled VF.
+func getDeviceSRIOV(paths *linuxpath.Paths, pciAddr *pciaddr.Address) *SRIOV {
+	devPath := filepath.Join(paths.SysBusPciDevices, pciAddr.String())
+	totalVFs, err := readIntFile(filepath.Join(devPath, "sriov_totalvfs"))
+	if err != nil {
+		return nil
+	}
+	s := &SRIOV{
+		TotalVFs:    totalVFs,
+		VFs:         []*VirtualFunction{},
+		EswitchMode: getDeviceEswitchMode(devPath),
+	}
+	s.NumVFs, _ = readIntFile(filepath.Join(devPath, "sriov_numvfs"))
+
+	links, _ := filepath.Glob(filepath.Join(devPath, "virtfn*"))
+	for _, link := range links {
+		idx, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), "virtfn"))
+		if err != nil {
+			continue
+		}
+		dest, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		s.VFs = append(s.VFs, &VirtualFunction{
+			Index:   idx,
+			Address: filepath.Base(dest),
+		})
+	}
+	sort.Slice(s.VFs, func(i, j int) bool {
+		return s.VFs[i].Index < s.VFs[j].Index
+	})
+	return s
+}
+
+// getDeviceEswitchMode returns the mode of the embedded switch of a PF, as
+// reported by the compat/devlink/mode attribute some network drivers, e.g.
+// mlx5, expose under the netdevs of the PF. Devices whose drivers only
+// report it through devlink have no eswitch mode here.
+func getDeviceEswitchMode(devPath string) string {
+	modes, _ := filepath.Glob(filepath.Join(devPath, "net", "*", "compat", "devlink", "mode"))
+	for _, path := range modes {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if mode := strings.TrimSpace(string(data)); mode != "" {
+			return mode
+		}
+	}
+	return ""
+}
+
+// getDevicePhysFnAddress returns the PCI address of the physical function of
+// the device, or "" if the device is not an SR-IOV virtual function
+func getDevicePhysFnAddress(paths *linuxpath.Paths, pciAddr *pciaddr.Address) string {
+	dest, err := os.Readlink(filepath.Join(paths.SysBusPciDevices, pciAddr.String(), "physfn"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dest)
+}
+
+// linkSRIOV resolves the VFs of the physical functions and the PhysFnAddress
+// of the virtual functions into Device pointers
+func linkSRIOV(byAddr map[string]*Device) {
+	for _, d := range byAddr {
+		if d.PhysFnAddress != "" {
+			d.PhysFn = byAddr[d.PhysFnAddress]
+		}
+		if d.SRIOV == nil {
+			continue
+		}
+		for _, vf := range d.SRIOV.VFs {
+			vf.Device = byAddr[vf.Address]
+		}
+	}
+}
+
+func readIntFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
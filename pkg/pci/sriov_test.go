@@ -0,0 +1,90 @@
+//go:build linux
+// +build linux
+
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/option"
+	pciaddr "github.com/jaypipes/ghw/pkg/pci/address"
+)
+
+// TestDeviceSRIOV builds a sysfs tree with a physical function having two
+// VFs enabled out of eight, and checks the links between them are resolved
+// both ways.
+func TestDeviceSRIOV(t *testing.T) {
+	chroot := t.TempDir()
+	const pf = "0000:3b:00.0"
+	vfs := []string{"0000:3b:02.0", "0000:3b:02.1"}
+	busDir := filepath.Join(chroot, "sys", "devices", "pci0000:3a", "0000:3a:00.0")
+	devsDir := filepath.Join(chroot, "sys", "bus", "pci", "devices")
+	for _, addr := range append([]string{pf}, vfs...) {
+		if err := os.MkdirAll(filepath.Join(busDir, addr), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(devsDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		rel, _ := filepath.Rel(devsDir, filepath.Join(busDir, addr))
+		if err := os.Symlink(rel, filepath.Join(devsDir, addr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pfDir := filepath.Join(busDir, pf)
+	_ = os.WriteFile(filepath.Join(pfDir, "sriov_totalvfs"), []byte("8\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(pfDir, "sriov_numvfs"), []byte("2\n"), 0o644)
+	modeDir := filepath.Join(pfDir, "net", "ens1f0", "compat", "devlink")
+	_ = os.MkdirAll(modeDir, 0o755)
+	_ = os.WriteFile(filepath.Join(modeDir, "mode"), []byte("switchdev\n"), 0o644)
+	// virtfn10 sorts before virtfn2 in the directory listing
+	for i, vf := range vfs {
+		idx := []string{"2", "10"}[i]
+		_ = os.Symlink(filepath.Join("..", vf), filepath.Join(pfDir, "virtfn"+idx))
+		_ = os.Symlink(filepath.Join("..", pf), filepath.Join(busDir, vf, "physfn"))
+	}
+
+	ctx := config.ContextFromArgs(option.WithChroot(chroot))
+	paths := linuxpath.New(ctx)
+	devs := []*Device{}
+	for _, addr := range append([]string{pf}, vfs...) {
+		pciAddr := pciaddr.FromString(addr)
+		devs = append(devs, &Device{
+			Address:       addr,
+			SRIOV:         getDeviceSRIOV(paths, pciAddr),
+			PhysFnAddress: getDevicePhysFnAddress(paths, pciAddr),
+		})
+	}
+	linkDeviceTree(devs)
+
+	s := devs[0].SRIOV
+	if s == nil || s.TotalVFs != 8 || s.NumVFs != 2 || s.EswitchMode != "switchdev" {
+		t.Fatalf("Expected 2/8 VFs in switchdev mode, but got %+v", s)
+	}
+	if len(s.VFs) != 2 || s.VFs[0].Index != 2 || s.VFs[1].Index != 10 {
+		t.Fatalf("Expected VFs 2 and 10, but got %+v", s.VFs)
+	}
+	for i, vf := range s.VFs {
+		if vf.Address != vfs[i] || vf.Device != devs[i+1] {
+			t.Fatalf("Expected VF %d at %s, but got %+v", vf.Index, vfs[i], vf)
+		}
+	}
+	for _, d := range devs[1:] {
+		if d.SRIOV != nil || d.PhysFnAddress != pf || d.PhysFn != devs[0] {
+			t.Fatalf("Expected %s to be a VF of %s, but got %+v", d.Address, pf, d)
+		}
+	}
+	if devs[0].PhysFnAddress != "" || devs[0].PhysFn != nil {
+		t.Fatalf("Expected the PF to have no physical function, but got %q", devs[0].PhysFnAddress)
+	}
+}
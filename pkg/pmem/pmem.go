@@ -0,0 +1,379 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pmem
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/marshal"
+	"github.com/jaypipes/ghw/pkg/pci"
+	"github.com/jaypipes/ghw/pkg/topology"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+)
+
+// NamespaceMode describes how the capacity of a persistent memory namespace
+// is exposed to the operating system.
+type NamespaceMode int
+
+const (
+	// NamespaceModeUnknown indicates the mode could not be determined.
+	NamespaceModeUnknown NamespaceMode = iota
+	// NamespaceModeRaw exposes the namespace as a plain block device without
+	// DAX support.
+	NamespaceModeRaw
+	// NamespaceModeSector exposes the namespace as a block device with
+	// atomic sector updates (BTT).
+	NamespaceModeSector
+	// NamespaceModeFSDAX exposes the namespace as a block device supporting
+	// DAX filesystems.
+	NamespaceModeFSDAX
+	// NamespaceModeDevDAX exposes the namespace as a character device, which
+	// may also be onlined as system RAM.
+	NamespaceModeDevDAX
+)
+
+var (
+	namespaceModeString = map[NamespaceMode]string{
+		NamespaceModeUnknown: "Unknown",
+		NamespaceModeRaw:     "Raw",
+		NamespaceModeSector:  "Sector",
+		NamespaceModeFSDAX:   "FSDAX",
+		NamespaceModeDevDAX:  "DevDAX",
+	}
+
+	// NOTE(fromani): the keys are all lowercase and do not match
+	// the keys in the opposite table `namespaceModeString`.
+	// This is done because of the choice we made in
+	// NamespaceMode:MarshalJSON.
+	// We use this table only in UnmarshalJSON, so it should be OK.
+	stringNamespaceMode = map[string]NamespaceMode{
+		"unknown": NamespaceModeUnknown,
+		"raw":     NamespaceModeRaw,
+		"sector":  NamespaceModeSector,
+		"fsdax":   NamespaceModeFSDAX,
+		"devdax":  NamespaceModeDevDAX,
+	}
+)
+
+func (m NamespaceMode) String() string {
+	return namespaceModeString[m]
+}
+
+// NOTE(jaypipes): since serialized output is as "official" as we're going to
+// get, let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (m NamespaceMode) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(m.String()))), nil
+}
+
+func (m *NamespaceMode) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringNamespaceMode[key]
+	if !ok {
+		return fmt.Errorf("unknown namespace mode: %q", key)
+	}
+	*m = val
+	return nil
+}
+
+// Namespace describes a persistent memory namespace, the unit of capacity of
+// a region that is exposed to the operating system as a device.
+type Namespace struct {
+	// Name is the kernel's name for the namespace, e.g. "namespace0.0"
+	Name      string        `json:"name"`
+	Mode      NamespaceMode `json:"mode"`
+	SizeBytes uint64        `json:"size_bytes"`
+	UUID      string        `json:"uuid,omitempty"`
+	// BlockDevice is the name of the namespace's block device, e.g. "pmem0",
+	// in raw, sector and fsdax modes
+	BlockDevice string `json:"block_device,omitempty"`
+	// CharDevice is the name of the namespace's character device, e.g.
+	// "dax0.0", in devdax mode
+	CharDevice string `json:"char_device,omitempty"`
+}
+
+func (ns *Namespace) String() string {
+	dev := ns.BlockDevice
+	if dev == "" {
+		dev = ns.CharDevice
+	}
+	if dev != "" {
+		dev = " /dev/" + dev
+	}
+	return fmt.Sprintf(
+		"%s %s (%s)%s",
+		ns.Name,
+		strings.ToLower(ns.Mode.String()),
+		sizeString(ns.SizeBytes),
+		dev,
+	)
+}
+
+// NVDIMM describes a non-volatile memory module (an "nmem" device).
+type NVDIMM struct {
+	// Name is the kernel's name for the module, e.g. "nmem0"
+	Name   string `json:"name"`
+	Vendor string `json:"vendor,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	// Flags contains the health flags the firmware reports for the module,
+	// e.g. "not_armed" or "save_fail". Empty for healthy modules.
+	Flags []string `json:"flags,omitempty"`
+	// State is "active" when the module is used by a region, "idle"
+	// otherwise
+	State string `json:"state,omitempty"`
+}
+
+// Region describes a persistent memory region: a contiguous, possibly
+// interleaved, range of NVDIMM or CXL memory capacity.
+type Region struct {
+	// Name is the kernel's name for the region, e.g. "region0"
+	Name string `json:"name"`
+	// Type is "pmem" for persistent memory regions, "volatile" for volatile
+	// regions (e.g. CXL RAM emulating NVDIMMs) and "blk" for the regions of
+	// legacy block-window NVDIMMs
+	Type           string `json:"type"`
+	SizeBytes      uint64 `json:"size_bytes"`
+	AvailableBytes uint64 `json:"available_bytes"`
+	// PersistenceDomain is "cpu_cache" or "memory_controller" depending on
+	// where writes are guaranteed to become persistent
+	PersistenceDomain string `json:"persistence_domain,omitempty"`
+	// NodeID is the NUMA node closest to the region, or -1 if unknown
+	NodeID int `json:"node_id"`
+	// TargetNodeID is the NUMA node the region's memory is onlined into
+	// when used as system RAM, or -1 if unknown
+	TargetNodeID int `json:"target_node_id"`
+	// Node and TargetNode point to the topology nodes matching NodeID and
+	// TargetNodeID. They are nil on non-NUMA systems, when topology
+	// detection is disabled, or when the region's memory is not online.
+	Node       *topology.Node `json:"-"`
+	TargetNode *topology.Node `json:"-"`
+	// DIMMs contains the names of the NVDIMMs the region is interleaved
+	// across
+	DIMMs      []string     `json:"dimms"`
+	Namespaces []*Namespace `json:"namespaces"`
+}
+
+func (r *Region) String() string {
+	return fmt.Sprintf(
+		"%s %s (%s, %d namespaces)",
+		r.Name,
+		r.Type,
+		sizeString(r.SizeBytes),
+		len(r.Namespaces),
+	)
+}
+
+// CXLPortType indicates the position of a CXL port, or of the decoders of
+// the port, in the CXL hierarchy.
+type CXLPortType int
+
+const (
+	// CXLPortTypeUnknown indicates the type could not be determined.
+	CXLPortTypeUnknown CXLPortType = iota
+	// CXLPortTypeRoot is the platform's CXL root, whose decoders describe
+	// the host physical address ranges routed to CXL host bridges.
+	CXLPortTypeRoot
+	// CXLPortTypeSwitch is a CXL host bridge or switch port.
+	CXLPortTypeSwitch
+	// CXLPortTypeEndpoint is the port of a CXL memory device.
+	CXLPortTypeEndpoint
+)
+
+var (
+	cxlPortTypeString = map[CXLPortType]string{
+		CXLPortTypeUnknown:  "Unknown",
+		CXLPortTypeRoot:     "Root",
+		CXLPortTypeSwitch:   "Switch",
+		CXLPortTypeEndpoint: "Endpoint",
+	}
+
+	// lowercase keys, see stringNamespaceMode
+	stringCXLPortType = map[string]CXLPortType{
+		"unknown":  CXLPortTypeUnknown,
+		"root":     CXLPortTypeRoot,
+		"switch":   CXLPortTypeSwitch,
+		"endpoint": CXLPortTypeEndpoint,
+	}
+)
+
+func (t CXLPortType) String() string {
+	return cxlPortTypeString[t]
+}
+
+func (t CXLPortType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(t.String()))), nil
+}
+
+func (t *CXLPortType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringCXLPortType[key]
+	if !ok {
+		return fmt.Errorf("unknown CXL port type: %q", key)
+	}
+	*t = val
+	return nil
+}
+
+// CXLMemoryDevice describes a CXL memory device ("memdev"), e.g. a CXL
+// memory expander.
+type CXLMemoryDevice struct {
+	// Name is the kernel's name for the device, e.g. "mem0"
+	Name            string `json:"name"`
+	Serial          string `json:"serial,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	// RAMSizeBytes and PMEMSizeBytes are the device's volatile and persistent
+	// capacities
+	RAMSizeBytes  uint64 `json:"ram_size_bytes"`
+	PMEMSizeBytes uint64 `json:"pmem_size_bytes"`
+	// Address is the PCI address of the device
+	Address string `json:"address"`
+	// PCI points to the PCI device information of the device, or is nil if
+	// unavailable
+	PCI *pci.Device `json:"pci,omitempty"`
+	// NodeID is the NUMA node the device is attached to, or -1 if unknown.
+	// Note that the device's memory is usually onlined into a separate,
+	// CPU-less node.
+	NodeID int `json:"node_id"`
+	// Node points to the topology node matching NodeID, or is nil
+	Node *topology.Node `json:"-"`
+}
+
+func (d *CXLMemoryDevice) String() string {
+	return fmt.Sprintf(
+		"%s@%s (%s ram, %s pmem)",
+		d.Name,
+		d.Address,
+		sizeString(d.RAMSizeBytes),
+		sizeString(d.PMEMSizeBytes),
+	)
+}
+
+// CXLPort describes a port of the CXL hierarchy: the root, a host bridge or
+// switch, or the endpoint of a memory device.
+type CXLPort struct {
+	// Name is the kernel's name for the port, e.g. "root0", "port1" or
+	// "endpoint2"
+	Name string      `json:"name"`
+	Type CXLPortType `json:"type"`
+	// Parent is the name of the parent port, empty for the root
+	Parent string `json:"parent,omitempty"`
+	// UpstreamDevice is the name of the device implementing the port: the
+	// ACPI name of a host bridge, the PCI address of a switch or the memory
+	// device of an endpoint
+	UpstreamDevice string `json:"upstream_device,omitempty"`
+	// DownstreamPorts contains the names of the port's downstream ports, e.g.
+	// the PCI addresses of the root ports of a host bridge
+	DownstreamPorts []string `json:"downstream_ports,omitempty"`
+	// MemoryDevice is the name of the memory device of an endpoint port
+	MemoryDevice string `json:"memory_device,omitempty"`
+}
+
+// CXLDecoder describes an HDM (Host-managed Device Memory) decoder, which
+// routes a range of host physical addresses to the targets below a port.
+type CXLDecoder struct {
+	// Name is the kernel's name for the decoder, e.g. "decoder0.0"
+	Name string `json:"name"`
+	// Port is the name of the port the decoder belongs to
+	Port         string      `json:"port"`
+	Type         CXLPortType `json:"type"`
+	StartAddress uint64      `json:"start_address"`
+	SizeBytes    uint64      `json:"size_bytes"`
+	// InterleaveWays is the number of targets the range is interleaved
+	// across and InterleaveGranularity the size, in bytes, of each
+	// interleaved chunk
+	InterleaveWays        int `json:"interleave_ways"`
+	InterleaveGranularity int `json:"interleave_granularity"`
+	// Targets contains the IDs of the downstream ports targeted by the
+	// decoder
+	Targets []string `json:"targets,omitempty"`
+	// Mode is "ram", "pmem" or "none" for endpoint decoders
+	Mode string `json:"mode,omitempty"`
+	// Region is the name of the CXL region using the decoder, if any
+	Region string `json:"region,omitempty"`
+}
+
+// sizeString returns a short human-readable representation of a size in
+// bytes, e.g. "16GB"
+func sizeString(size uint64) string {
+	if size == 0 {
+		return "0"
+	}
+	unit, unitStr := unitutil.AmountString(int64(size))
+	return fmt.Sprintf("%d%s", int64(math.Ceil(float64(size)/float64(unit))), unitStr)
+}
+
+// Info describes the persistent memory and CXL memory devices of the host
+// system.
+type Info struct {
+	Regions          []*Region          `json:"regions"`
+	DIMMs            []*NVDIMM          `json:"dimms"`
+	CXLMemoryDevices []*CXLMemoryDevice `json:"cxl_memory_devices"`
+	CXLPorts         []*CXLPort         `json:"cxl_ports"`
+	CXLDecoders      []*CXLDecoder      `json:"cxl_decoders"`
+}
+
+func (i *Info) String() string {
+	namespaces := 0
+	for _, r := range i.Regions {
+		namespaces += len(r.Namespaces)
+	}
+	return fmt.Sprintf(
+		"pmem (%d regions, %d namespaces, %d NVDIMMs, %d CXL memory devices)",
+		len(i.Regions),
+		namespaces,
+		len(i.DIMMs),
+		len(i.CXLMemoryDevices),
+	)
+}
+
+// New returns a pointer to an Info struct that contains information about
+// the persistent memory and CXL memory devices on the host system
+func New(args ...any) (*Info, error) {
+	ctx := config.ContextFromArgs(args...)
+	info := &Info{
+		Regions:          []*Region{},
+		DIMMs:            []*NVDIMM{},
+		CXLMemoryDevices: []*CXLMemoryDevice{},
+		CXLPorts:         []*CXLPort{},
+		CXLDecoders:      []*CXLDecoder{},
+	}
+	if err := info.load(ctx); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// simple private struct used to encapsulate persistent memory information in
+// a top-level "pmem" YAML/JSON map/object key
+type pmemPrinter struct {
+	Info *Info `json:"pmem"`
+}
+
+// YAMLString returns a string with the persistent memory information
+// formatted as YAML under a top-level "pmem:" key
+func (i *Info) YAMLString() string {
+	return marshal.SafeYAML(pmemPrinter{i})
+}
+
+// JSONString returns a string with the persistent memory information
+// formatted as JSON under a top-level "pmem:" key
+func (i *Info) JSONString(indent bool) string {
+	return marshal.SafeJSON(pmemPrinter{i}, indent)
+}
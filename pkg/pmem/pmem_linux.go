@@ -0,0 +1,366 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pmem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/pci"
+	pciaddr "github.com/jaypipes/ghw/pkg/pci/address"
+	"github.com/jaypipes/ghw/pkg/topology"
+)
+
+func (i *Info) load(ctx context.Context) error {
+	// The libnvdimm subsystem registers its devices on the "nd" bus:
+	//
+	// $ ls /sys/bus/nd/devices
+	// btt0.0  dax0.0  namespace0.0  namespace0.1  ndbus0  nmem0  nmem1  pfn0.0  region0
+	//
+	// The CXL subsystem registers its devices, including the ports and HDM
+	// decoders of the CXL hierarchy, on the "cxl" bus:
+	//
+	// $ ls /sys/bus/cxl/devices
+	// decoder0.0  decoder1.0  decoder2.0  endpoint2  mem0  port1  root0
+	paths := linuxpath.New(ctx)
+	i.DIMMs = append(i.DIMMs, nvdimms(paths)...)
+	i.Regions = append(i.Regions, regions(paths)...)
+	i.CXLMemoryDevices = append(i.CXLMemoryDevices, cxlMemoryDevices(paths)...)
+	i.CXLPorts = append(i.CXLPorts, cxlPorts(paths)...)
+	i.CXLDecoders = append(i.CXLDecoders, cxlDecoders(paths)...)
+	i.fillNodes(ctx)
+	i.fillPCIDevices(ctx)
+	return nil
+}
+
+// busDevices returns the names of the devices of a bus having the supplied
+// prefix followed by a digit, e.g. "region" matches "region0" but not
+// "regions"
+func busDevices(busPath string, prefix string) []string {
+	entries, err := os.ReadDir(busPath)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || rest == "" || rest[0] < '0' || rest[0] > '9' {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func nvdimms(paths *linuxpath.Paths) []*NVDIMM {
+	dimms := []*NVDIMM{}
+	for _, name := range busDevices(paths.SysBusNdDevices, "nmem") {
+		path := filepath.Join(paths.SysBusNdDevices, name)
+		dimm := &NVDIMM{
+			Name:   name,
+			Vendor: readString(filepath.Join(path, "nfit", "vendor")),
+			Serial: readString(filepath.Join(path, "nfit", "serial")),
+			Flags:  strings.Fields(readString(filepath.Join(path, "nfit", "flags"))),
+			State:  readString(filepath.Join(path, "state")),
+		}
+		dimms = append(dimms, dimm)
+	}
+	return dimms
+}
+
+func regions(paths *linuxpath.Paths) []*Region {
+	regions := []*Region{}
+	for _, name := range busDevices(paths.SysBusNdDevices, "region") {
+		path := filepath.Join(paths.SysBusNdDevices, name)
+		region := &Region{
+			Name:              name,
+			Type:              strings.TrimPrefix(readString(filepath.Join(path, "devtype")), "nd_"),
+			SizeBytes:         readUint(filepath.Join(path, "size")),
+			AvailableBytes:    readUint(filepath.Join(path, "available_size")),
+			PersistenceDomain: readString(filepath.Join(path, "persistence_domain")),
+			NodeID:            readInt(filepath.Join(path, "numa_node")),
+			TargetNodeID:      readInt(filepath.Join(path, "target_node")),
+			DIMMs:             []string{},
+			Namespaces:        []*Namespace{},
+		}
+		// Each mappingN file describes the NVDIMM backing one interleave
+		// position of the region, as "nmem0,0,17179869184,0" (device,
+		// offset, length, position)
+		mappings := int(readUint(filepath.Join(path, "mappings")))
+		for x := 0; x < mappings; x++ {
+			mapping := readString(filepath.Join(path, fmt.Sprintf("mapping%d", x)))
+			if dimm, _, _ := strings.Cut(mapping, ","); dimm != "" {
+				region.DIMMs = append(region.DIMMs, dimm)
+			}
+		}
+		// Namespaces are named after their region's ID: namespace0.0,
+		// namespace0.1, ... belong to region0
+		regionID := strings.TrimPrefix(name, "region")
+		for _, nsName := range busDevices(paths.SysBusNdDevices, "namespace"+regionID+".") {
+			if ns := namespace(paths, nsName); ns != nil {
+				region.Namespaces = append(region.Namespaces, ns)
+			}
+		}
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// namespace returns the namespace with the supplied name or nil if it is the
+// empty "seed" namespace every region has for creating new namespaces
+func namespace(paths *linuxpath.Paths, name string) *Namespace {
+	path := filepath.Join(paths.SysBusNdDevices, name)
+	size := readUint(filepath.Join(path, "size"))
+	if size == 0 {
+		return nil
+	}
+	ns := &Namespace{
+		Name:      name,
+		Mode:      namespaceMode(readString(filepath.Join(path, "mode"))),
+		SizeBytes: size,
+		UUID:      readString(filepath.Join(path, "uuid")),
+	}
+	// Except in raw mode, the namespace is claimed by a btt (sector), pfn
+	// (fsdax) or dax (devdax) device, its "holder", which owns the block or
+	// character device
+	devPaths := []string{path}
+	if holder := readString(filepath.Join(path, "holder")); holder != "" {
+		devPaths = append(devPaths, filepath.Join(paths.SysBusNdDevices, holder))
+	}
+	for _, devPath := range devPaths {
+		if entries, err := os.ReadDir(filepath.Join(devPath, "block")); err == nil && len(entries) > 0 {
+			ns.BlockDevice = entries[0].Name()
+		}
+		entries, err := os.ReadDir(devPath)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), "dax") && strings.Contains(entry.Name(), ".") {
+				ns.CharDevice = entry.Name()
+			}
+		}
+	}
+	return ns
+}
+
+func namespaceMode(mode string) NamespaceMode {
+	switch mode {
+	case "raw":
+		return NamespaceModeRaw
+	case "safe", "sector":
+		return NamespaceModeSector
+	case "memory", "fsdax":
+		return NamespaceModeFSDAX
+	case "dax", "devdax":
+		return NamespaceModeDevDAX
+	}
+	return NamespaceModeUnknown
+}
+
+func cxlMemoryDevices(paths *linuxpath.Paths) []*CXLMemoryDevice {
+	devs := []*CXLMemoryDevice{}
+	for _, name := range busDevices(paths.SysBusCxlDevices, "mem") {
+		path := filepath.Join(paths.SysBusCxlDevices, name)
+		dev := &CXLMemoryDevice{
+			Name:            name,
+			Serial:          readString(filepath.Join(path, "serial")),
+			FirmwareVersion: readString(filepath.Join(path, "firmware_version")),
+			RAMSizeBytes:    readUint(filepath.Join(path, "ram", "size")),
+			PMEMSizeBytes:   readUint(filepath.Join(path, "pmem", "size")),
+			NodeID:          readInt(filepath.Join(path, "numa_node")),
+		}
+		// The memory device is a child of its PCI device:
+		// /sys/bus/cxl/devices/mem0 -> ../../../devices/pci0000:0c/0000:0c:00.0/0000:0d:00.0/mem0
+		if addr := pciaddr.FromString(parentDevice(path)); addr != nil {
+			dev.Address = addr.String()
+		}
+		devs = append(devs, dev)
+	}
+	return devs
+}
+
+func cxlPorts(paths *linuxpath.Paths) []*CXLPort {
+	ports := []*CXLPort{}
+	for _, prefix := range []string{"root", "port", "endpoint"} {
+		for _, name := range busDevices(paths.SysBusCxlDevices, prefix) {
+			path := filepath.Join(paths.SysBusCxlDevices, name)
+			port := &CXLPort{
+				Name:           name,
+				Type:           cxlPortType(name),
+				UpstreamDevice: linkTargetName(filepath.Join(path, "uport")),
+			}
+			// Ports are children of their parent port:
+			// /sys/bus/cxl/devices/endpoint2 -> ../../../devices/platform/ACPI0017:00/root0/port1/endpoint2
+			if parent := parentDevice(path); cxlPortType(parent) != CXLPortTypeUnknown {
+				port.Parent = parent
+			}
+			// The upstream device of an endpoint is its memory device
+			if port.Type == CXLPortTypeEndpoint {
+				port.MemoryDevice = port.UpstreamDevice
+			}
+			if entries, err := os.ReadDir(path); err == nil {
+				for _, entry := range entries {
+					if strings.HasPrefix(entry.Name(), "dport") {
+						port.DownstreamPorts = append(
+							port.DownstreamPorts,
+							linkTargetName(filepath.Join(path, entry.Name())),
+						)
+					}
+				}
+			}
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func cxlPortType(name string) CXLPortType {
+	for prefix, t := range map[string]CXLPortType{
+		"root":     CXLPortTypeRoot,
+		"port":     CXLPortTypeSwitch,
+		"endpoint": CXLPortTypeEndpoint,
+	} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			return t
+		}
+	}
+	return CXLPortTypeUnknown
+}
+
+func cxlDecoders(paths *linuxpath.Paths) []*CXLDecoder {
+	decoders := []*CXLDecoder{}
+	for _, name := range busDevices(paths.SysBusCxlDevices, "decoder") {
+		path := filepath.Join(paths.SysBusCxlDevices, name)
+		decoder := &CXLDecoder{
+			Name:                  name,
+			Port:                  parentDevice(path),
+			StartAddress:          readUint(filepath.Join(path, "start")),
+			SizeBytes:             readUint(filepath.Join(path, "size")),
+			InterleaveWays:        int(readUint(filepath.Join(path, "interleave_ways"))),
+			InterleaveGranularity: int(readUint(filepath.Join(path, "interleave_granularity"))),
+			Mode:                  readString(filepath.Join(path, "mode")),
+			Region:                readString(filepath.Join(path, "region")),
+		}
+		switch readString(filepath.Join(path, "devtype")) {
+		case "cxl_decoder_root":
+			decoder.Type = CXLPortTypeRoot
+		case "cxl_decoder_switch":
+			decoder.Type = CXLPortTypeSwitch
+		case "cxl_decoder_endpoint":
+			decoder.Type = CXLPortTypeEndpoint
+		default:
+			decoder.Type = cxlPortType(decoder.Port)
+		}
+		if targets := readString(filepath.Join(path, "target_list")); targets != "" {
+			decoder.Targets = strings.Split(targets, ",")
+		}
+		decoders = append(decoders, decoder)
+	}
+	return decoders
+}
+
+// fillNodes points the regions and memory devices to their topology nodes
+func (i *Info) fillNodes(ctx context.Context) {
+	if !config.TopologyEnabled(ctx) {
+		return
+	}
+	if len(i.Regions) == 0 && len(i.CXLMemoryDevices) == 0 {
+		return
+	}
+	topo, err := topology.New(ctx)
+	if err != nil {
+		log.Warn(ctx, "failed to determine topology: %s", err)
+		return
+	}
+	nodeByID := func(id int) *topology.Node {
+		for _, node := range topo.Nodes {
+			if node.ID == id {
+				return node
+			}
+		}
+		return nil
+	}
+	for _, r := range i.Regions {
+		r.Node = nodeByID(r.NodeID)
+		r.TargetNode = nodeByID(r.TargetNodeID)
+	}
+	for _, d := range i.CXLMemoryDevices {
+		d.Node = nodeByID(d.NodeID)
+	}
+}
+
+// fillPCIDevices sets the PCI device information of the CXL memory devices
+func (i *Info) fillPCIDevices(ctx context.Context) {
+	if len(i.CXLMemoryDevices) == 0 {
+		return
+	}
+	pciInfo, err := pci.New(ctx)
+	if err != nil {
+		log.Warn(ctx, "failed to initialize PCI device database: %s", err)
+		return
+	}
+	for _, d := range i.CXLMemoryDevices {
+		if d.Address != "" {
+			d.PCI = pciInfo.GetDevice(d.Address)
+		}
+	}
+}
+
+// parentDevice returns the name of the parent of the device whose bus link
+// is at the supplied path
+func parentDevice(path string) string {
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(filepath.Dir(dest))
+}
+
+// linkTargetName returns the last element of the target of the supplied
+// symbolic link
+func linkTargetName(path string) string {
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dest)
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readUint reads an unsigned integer in decimal or, with a 0x prefix,
+// hexadecimal notation, returning 0 on error
+func readUint(path string) uint64 {
+	v, err := strconv.ParseUint(readString(path), 0, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// readInt reads a possibly negative integer like a NUMA node ID, returning -1
+// on error
+func readInt(path string) int {
+	v, err := strconv.Atoi(readString(path))
+	if err != nil {
+		return -1
+	}
+	return v
+}
@@ -0,0 +1,232 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pmem_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/internal/testutil"
+	"github.com/jaypipes/ghw/pkg/pmem"
+)
+
+// writeDevice creates the sysfs directory of a device below root, with the
+// supplied attribute files, and links it from /sys/bus/<bus>/devices
+func writeDevice(t *testing.T, root, bus, devPath string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, "sys", "devices", devPath)
+	testutil.WriteFiles(t, dir, attrs)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if bus == "" {
+		return
+	}
+	busDir := filepath.Join(root, "sys", "bus", bus, "devices")
+	if err := os.MkdirAll(busDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(
+		filepath.Join("..", "..", "..", "devices", devPath),
+		filepath.Join(busDir, filepath.Base(devPath)),
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeLink creates a symbolic link in the sysfs directory of a device
+// pointing to another device
+func writeLink(t *testing.T, root, devPath, name, targetDevPath string) {
+	t.Helper()
+	from := filepath.Join(root, "sys", "devices", devPath)
+	rel, err := filepath.Rel(from, filepath.Join(root, "sys", "devices", targetDevPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(rel, filepath.Join(from, name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPMEMRegionsAndNamespaces(t *testing.T) {
+	root := t.TempDir()
+	ndbus := "LNXSYSTM:00/LNXSYBUS:00/ACPI0012:00/ndbus0"
+	writeDevice(t, root, "nd", ndbus+"/nmem0", map[string]string{
+		"state":       "active",
+		"nfit/vendor": "0x8980",
+		"nfit/serial": "0x1234abcd",
+		"nfit/flags":  "",
+	})
+	writeDevice(t, root, "nd", ndbus+"/nmem1", map[string]string{
+		"state":       "active",
+		"nfit/serial": "0x1234abce",
+		"nfit/flags":  "not_armed smart_notify",
+	})
+	writeDevice(t, root, "nd", ndbus+"/region0", map[string]string{
+		"devtype":            "nd_pmem",
+		"size":               "541165879296",
+		"available_size":     "0",
+		"persistence_domain": "memory_controller",
+		"numa_node":          "0",
+		"target_node":        "2",
+		"mappings":           "2",
+		"mapping0":           "nmem0,0,270582939648,0",
+		"mapping1":           "nmem1,0,270582939648,1",
+	})
+	// fsdax namespace claimed by pfn0.1, devdax namespace claimed by dax0.2
+	// and the empty seed namespace
+	writeDevice(t, root, "nd", ndbus+"/region0/namespace0.0", map[string]string{
+		"size":   "270582939648",
+		"mode":   "memory",
+		"uuid":   "d2f0fde8-5b6a-4e6d-a0a4-3a1b6b0e7c53",
+		"holder": "pfn0.1",
+	})
+	writeDevice(t, root, "nd", ndbus+"/region0/pfn0.1", map[string]string{
+		"block/pmem0/dev": "259:0",
+	})
+	writeDevice(t, root, "nd", ndbus+"/region0/namespace0.1", map[string]string{
+		"size":   "270582939648",
+		"mode":   "dax",
+		"holder": "dax0.2",
+	})
+	writeDevice(t, root, "nd", ndbus+"/region0/dax0.2", map[string]string{
+		"dax0.0/dev": "252:0",
+	})
+	writeDevice(t, root, "nd", ndbus+"/region0/namespace0.2", map[string]string{
+		"size": "0",
+		"mode": "raw",
+	})
+
+	info, err := pmem.New(ghw.WithChroot(root), ghw.WithDisableTopology())
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.DIMMs) != 2 {
+		t.Fatalf("Expected 2 NVDIMMs, but got %d", len(info.DIMMs))
+	}
+	if len(info.DIMMs[0].Flags) != 0 || info.DIMMs[0].Vendor != "0x8980" {
+		t.Errorf("unexpected NVDIMM: %+v", info.DIMMs[0])
+	}
+	if !reflect.DeepEqual(info.DIMMs[1].Flags, []string{"not_armed", "smart_notify"}) {
+		t.Errorf("Expected NVDIMM health flags, but got %v", info.DIMMs[1].Flags)
+	}
+
+	if len(info.Regions) != 1 {
+		t.Fatalf("Expected 1 region, but got %d", len(info.Regions))
+	}
+	region := info.Regions[0]
+	if region.Type != "pmem" || region.SizeBytes != 541165879296 || region.PersistenceDomain != "memory_controller" {
+		t.Errorf("unexpected region: %+v", region)
+	}
+	if region.NodeID != 0 || region.TargetNodeID != 2 {
+		t.Errorf("Expected region on node 0 targeting node 2, but got %d and %d", region.NodeID, region.TargetNodeID)
+	}
+	if !reflect.DeepEqual(region.DIMMs, []string{"nmem0", "nmem1"}) {
+		t.Errorf("Expected region interleaved across nmem0 and nmem1, but got %v", region.DIMMs)
+	}
+	if len(region.Namespaces) != 2 {
+		t.Fatalf("Expected 2 namespaces, but got %d", len(region.Namespaces))
+	}
+	fsdax, devdax := region.Namespaces[0], region.Namespaces[1]
+	if fsdax.Mode != pmem.NamespaceModeFSDAX || fsdax.BlockDevice != "pmem0" || fsdax.CharDevice != "" {
+		t.Errorf("unexpected fsdax namespace: %+v", fsdax)
+	}
+	if devdax.Mode != pmem.NamespaceModeDevDAX || devdax.CharDevice != "dax0.0" || devdax.BlockDevice != "" {
+		t.Errorf("unexpected devdax namespace: %+v", devdax)
+	}
+	if !strings.Contains(info.JSONString(false), `"mode":"fsdax"`) {
+		t.Errorf("Expected lowercase namespace mode in %s", info.JSONString(false))
+	}
+}
+
+func TestPMEMCXL(t *testing.T) {
+	root := t.TempDir()
+	memdev := "pci0000:0c/0000:0c:00.0/0000:0d:00.0/mem0"
+	cxlRoot := "platform/ACPI0017:00/root0"
+	writeDevice(t, root, "", "pci0000:0c/0000:0c:00.0", nil)
+	writeDevice(t, root, "cxl", memdev, map[string]string{
+		"serial":           "0x0",
+		"firmware_version": "BWFW VERSION 00",
+		"numa_node":        "0",
+		"ram/size":         "0x4000000000",
+		"pmem/size":        "0x0",
+	})
+	writeDevice(t, root, "cxl", cxlRoot, nil)
+	writeDevice(t, root, "cxl", cxlRoot+"/decoder0.0", map[string]string{
+		"devtype":                "cxl_decoder_root",
+		"start":                  "0x1050000000",
+		"size":                   "0x4000000000",
+		"interleave_ways":        "1",
+		"interleave_granularity": "256",
+		"target_list":            "0",
+	})
+	writeDevice(t, root, "cxl", cxlRoot+"/port1", nil)
+	writeLink(t, root, cxlRoot+"/port1", "uport", "pci0000:0c")
+	writeLink(t, root, cxlRoot+"/port1", "dport0", "pci0000:0c/0000:0c:00.0")
+	writeDevice(t, root, "cxl", cxlRoot+"/port1/endpoint2", nil)
+	writeLink(t, root, cxlRoot+"/port1/endpoint2", "uport", memdev)
+	writeDevice(t, root, "cxl", cxlRoot+"/port1/endpoint2/decoder2.0", map[string]string{
+		"devtype":         "cxl_decoder_endpoint",
+		"start":           "0x1050000000",
+		"size":            "0x4000000000",
+		"interleave_ways": "1",
+		"mode":            "ram",
+		"region":          "region0",
+	})
+
+	info, err := pmem.New(ghw.WithChroot(root), ghw.WithDisableTopology())
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.CXLMemoryDevices) != 1 {
+		t.Fatalf("Expected 1 CXL memory device, but got %d", len(info.CXLMemoryDevices))
+	}
+	dev := info.CXLMemoryDevices[0]
+	if dev.Address != "0000:0d:00.0" || dev.RAMSizeBytes != 256<<30 || dev.PMEMSizeBytes != 0 || dev.NodeID != 0 {
+		t.Errorf("unexpected CXL memory device: %+v", dev)
+	}
+
+	wantPorts := []*pmem.CXLPort{
+		{Name: "root0", Type: pmem.CXLPortTypeRoot},
+		{Name: "port1", Type: pmem.CXLPortTypeSwitch, Parent: "root0", UpstreamDevice: "pci0000:0c", DownstreamPorts: []string{"0000:0c:00.0"}},
+		{Name: "endpoint2", Type: pmem.CXLPortTypeEndpoint, Parent: "port1", UpstreamDevice: "mem0", MemoryDevice: "mem0"},
+	}
+	if !reflect.DeepEqual(info.CXLPorts, wantPorts) {
+		for _, p := range info.CXLPorts {
+			t.Logf("%+v", p)
+		}
+		t.Errorf("unexpected CXL ports")
+	}
+
+	if len(info.CXLDecoders) != 2 {
+		t.Fatalf("Expected 2 CXL decoders, but got %d", len(info.CXLDecoders))
+	}
+	rootDecoder, epDecoder := info.CXLDecoders[0], info.CXLDecoders[1]
+	if rootDecoder.Port != "root0" || rootDecoder.Type != pmem.CXLPortTypeRoot || rootDecoder.StartAddress != 0x1050000000 {
+		t.Errorf("unexpected root decoder: %+v", rootDecoder)
+	}
+	if rootDecoder.InterleaveGranularity != 256 || !reflect.DeepEqual(rootDecoder.Targets, []string{"0"}) {
+		t.Errorf("unexpected root decoder: %+v", rootDecoder)
+	}
+	if epDecoder.Port != "endpoint2" || epDecoder.Type != pmem.CXLPortTypeEndpoint || epDecoder.Mode != "ram" || epDecoder.Region != "region0" {
+		t.Errorf("unexpected endpoint decoder: %+v", epDecoder)
+	}
+}
+
+func TestPMEMNoDevices(t *testing.T) {
+	info, err := pmem.New(ghw.WithChroot(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.Regions) != 0 || len(info.CXLMemoryDevices) != 0 {
+		t.Errorf("Expected no devices, but got %v", info)
+	}
+}
@@ -10,10 +10,16 @@ import (
 
 	"github.com/jaypipes/ghw/pkg/linuxdmi"
 	"github.com/jaypipes/ghw/pkg/linuxdt"
+	"github.com/jaypipes/ghw/pkg/smbios"
 	"github.com/jaypipes/ghw/pkg/util"
 )
 
 func (i *Info) load(ctx context.Context) error {
+	if table, err := smbios.Load(ctx); err == nil {
+		if system := table.System(); system != nil {
+			return i.loadSMBIOS(system)
+		}
+	}
 	if !linuxdmi.Available(ctx) && linuxdt.Available(ctx) {
 		return i.loadDeviceTree(ctx)
 	}
@@ -29,6 +35,21 @@ func (i *Info) load(ctx context.Context) error {
 	return nil
 }
 
+// loadSMBIOS populates product information from the decoded SMBIOS System
+// Information structure. The strings the firmware left unset are reported as
+// unknown, as the sysfs and DeviceTree readers do.
+func (i *Info) loadSMBIOS(system *smbios.System) error {
+	i.Family = util.StringOrUnknown(system.Family)
+	i.Name = util.StringOrUnknown(system.ProductName)
+	i.Vendor = util.StringOrUnknown(system.Manufacturer)
+	i.SerialNumber = util.StringOrUnknown(system.SerialNumber)
+	i.UUID = util.StringOrUnknown(system.UUID)
+	i.SKU = util.StringOrUnknown(system.SKUNumber)
+	i.Version = util.StringOrUnknown(system.Version)
+
+	return nil
+}
+
 // loadDeviceTree populates product information from the DeviceTree on systems
 // without DMI/SMBIOS. The DeviceTree only carries the model, vendor and serial
 // number, so the remaining fields stay unknown.
@@ -0,0 +1,383 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+// Package smbios decodes raw System Management BIOS (SMBIOS, also known as
+// DMI) tables into typed structures. The format is defined by the DMTF SMBIOS
+// Reference Specification (DSP0134).
+//
+// The decoder itself only operates on byte slices, so it can be used against
+// tables captured from another host. On Linux, Load reads the entry point and
+// structure table the kernel exposes under /sys/firmware/dmi/tables, honoring
+// any chroot or path overrides in the supplied context.
+package smbios
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Well-known SMBIOS structure types.
+const (
+	TypeBIOS                = 0
+	TypeSystem              = 1
+	TypeBaseboard           = 2
+	TypeChassis             = 3
+	TypeProcessor           = 4
+	TypePortConnector       = 8
+	TypeSystemSlot          = 9
+	TypeOEMStrings          = 11
+	TypePhysicalMemoryArray = 16
+	TypeMemoryDevice        = 17
+	TypeEndOfTable          = 127
+)
+
+var (
+	anchor21     = []byte("_SM_")
+	anchor30     = []byte("_SM3_")
+	anchorLegacy = []byte("_DMI_")
+)
+
+// cacheKey is the key of the cache of the decoded tables in a context
+type cacheKey struct{}
+
+// cache holds the outcome of the first Load with a context
+type cache struct {
+	once  sync.Once
+	table *Table
+	err   error
+}
+
+// WithCache returns a copy of the supplied context with which Load reads and
+// decodes the tables once, sharing them between its callers, e.g. the
+// packages describing the BIOS, baseboard, chassis, product and memory of a
+// host. The tables must not be modified by the callers.
+func WithCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(cacheKey{}).(*cache); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheKey{}, &cache{})
+}
+
+// ErrEntryPointInvalid is returned when the entry point does not start with a
+// known anchor string or is too short for the anchor it starts with.
+var ErrEntryPointInvalid = errors.New("smbios: invalid entry point")
+
+// ErrEntryPointChecksum is returned when the entry point bytes do not sum to
+// zero.
+var ErrEntryPointChecksum = errors.New("smbios: entry point checksum mismatch")
+
+// ErrTableTruncated is returned when the structure table ends in the middle of
+// a structure.
+var ErrTableTruncated = errors.New("smbios: truncated structure table")
+
+// EntryPoint describes the SMBIOS entry point structure, which locates the
+// structure table and records the version of the specification the firmware
+// implements.
+type EntryPoint struct {
+	// Anchor is the anchor string that identified the entry point, e.g.
+	// "_SM_" for 32-bit (2.x) or "_SM3_" for 64-bit (3.x) entry points.
+	Anchor string `json:"anchor"`
+	// MajorVersion is the major version of the SMBIOS specification
+	MajorVersion uint8 `json:"major_version"`
+	// MinorVersion is the minor version of the SMBIOS specification
+	MinorVersion uint8 `json:"minor_version"`
+	// Revision is the docrev of the SMBIOS specification. Only 3.x entry
+	// points carry this.
+	Revision uint8 `json:"revision"`
+	// TableAddress is the physical address of the structure table
+	TableAddress uint64 `json:"table_address"`
+	// TableLength is the length of the structure table in bytes. For 3.x
+	// entry points this is the maximum size of the table.
+	TableLength uint32 `json:"table_length"`
+	// NumStructures is the number of structures in the table, or 0 if the
+	// entry point does not say (3.x entry points).
+	NumStructures uint16 `json:"num_structures"`
+}
+
+// Version returns the SMBIOS version as a "major.minor" string.
+func (ep *EntryPoint) Version() string {
+	return fmt.Sprintf("%d.%d", ep.MajorVersion, ep.MinorVersion)
+}
+
+// atLeast returns true if the entry point reports SMBIOS version major.minor
+// or later.
+func (ep *EntryPoint) atLeast(major, minor uint8) bool {
+	if ep == nil {
+		return false
+	}
+	if ep.MajorVersion != major {
+		return ep.MajorVersion > major
+	}
+	return ep.MinorVersion >= minor
+}
+
+// ParseEntryPoint decodes an SMBIOS 2.x ("_SM_"), 3.x ("_SM3_") or legacy
+// ("_DMI_") entry point, as read from /sys/firmware/dmi/tables/smbios_entry_point.
+func ParseEntryPoint(data []byte) (*EntryPoint, error) {
+	switch {
+	case bytes.HasPrefix(data, anchor30):
+		if len(data) < 0x18 {
+			return nil, ErrEntryPointInvalid
+		}
+		length := int(data[0x06])
+		if length < 0x18 || length > len(data) {
+			return nil, ErrEntryPointInvalid
+		}
+		if !checksumOK(data[:length]) {
+			return nil, ErrEntryPointChecksum
+		}
+		return &EntryPoint{
+			Anchor:       string(anchor30),
+			MajorVersion: data[0x07],
+			MinorVersion: data[0x08],
+			Revision:     data[0x09],
+			TableLength:  binary.LittleEndian.Uint32(data[0x0C:0x10]),
+			TableAddress: binary.LittleEndian.Uint64(data[0x10:0x18]),
+		}, nil
+	case bytes.HasPrefix(data, anchor21):
+		if len(data) < 0x1E {
+			return nil, ErrEntryPointInvalid
+		}
+		length := int(data[0x05])
+		if length < 0x1E || length > len(data) {
+			return nil, ErrEntryPointInvalid
+		}
+		if !checksumOK(data[:length]) {
+			return nil, ErrEntryPointChecksum
+		}
+		if !bytes.Equal(data[0x10:0x15], anchorLegacy) {
+			return nil, ErrEntryPointInvalid
+		}
+		ep := &EntryPoint{
+			Anchor:        string(anchor21),
+			MajorVersion:  data[0x06],
+			MinorVersion:  data[0x07],
+			TableLength:   uint32(binary.LittleEndian.Uint16(data[0x16:0x18])),
+			TableAddress:  uint64(binary.LittleEndian.Uint32(data[0x18:0x1C])),
+			NumStructures: binary.LittleEndian.Uint16(data[0x1C:0x1E]),
+		}
+		// Some firmware reports a malformed version for 2.3 (0x1F, 0x21)
+		// and 2.6 (0x33). Fix those up the same way the kernel and
+		// dmidecode do.
+		if ep.MajorVersion == 2 && (ep.MinorVersion == 0x1F || ep.MinorVersion == 0x21) {
+			ep.MinorVersion = 3
+		} else if ep.MajorVersion == 2 && ep.MinorVersion == 0x33 {
+			ep.MinorVersion = 6
+		}
+		return ep, nil
+	case bytes.HasPrefix(data, anchorLegacy):
+		if len(data) < 0x0F {
+			return nil, ErrEntryPointInvalid
+		}
+		if !checksumOK(data[:0x0F]) {
+			return nil, ErrEntryPointChecksum
+		}
+		bcd := data[0x0E]
+		return &EntryPoint{
+			Anchor:        string(anchorLegacy),
+			MajorVersion:  bcd >> 4,
+			MinorVersion:  bcd & 0x0F,
+			TableLength:   uint32(binary.LittleEndian.Uint16(data[0x06:0x08])),
+			TableAddress:  uint64(binary.LittleEndian.Uint32(data[0x08:0x0C])),
+			NumStructures: binary.LittleEndian.Uint16(data[0x0C:0x0E]),
+		}, nil
+	}
+	return nil, ErrEntryPointInvalid
+}
+
+func checksumOK(data []byte) bool {
+	var sum uint8
+	for _, b := range data {
+		sum += b
+	}
+	return sum == 0
+}
+
+// Structure is a single, undecoded SMBIOS structure. Typed accessors on Table
+// (e.g. Table.MemoryDevices) decode the well-known structure types; callers
+// may use the Byte/Word/DWord/QWord/StringAt helpers to decode others.
+type Structure struct {
+	// Type is the SMBIOS structure type, e.g. 17 for a Memory Device
+	Type uint8 `json:"type"`
+	// Handle is the unique 16-bit handle of the structure within the table
+	Handle uint16 `json:"handle"`
+	// Formatted is the formatted area of the structure, *including* the
+	// four-byte header so that offsets match those in the specification.
+	Formatted []byte `json:"-"`
+	// Strings holds the structure's string-set. SMBIOS string references are
+	// 1-based, so a reference of N corresponds to Strings[N-1].
+	Strings []string `json:"strings,omitempty"`
+}
+
+// Len returns the length of the formatted area, including the header.
+func (s *Structure) Len() int {
+	return len(s.Formatted)
+}
+
+// Byte returns the byte at the supplied specification offset, or 0 if the
+// structure is too short to contain it.
+func (s *Structure) Byte(offset int) uint8 {
+	if offset+1 > len(s.Formatted) {
+		return 0
+	}
+	return s.Formatted[offset]
+}
+
+// Word returns the little-endian 16-bit value at the supplied specification
+// offset, or 0 if the structure is too short to contain it.
+func (s *Structure) Word(offset int) uint16 {
+	if offset+2 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(s.Formatted[offset:])
+}
+
+// DWord returns the little-endian 32-bit value at the supplied specification
+// offset, or 0 if the structure is too short to contain it.
+func (s *Structure) DWord(offset int) uint32 {
+	if offset+4 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(s.Formatted[offset:])
+}
+
+// QWord returns the little-endian 64-bit value at the supplied specification
+// offset, or 0 if the structure is too short to contain it.
+func (s *Structure) QWord(offset int) uint64 {
+	if offset+8 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(s.Formatted[offset:])
+}
+
+// StringAt returns the string referenced by the byte at the supplied
+// specification offset, with surrounding whitespace trimmed. An empty string
+// is returned for a zero (absent) or out-of-range reference.
+func (s *Structure) StringAt(offset int) string {
+	idx := int(s.Byte(offset))
+	if idx == 0 || idx > len(s.Strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.Strings[idx-1])
+}
+
+// has returns true if the formatted area is long enough to contain a field of
+// size bytes at the supplied offset. SMBIOS structures grow across versions
+// of the specification, so fields added later must be checked for.
+func (s *Structure) has(offset int, size int) bool {
+	return offset+size <= len(s.Formatted)
+}
+
+// Table is a decoded SMBIOS structure table.
+type Table struct {
+	// EntryPoint is the decoded entry point describing the table
+	EntryPoint *EntryPoint `json:"entry_point"`
+	// Structures contains every structure found in the table, in table order,
+	// excluding the End-of-Table structure.
+	Structures []*Structure `json:"-"`
+}
+
+// ParseTable decodes the SMBIOS structure table in table, described by the
+// entry point in entryPoint, as read from /sys/firmware/dmi/tables/DMI and
+// /sys/firmware/dmi/tables/smbios_entry_point respectively.
+func ParseTable(entryPoint []byte, table []byte) (*Table, error) {
+	ep, err := ParseEntryPoint(entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	structs, err := ParseStructures(table, int(ep.NumStructures))
+	if err != nil {
+		return nil, err
+	}
+	return &Table{
+		EntryPoint: ep,
+		Structures: structs,
+	}, nil
+}
+
+// ParseStructures decodes a raw SMBIOS structure table. Decoding stops at the
+// End-of-Table (type 127) structure, at the end of data, or once max
+// structures have been read if max is greater than zero.
+func ParseStructures(data []byte, max int) ([]*Structure, error) {
+	out := []*Structure{}
+	i := 0
+	for i+4 <= len(data) {
+		if max > 0 && len(out) >= max {
+			break
+		}
+		typ := data[i]
+		length := int(data[i+1])
+		if length < 4 {
+			return nil, fmt.Errorf(
+				"smbios: structure at offset %d has invalid length %d",
+				i, length,
+			)
+		}
+		if i+length > len(data) {
+			return nil, ErrTableTruncated
+		}
+		s := &Structure{
+			Type:      typ,
+			Handle:    binary.LittleEndian.Uint16(data[i+2 : i+4]),
+			Formatted: data[i : i+length],
+		}
+		// The string-set immediately follows the formatted area and is
+		// terminated by a double NUL. A structure with no strings is
+		// followed by just the double NUL.
+		strStart := i + length
+		end := bytes.Index(data[strStart:], []byte{0, 0})
+		if end < 0 {
+			return nil, ErrTableTruncated
+		}
+		if end > 0 {
+			s.Strings = strings.Split(string(data[strStart:strStart+end]), "\x00")
+		}
+		i = strStart + end + 2
+		if typ == TypeEndOfTable {
+			break
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// StructuresByType returns the structures of the supplied type, in table
+// order.
+func (t *Table) StructuresByType(typ uint8) []*Structure {
+	out := []*Structure{}
+	for _, s := range t.Structures {
+		if s.Type == typ {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// StructureByHandle returns the structure with the supplied handle, or nil if
+// there is no such structure.
+func (t *Table) StructureByHandle(handle uint16) *Structure {
+	for _, s := range t.Structures {
+		if s.Handle == handle {
+			return s
+		}
+	}
+	return nil
+}
+
+// first returns the first structure of the supplied type, or nil.
+func (t *Table) first(typ uint8) *Structure {
+	for _, s := range t.Structures {
+		if s.Type == typ {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package smbios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jaypipes/ghw/internal/log"
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+)
+
+// ErrNotAvailable is returned by Load when the kernel does not expose the raw
+// SMBIOS tables, for instance on hosts without SMBIOS firmware or in a chroot
+// or snapshot that did not capture them.
+var ErrNotAvailable = errors.New("smbios: tables not available")
+
+// Load reads and decodes the SMBIOS entry point and structure table from
+// /sys/firmware/dmi/tables. Both files are usually only readable by root;
+// callers without sufficient privilege receive a wrapped permission error and
+// should fall back to the /sys/class/dmi/id attributes (see linuxdmi). With a
+// context returned by WithCache, the tables are only read and decoded once.
+func Load(ctx context.Context) (*Table, error) {
+	if c, ok := ctx.Value(cacheKey{}).(*cache); ok {
+		c.once.Do(func() {
+			c.table, c.err = load(ctx)
+		})
+		return c.table, c.err
+	}
+	return load(ctx)
+}
+
+func load(ctx context.Context) (*Table, error) {
+	paths := linuxpath.New(ctx)
+	epPath := filepath.Join(paths.SysFirmwareDMITables, "smbios_entry_point")
+	tablePath := filepath.Join(paths.SysFirmwareDMITables, "DMI")
+
+	log.Debug(ctx, "reading from %q", epPath)
+	ep, err := os.ReadFile(epPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotAvailable
+		}
+		return nil, fmt.Errorf("smbios: read %s: %w", epPath, err)
+	}
+	log.Debug(ctx, "reading from %q", tablePath)
+	table, err := os.ReadFile(tablePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotAvailable
+		}
+		return nil, fmt.Errorf("smbios: read %s: %w", tablePath, err)
+	}
+	return ParseTable(ep, table)
+}
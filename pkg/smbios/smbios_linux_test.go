@@ -0,0 +1,112 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package smbios_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/pkg/product"
+	"github.com/jaypipes/ghw/pkg/smbios"
+)
+
+func smbiosChroot(t *testing.T, ep, table []byte) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "sys", "firmware", "dmi", "tables")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "smbios_entry_point"), ep, 0o400); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "DMI"), table, 0o400); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestLoadNotAvailable(t *testing.T) {
+	ctx := config.WithChroot(t.TempDir())(context.TODO())
+	if _, err := smbios.Load(ctx); !errors.Is(err, smbios.ErrNotAvailable) {
+		t.Fatalf("Expected ErrNotAvailable, but got %v", err)
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	data := buildTable([]testStructure{
+		{
+			typ: smbios.TypeSystem, handle: 0x0100,
+			formatted: formatted(0x1B-4, map[int][]byte{0x04: {1}}),
+			strings:   []string{"LENOVO"},
+		},
+	})
+	root := smbiosChroot(t, buildEntryPoint30(3, 4, uint32(len(data))), data)
+
+	ctx := smbios.WithCache(config.WithChroot(root)(context.TODO()))
+	first, err := smbios.Load(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	// the cached tables are returned without reading them again
+	if err := os.RemoveAll(filepath.Join(root, "sys")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := smbios.Load(ctx)
+	if err != nil || second != first {
+		t.Fatalf("Expected the cached tables, but got %p, %v", second, err)
+	}
+	if smbios.WithCache(ctx) != ctx {
+		t.Fatalf("Expected WithCache to keep the cache of the context")
+	}
+	if _, err := smbios.Load(config.WithChroot(root)(context.TODO())); !errors.Is(err, smbios.ErrNotAvailable) {
+		t.Fatalf("Expected ErrNotAvailable without a cache, but got %v", err)
+	}
+}
+
+func TestLoadProduct(t *testing.T) {
+	data := buildTable([]testStructure{
+		{
+			typ: smbios.TypeSystem, handle: 0x0100,
+			formatted: formatted(0x1B-4, map[int][]byte{
+				0x04: {1}, 0x05: {2}, 0x06: {3}, 0x07: {4}, 0x19: {5}, 0x1A: {6},
+			}),
+			strings: []string{"LENOVO", "21CBCTO1WW", "ThinkPad X1 Carbon Gen 10", "PF3ABCDE", "LENOVO_MT_21CB", "ThinkPad X1 Carbon Gen 10"},
+		},
+	})
+	root := smbiosChroot(t, buildEntryPoint30(3, 4, uint32(len(data))), data)
+
+	ctx := config.WithChroot(root)(context.TODO())
+	table, err := smbios.Load(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if table.EntryPoint.Version() != "3.4" {
+		t.Fatalf("Expected version 3.4, but got %s", table.EntryPoint.Version())
+	}
+
+	info, err := product.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Vendor, "LENOVO"; got != want {
+		t.Errorf("vendor: got %q, want %q", got, want)
+	}
+	if got, want := info.Name, "21CBCTO1WW"; got != want {
+		t.Errorf("name: got %q, want %q", got, want)
+	}
+	if got, want := info.SerialNumber, "PF3ABCDE"; got != want {
+		t.Errorf("serial: got %q, want %q", got, want)
+	}
+	if got, want := info.SKU, "LENOVO_MT_21CB"; got != want {
+		t.Errorf("sku: got %q, want %q", got, want)
+	}
+}
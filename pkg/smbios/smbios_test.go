@@ -0,0 +1,327 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package smbios_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/ghw/pkg/smbios"
+)
+
+// testStructure is a structure to be assembled into a synthetic SMBIOS table.
+// formatted is the formatted area *after* the four-byte header.
+type testStructure struct {
+	typ       uint8
+	handle    uint16
+	formatted []byte
+	strings   []string
+}
+
+// buildTable assembles a raw SMBIOS structure table, terminated by an
+// End-of-Table structure.
+func buildTable(structs []testStructure) []byte {
+	var out []byte
+	structs = append(structs, testStructure{typ: smbios.TypeEndOfTable, handle: 0xFFFF})
+	for _, s := range structs {
+		var hdr [4]byte
+		hdr[0] = s.typ
+		hdr[1] = byte(4 + len(s.formatted))
+		binary.LittleEndian.PutUint16(hdr[2:], s.handle)
+		out = append(out, hdr[:]...)
+		out = append(out, s.formatted...)
+		if len(s.strings) == 0 {
+			out = append(out, 0, 0)
+			continue
+		}
+		for _, str := range s.strings {
+			out = append(out, []byte(str)...)
+			out = append(out, 0)
+		}
+		out = append(out, 0)
+	}
+	return out
+}
+
+// buildEntryPoint30 assembles a valid 64-bit SMBIOS 3.x entry point.
+func buildEntryPoint30(major, minor uint8, tableLen uint32) []byte {
+	ep := make([]byte, 0x18)
+	copy(ep, "_SM3_")
+	ep[0x06] = 0x18
+	ep[0x07] = major
+	ep[0x08] = minor
+	ep[0x0A] = 0x01
+	binary.LittleEndian.PutUint32(ep[0x0C:], tableLen)
+	binary.LittleEndian.PutUint64(ep[0x10:], 0x7F6B1000)
+	ep[0x05] = checksum(ep)
+	return ep
+}
+
+// buildEntryPoint21 assembles a valid 32-bit SMBIOS 2.x entry point.
+func buildEntryPoint21(major, minor uint8, tableLen uint16, numStructs uint16) []byte {
+	ep := make([]byte, 0x1F)
+	copy(ep, "_SM_")
+	ep[0x05] = 0x1F
+	ep[0x06] = major
+	ep[0x07] = minor
+	copy(ep[0x10:], "_DMI_")
+	binary.LittleEndian.PutUint16(ep[0x16:], tableLen)
+	binary.LittleEndian.PutUint32(ep[0x18:], 0x000F0000)
+	binary.LittleEndian.PutUint16(ep[0x1C:], numStructs)
+	ep[0x15] = checksum(ep[0x10:])
+	ep[0x04] = checksum(ep)
+	return ep
+}
+
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
+
+// formatted returns a formatted area of size bytes (after the header) with
+// the supplied values written at their specification offsets.
+func formatted(size int, fields map[int][]byte) []byte {
+	out := make([]byte, size)
+	for offset, val := range fields {
+		copy(out[offset-4:], val)
+	}
+	return out
+}
+
+func le16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func TestParseEntryPoint(t *testing.T) {
+	ep, err := smbios.ParseEntryPoint(buildEntryPoint30(3, 5, 0x1234))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if ep.Version() != "3.5" || ep.TableLength != 0x1234 || ep.TableAddress != 0x7F6B1000 {
+		t.Fatalf("unexpected 3.x entry point: %+v", ep)
+	}
+
+	ep, err = smbios.ParseEntryPoint(buildEntryPoint21(2, 8, 0x200, 42))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if ep.Version() != "2.8" || ep.TableLength != 0x200 || ep.NumStructures != 42 {
+		t.Fatalf("unexpected 2.x entry point: %+v", ep)
+	}
+
+	// some 2.1 firmware reports an entry point length of 0x1E
+	short := buildEntryPoint21(2, 1, 0x100, 12)[:0x1E]
+	short[0x05] = 0x1E
+	short[0x04] = 0
+	short[0x04] = checksum(short)
+	ep, err = smbios.ParseEntryPoint(short)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if ep.Version() != "2.1" || ep.NumStructures != 12 {
+		t.Fatalf("unexpected short 2.x entry point: %+v", ep)
+	}
+
+	bad := buildEntryPoint30(3, 5, 0x1234)
+	bad[0x07]++
+	if _, err := smbios.ParseEntryPoint(bad); !errors.Is(err, smbios.ErrEntryPointChecksum) {
+		t.Fatalf("Expected ErrEntryPointChecksum, but got %v", err)
+	}
+	if _, err := smbios.ParseEntryPoint([]byte("_XX_ not an entry point")); !errors.Is(err, smbios.ErrEntryPointInvalid) {
+		t.Fatalf("Expected ErrEntryPointInvalid, but got %v", err)
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	uuid := []byte{
+		0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66,
+		0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+	}
+	data := buildTable([]testStructure{
+		{
+			typ: smbios.TypeBIOS, handle: 0x0000,
+			formatted: formatted(0x18-4, map[int][]byte{
+				0x04: {1}, 0x05: {2}, 0x08: {3}, 0x09: {0x0F},
+				0x14: {5}, 0x15: {13},
+			}),
+			strings: []string{"Dell Inc.", "2.19.0 ", "01/10/2023"},
+		},
+		{
+			typ: smbios.TypeSystem, handle: 0x0100,
+			formatted: formatted(0x1B-4, map[int][]byte{
+				0x04: {1}, 0x05: {2}, 0x07: {3}, 0x08: uuid, 0x19: {4}, 0x1A: {5},
+			}),
+			strings: []string{"Dell Inc.", "PowerEdge R650", "ABC1234", "SKU=0A5F", "PowerEdge"},
+		},
+		{
+			typ: smbios.TypeChassis, handle: 0x0300,
+			// one contained element of 3 bytes, followed by the SKU string
+			formatted: formatted(0x19-4, map[int][]byte{
+				0x04: {1}, 0x05: {0x80 | 23}, 0x13: {1}, 0x14: {3}, 0x18: {2},
+			}),
+			strings: []string{"Dell Inc.", "SKU-CHASSIS"},
+		},
+		{
+			typ: smbios.TypeSystemSlot, handle: 0x0900,
+			formatted: formatted(0x11-4, map[int][]byte{
+				0x04: {1}, 0x05: {0xA5}, 0x07: {0x04}, 0x0D: le16(0), 0x0F: {0x3b}, 0x10: {0x00},
+			}),
+			strings: []string{"PCIe Slot 1"},
+		},
+		{
+			typ: smbios.TypeOEMStrings, handle: 0x0B00,
+			formatted: []byte{2},
+			strings:   []string{"Dell System", "5[0000]"},
+		},
+		{
+			typ: smbios.TypePhysicalMemoryArray, handle: 0x1000,
+			formatted: formatted(0x17-4, map[int][]byte{
+				0x04: {0x03}, 0x05: {0x03}, 0x06: {0x06}, 0x07: le32(0x80000000),
+				0x0D: le16(2), 0x0F: {0, 0, 0, 0, 0x20, 0, 0, 0},
+			}),
+		},
+		{
+			// An empty slot, followed by a 64GB DDR5 module using the
+			// extended size and speed fields.
+			typ: smbios.TypeMemoryDevice, handle: 0x1100,
+			formatted: formatted(0x28-4, map[int][]byte{
+				0x04: le16(0x1000), 0x0C: le16(0), 0x10: {1},
+			}),
+			strings: []string{"A1"},
+		},
+		{
+			typ: smbios.TypeMemoryDevice, handle: 0x1101,
+			formatted: formatted(0x5C-4, map[int][]byte{
+				0x04: le16(0x1000), 0x08: le16(80), 0x0A: le16(64),
+				0x0C: le16(0x7FFF), 0x0E: {0x09}, 0x10: {1}, 0x11: {2},
+				0x12: {0x22}, 0x15: le16(0xFFFF), 0x17: {3}, 0x18: {4},
+				0x1A: {5}, 0x1B: {2}, 0x1C: le32(65536), 0x20: le16(4800),
+				0x54: le32(5600),
+			}),
+			strings: []string{"A2", "P0 CHANNEL A", "Samsung", "03A1B2C3", "M321R8GA0BB0-CQKZJ"},
+		},
+	})
+
+	table, err := smbios.ParseTable(buildEntryPoint30(3, 3, uint32(len(data))), data)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(table.Structures) != 8 {
+		t.Fatalf("Expected 8 structures, but got %d", len(table.Structures))
+	}
+
+	bios := table.BIOS()
+	if bios.Vendor != "Dell Inc." || bios.Version != "2.19.0" || bios.ReleaseDate != "01/10/2023" {
+		t.Fatalf("unexpected BIOS: %+v", bios)
+	}
+	if bios.ROMSizeBytes != 1024*1024 || bios.SystemBIOSMajorRelease != 5 {
+		t.Fatalf("unexpected BIOS: %+v", bios)
+	}
+
+	system := table.System()
+	if system.ProductName != "PowerEdge R650" || system.SKUNumber != "SKU=0A5F" || system.Family != "PowerEdge" {
+		t.Fatalf("unexpected system: %+v", system)
+	}
+	if want := "00112233-4455-6677-8899-aabbccddeeff"; system.UUID != want {
+		t.Fatalf("Expected UUID %q, but got %q", want, system.UUID)
+	}
+
+	chassis := table.Chassis()
+	if len(chassis) != 1 || chassis[0].Type != 23 || !chassis[0].Lock || chassis[0].SKUNumber != "SKU-CHASSIS" {
+		t.Fatalf("unexpected chassis: %+v", chassis)
+	}
+
+	slots := table.SystemSlots()
+	if len(slots) != 1 || slots[0].PCIAddress != "0000:3b:00.0" || slots[0].CurrentUsage.String() != "In use" {
+		t.Fatalf("unexpected slots: %+v", slots)
+	}
+
+	if got, want := table.OEMStrings(), []string{"Dell System", "5[0000]"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected OEM strings %v, but got %v", want, got)
+	}
+
+	arrays := table.PhysicalMemoryArrays()
+	if len(arrays) != 1 || arrays[0].ErrorCorrection.String() != "Multi-bit ECC" || arrays[0].MaxCapacityBytes != 1<<37 {
+		t.Fatalf("unexpected memory arrays: %+v", arrays)
+	}
+
+	devs := table.MemoryDevices()
+	if len(devs) != 2 {
+		t.Fatalf("Expected 2 memory devices, but got %d", len(devs))
+	}
+	if devs[0].Installed || devs[0].DeviceLocator != "A1" {
+		t.Fatalf("Expected empty slot A1, but got %+v", devs[0])
+	}
+	dimm := devs[1]
+	if !dimm.Installed || dimm.SizeBytes != 64<<30 {
+		t.Fatalf("Expected 64GB module, but got %+v", dimm)
+	}
+	if dimm.Type.String() != "DDR5" || dimm.FormFactor.String() != "DIMM" {
+		t.Fatalf("Expected DDR5 DIMM, but got %s %s", dimm.Type, dimm.FormFactor)
+	}
+	if dimm.SpeedMTs != 5600 || dimm.ConfiguredSpeedMTs != 4800 || dimm.Rank != 2 {
+		t.Fatalf("unexpected speed/rank: %+v", dimm)
+	}
+	if dimm.TotalWidthBits != 80 || dimm.DataWidthBits != 64 || dimm.PartNumber != "M321R8GA0BB0-CQKZJ" {
+		t.Fatalf("unexpected memory device: %+v", dimm)
+	}
+	if table.StructureByHandle(0x1101) == nil {
+		t.Fatalf("Expected to find structure by handle 0x1101")
+	}
+}
+
+func TestMemoryDeviceWithoutExtendedSize(t *testing.T) {
+	// An SMBIOS 2.6 memory device is too short for the Extended Size field,
+	// so a size of 0x7FFF cannot be resolved.
+	data := buildTable([]testStructure{
+		{
+			typ: smbios.TypeMemoryDevice, handle: 0x1100,
+			formatted: formatted(0x1C-4, map[int][]byte{
+				0x04: le16(0x1000), 0x0C: le16(0x7FFF), 0x10: {1},
+			}),
+			strings: []string{"A1"},
+		},
+	})
+
+	table, err := smbios.ParseTable(buildEntryPoint21(2, 6, uint16(len(data)), 1), data)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	devs := table.MemoryDevices()
+	if len(devs) != 1 {
+		t.Fatalf("Expected 1 memory device, but got %d", len(devs))
+	}
+	if !devs[0].Installed || devs[0].SizeBytes != 0 {
+		t.Fatalf("Expected installed module of unknown size, but got %+v", devs[0])
+	}
+}
+
+func TestParseStructuresTruncated(t *testing.T) {
+	data := buildTable([]testStructure{
+		{typ: smbios.TypeBIOS, formatted: make([]byte, 0x14), strings: []string{"vendor"}},
+	})
+	if _, err := smbios.ParseStructures(data[:10], 0); !errors.Is(err, smbios.ErrTableTruncated) {
+		t.Fatalf("Expected ErrTableTruncated, but got %v", err)
+	}
+	// Strings without the terminating double NUL
+	if _, err := smbios.ParseStructures(data[:0x18+4], 0); !errors.Is(err, smbios.ErrTableTruncated) {
+		t.Fatalf("Expected ErrTableTruncated, but got %v", err)
+	}
+}
@@ -0,0 +1,712 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package smbios
+
+import (
+	"fmt"
+
+	"github.com/jaypipes/ghw/pkg/unitutil"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// BIOS describes the BIOS Information (type 0) structure.
+type BIOS struct {
+	Vendor      string `json:"vendor"`
+	Version     string `json:"version"`
+	ReleaseDate string `json:"release_date"`
+	// ROMSizeBytes is the size of the physical device containing the BIOS
+	ROMSizeBytes uint64 `json:"rom_size_bytes"`
+	// Characteristics is the raw BIOS Characteristics bit field
+	Characteristics uint64 `json:"characteristics"`
+	// SystemBIOSMajorRelease and SystemBIOSMinorRelease identify the release
+	// of the system BIOS. Both are 0xFF if not supported.
+	SystemBIOSMajorRelease uint8 `json:"system_bios_major_release"`
+	SystemBIOSMinorRelease uint8 `json:"system_bios_minor_release"`
+	// ECFirmwareMajorRelease and ECFirmwareMinorRelease identify the release
+	// of the embedded controller firmware. Both are 0xFF if not supported.
+	ECFirmwareMajorRelease uint8 `json:"ec_firmware_major_release"`
+	ECFirmwareMinorRelease uint8 `json:"ec_firmware_minor_release"`
+}
+
+func newBIOS(s *Structure) *BIOS {
+	b := &BIOS{
+		Vendor:                 s.StringAt(0x04),
+		Version:                s.StringAt(0x05),
+		ReleaseDate:            s.StringAt(0x08),
+		Characteristics:        s.QWord(0x0A),
+		SystemBIOSMajorRelease: 0xFF,
+		SystemBIOSMinorRelease: 0xFF,
+		ECFirmwareMajorRelease: 0xFF,
+		ECFirmwareMinorRelease: 0xFF,
+	}
+	romSize := s.Byte(0x09)
+	if romSize != 0xFF {
+		b.ROMSizeBytes = (uint64(romSize) + 1) * 64 * uint64(unitutil.KB)
+	} else if s.has(0x18, 2) {
+		// SMBIOS 3.1 Extended BIOS ROM Size. Bits 15:14 are the unit
+		// (00b for MB, 01b for GB) and bits 13:0 the size.
+		ext := s.Word(0x18)
+		size := uint64(ext & 0x3FFF)
+		switch ext >> 14 {
+		case 0:
+			b.ROMSizeBytes = size * uint64(unitutil.MB)
+		case 1:
+			b.ROMSizeBytes = size * uint64(unitutil.GB)
+		}
+	}
+	if s.has(0x17, 1) {
+		b.SystemBIOSMajorRelease = s.Byte(0x14)
+		b.SystemBIOSMinorRelease = s.Byte(0x15)
+		b.ECFirmwareMajorRelease = s.Byte(0x16)
+		b.ECFirmwareMinorRelease = s.Byte(0x17)
+	}
+	return b
+}
+
+// System describes the System Information (type 1) structure.
+type System struct {
+	Manufacturer string `json:"manufacturer"`
+	ProductName  string `json:"product_name"`
+	Version      string `json:"version"`
+	SerialNumber string `json:"serial_number"`
+	// UUID is the system UUID formatted as a lowercase string, or empty if
+	// the firmware reports it as not present or not set.
+	UUID       string `json:"uuid"`
+	WakeUpType uint8  `json:"wake_up_type"`
+	SKUNumber  string `json:"sku_number"`
+	Family     string `json:"family"`
+}
+
+func newSystem(ep *EntryPoint, s *Structure) *System {
+	sys := &System{
+		Manufacturer: s.StringAt(0x04),
+		ProductName:  s.StringAt(0x05),
+		Version:      s.StringAt(0x06),
+		SerialNumber: s.StringAt(0x07),
+	}
+	if s.has(0x08, 16) {
+		// As of SMBIOS 2.6 the first three UUID fields are encoded
+		// little-endian. Older tables use network byte order throughout.
+		sys.UUID = uuidString(s.Formatted[0x08:0x18], ep.atLeast(2, 6))
+	}
+	sys.WakeUpType = s.Byte(0x18)
+	sys.SKUNumber = s.StringAt(0x19)
+	sys.Family = s.StringAt(0x1A)
+	return sys
+}
+
+// uuidString formats a 16-byte SMBIOS UUID. An all-ones UUID means the UUID is
+// not present and an all-zeroes UUID means it is not set; both return an
+// empty string.
+func uuidString(b []byte, littleEndian bool) string {
+	allOnes, allZeroes := true, true
+	for _, c := range b {
+		if c != 0xFF {
+			allOnes = false
+		}
+		if c != 0x00 {
+			allZeroes = false
+		}
+	}
+	if allOnes || allZeroes {
+		return ""
+	}
+	if littleEndian {
+		return fmt.Sprintf(
+			"%02x%02x%02x%02x-%02x%02x-%02x%02x-%02x%02x-%02x%02x%02x%02x%02x%02x",
+			b[3], b[2], b[1], b[0], b[5], b[4], b[7], b[6],
+			b[8], b[9], b[10], b[11], b[12], b[13], b[14], b[15],
+		)
+	}
+	return fmt.Sprintf(
+		"%02x%02x%02x%02x-%02x%02x-%02x%02x-%02x%02x-%02x%02x%02x%02x%02x%02x",
+		b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7],
+		b[8], b[9], b[10], b[11], b[12], b[13], b[14], b[15],
+	)
+}
+
+// Baseboard describes the Baseboard (or Module) Information (type 2)
+// structure.
+type Baseboard struct {
+	Manufacturer      string `json:"manufacturer"`
+	Product           string `json:"product"`
+	Version           string `json:"version"`
+	SerialNumber      string `json:"serial_number"`
+	AssetTag          string `json:"asset_tag"`
+	FeatureFlags      uint8  `json:"feature_flags"`
+	LocationInChassis string `json:"location_in_chassis"`
+	ChassisHandle     uint16 `json:"chassis_handle"`
+	BoardType         uint8  `json:"board_type"`
+}
+
+func newBaseboard(s *Structure) *Baseboard {
+	return &Baseboard{
+		Manufacturer:      s.StringAt(0x04),
+		Product:           s.StringAt(0x05),
+		Version:           s.StringAt(0x06),
+		SerialNumber:      s.StringAt(0x07),
+		AssetTag:          s.StringAt(0x08),
+		FeatureFlags:      s.Byte(0x09),
+		LocationInChassis: s.StringAt(0x0A),
+		ChassisHandle:     s.Word(0x0B),
+		BoardType:         s.Byte(0x0D),
+	}
+}
+
+// Chassis describes the System Enclosure or Chassis (type 3) structure.
+type Chassis struct {
+	Manufacturer string `json:"manufacturer"`
+	// Type is the SMBIOS chassis type code, e.g. 3 for "Desktop" or 23 for
+	// "Rack Mount Chassis"
+	Type uint8 `json:"type"`
+	// Lock is true if a chassis lock is present
+	Lock             bool   `json:"lock"`
+	Version          string `json:"version"`
+	SerialNumber     string `json:"serial_number"`
+	AssetTag         string `json:"asset_tag"`
+	BootUpState      uint8  `json:"boot_up_state"`
+	PowerSupplyState uint8  `json:"power_supply_state"`
+	ThermalState     uint8  `json:"thermal_state"`
+	SecurityStatus   uint8  `json:"security_status"`
+	// HeightU is the height of the enclosure in rack units, or 0 if
+	// unspecified
+	HeightU       uint8  `json:"height_u"`
+	NumPowerCords uint8  `json:"num_power_cords"`
+	SKUNumber     string `json:"sku_number"`
+}
+
+func newChassis(s *Structure) *Chassis {
+	c := &Chassis{
+		Manufacturer:     s.StringAt(0x04),
+		Type:             s.Byte(0x05) & 0x7F,
+		Lock:             s.Byte(0x05)&0x80 != 0,
+		Version:          s.StringAt(0x06),
+		SerialNumber:     s.StringAt(0x07),
+		AssetTag:         s.StringAt(0x08),
+		BootUpState:      s.Byte(0x09),
+		PowerSupplyState: s.Byte(0x0A),
+		ThermalState:     s.Byte(0x0B),
+		SecurityStatus:   s.Byte(0x0C),
+		HeightU:          s.Byte(0x11),
+		NumPowerCords:    s.Byte(0x12),
+	}
+	// The SKU number follows the variable-length contained elements list.
+	if s.has(0x15, 0) {
+		count := int(s.Byte(0x13))
+		recLen := int(s.Byte(0x14))
+		c.SKUNumber = s.StringAt(0x15 + count*recLen)
+	}
+	return c
+}
+
+// ProcessorType is the type of a processor as reported in the Processor
+// Information (type 4) structure.
+type ProcessorType uint8
+
+var processorTypeString = map[ProcessorType]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "Central Processor",
+	0x04: "Math Processor",
+	0x05: "DSP Processor",
+	0x06: "Video Processor",
+}
+
+func (t ProcessorType) String() string {
+	if s, ok := processorTypeString[t]; ok {
+		return s
+	}
+	return util.UNKNOWN
+}
+
+// Processor describes the Processor Information (type 4) structure.
+type Processor struct {
+	SocketDesignation string        `json:"socket_designation"`
+	Type              ProcessorType `json:"type"`
+	// Family is the processor family code. Values above 0xFF are only
+	// reported through the Processor Family 2 field.
+	Family       uint16 `json:"family"`
+	Manufacturer string `json:"manufacturer"`
+	// ID is the raw processor ID. On x86 this is the CPUID leaf 1 EAX value
+	// in the low 32 bits and EDX in the high 32 bits.
+	ID               uint64 `json:"id"`
+	Version          string `json:"version"`
+	ExternalClockMHz uint16 `json:"external_clock_mhz"`
+	MaxSpeedMHz      uint16 `json:"max_speed_mhz"`
+	CurrentSpeedMHz  uint16 `json:"current_speed_mhz"`
+	// Populated is true if the socket has a processor installed
+	Populated    bool   `json:"populated"`
+	SerialNumber string `json:"serial_number"`
+	AssetTag     string `json:"asset_tag"`
+	PartNumber   string `json:"part_number"`
+	CoreCount    uint16 `json:"core_count"`
+	CoreEnabled  uint16 `json:"core_enabled"`
+	ThreadCount  uint16 `json:"thread_count"`
+	// Characteristics is the raw Processor Characteristics bit field
+	Characteristics uint16 `json:"characteristics"`
+}
+
+func newProcessor(s *Structure) *Processor {
+	p := &Processor{
+		SocketDesignation: s.StringAt(0x04),
+		Type:              ProcessorType(s.Byte(0x05)),
+		Family:            uint16(s.Byte(0x06)),
+		Manufacturer:      s.StringAt(0x07),
+		ID:                s.QWord(0x08),
+		Version:           s.StringAt(0x10),
+		ExternalClockMHz:  s.Word(0x12),
+		MaxSpeedMHz:       s.Word(0x14),
+		CurrentSpeedMHz:   s.Word(0x16),
+		Populated:         s.Byte(0x18)&0x40 != 0,
+		SerialNumber:      s.StringAt(0x20),
+		AssetTag:          s.StringAt(0x21),
+		PartNumber:        s.StringAt(0x22),
+		CoreCount:         uint16(s.Byte(0x23)),
+		CoreEnabled:       uint16(s.Byte(0x24)),
+		ThreadCount:       uint16(s.Byte(0x25)),
+		Characteristics:   s.Word(0x26),
+	}
+	// 0xFE in the Processor Family field means "see Processor Family 2"
+	if p.Family == 0xFE && s.has(0x28, 2) {
+		p.Family = s.Word(0x28)
+	}
+	// 0xFF in the count fields means "see the 16-bit count 2 fields"
+	if p.CoreCount == 0xFF && s.has(0x2A, 2) {
+		p.CoreCount = s.Word(0x2A)
+	}
+	if p.CoreEnabled == 0xFF && s.has(0x2C, 2) {
+		p.CoreEnabled = s.Word(0x2C)
+	}
+	if p.ThreadCount == 0xFF && s.has(0x2E, 2) {
+		p.ThreadCount = s.Word(0x2E)
+	}
+	return p
+}
+
+// PortConnector describes the Port Connector Information (type 8) structure.
+// The connector and port type fields contain the raw codes from the SMBIOS
+// specification's Connector Types and Port Types tables.
+type PortConnector struct {
+	InternalReference     string `json:"internal_reference"`
+	InternalConnectorType uint8  `json:"internal_connector_type"`
+	ExternalReference     string `json:"external_reference"`
+	ExternalConnectorType uint8  `json:"external_connector_type"`
+	PortType              uint8  `json:"port_type"`
+}
+
+func newPortConnector(s *Structure) *PortConnector {
+	return &PortConnector{
+		InternalReference:     s.StringAt(0x04),
+		InternalConnectorType: s.Byte(0x05),
+		ExternalReference:     s.StringAt(0x06),
+		ExternalConnectorType: s.Byte(0x07),
+		PortType:              s.Byte(0x08),
+	}
+}
+
+// SlotUsage describes whether a system slot is currently in use.
+type SlotUsage uint8
+
+var slotUsageString = map[SlotUsage]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "Available",
+	0x04: "In use",
+	0x05: "Unavailable",
+}
+
+func (u SlotUsage) String() string {
+	if s, ok := slotUsageString[u]; ok {
+		return s
+	}
+	return util.UNKNOWN
+}
+
+// SystemSlot describes the System Slots (type 9) structure.
+type SystemSlot struct {
+	Designation string `json:"designation"`
+	// Type is the raw slot type code, e.g. 0xA5 for "PCI Express"
+	Type            uint8     `json:"type"`
+	DataBusWidth    uint8     `json:"data_bus_width"`
+	CurrentUsage    SlotUsage `json:"current_usage"`
+	Length          uint8     `json:"length"`
+	ID              uint16    `json:"id"`
+	Characteristics uint16    `json:"characteristics"`
+	// PCIAddress is the PCI address of the device in the slot, in the
+	// "domain:bus:device.function" form used by the pci package, or empty if
+	// the firmware does not report one.
+	PCIAddress string `json:"pci_address,omitempty"`
+}
+
+func newSystemSlot(s *Structure) *SystemSlot {
+	slot := &SystemSlot{
+		Designation:     s.StringAt(0x04),
+		Type:            s.Byte(0x05),
+		DataBusWidth:    s.Byte(0x06),
+		CurrentUsage:    SlotUsage(s.Byte(0x07)),
+		Length:          s.Byte(0x08),
+		ID:              s.Word(0x09),
+		Characteristics: uint16(s.Byte(0x0B)) | uint16(s.Byte(0x0C))<<8,
+	}
+	if s.has(0x10, 1) {
+		segment := s.Word(0x0D)
+		bus := s.Byte(0x0F)
+		devFn := s.Byte(0x10)
+		// 0xFF for the bus and device/function means the fields are
+		// not applicable to this slot.
+		if !(bus == 0xFF && devFn == 0xFF) && segment != 0xFFFF {
+			slot.PCIAddress = fmt.Sprintf(
+				"%04x:%02x:%02x.%x", segment, bus, devFn>>3, devFn&0x7,
+			)
+		}
+	}
+	return slot
+}
+
+// MemoryErrorCorrection is the type of error correction used by a physical
+// memory array.
+type MemoryErrorCorrection uint8
+
+var memoryErrorCorrectionString = map[MemoryErrorCorrection]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "None",
+	0x04: "Parity",
+	0x05: "Single-bit ECC",
+	0x06: "Multi-bit ECC",
+	0x07: "CRC",
+}
+
+func (c MemoryErrorCorrection) String() string {
+	if s, ok := memoryErrorCorrectionString[c]; ok {
+		return s
+	}
+	return util.UNKNOWN
+}
+
+// Detects returns true if the error correction type is able to at least
+// detect memory errors (parity, ECC or CRC).
+func (c MemoryErrorCorrection) Detects() bool {
+	return c >= 0x04 && c <= 0x07
+}
+
+// MemoryArrayUseSystemMemory is the PhysicalMemoryArray Use value of an
+// array holding system memory, as opposed to video or flash memory.
+const MemoryArrayUseSystemMemory uint8 = 0x03
+
+// PhysicalMemoryArray describes the Physical Memory Array (type 16)
+// structure: a collection of memory devices that operate together.
+type PhysicalMemoryArray struct {
+	Handle   uint16 `json:"handle"`
+	Location uint8  `json:"location"`
+	// Use is the function for which the array is used, see
+	// MemoryArrayUseSystemMemory
+	Use             uint8                 `json:"use"`
+	ErrorCorrection MemoryErrorCorrection `json:"error_correction"`
+	// MaxCapacityBytes is the maximum memory capacity the array supports
+	MaxCapacityBytes uint64 `json:"max_capacity_bytes"`
+	// NumDevices is the number of slots or sockets available to memory
+	// devices in this array
+	NumDevices uint16 `json:"num_devices"`
+}
+
+func newPhysicalMemoryArray(s *Structure) *PhysicalMemoryArray {
+	a := &PhysicalMemoryArray{
+		Handle:          s.Handle,
+		Location:        s.Byte(0x04),
+		Use:             s.Byte(0x05),
+		ErrorCorrection: MemoryErrorCorrection(s.Byte(0x06)),
+		NumDevices:      s.Word(0x0D),
+	}
+	maxKB := s.DWord(0x07)
+	if maxKB == 0x80000000 && s.has(0x0F, 8) {
+		a.MaxCapacityBytes = s.QWord(0x0F)
+	} else {
+		a.MaxCapacityBytes = uint64(maxKB) * uint64(unitutil.KB)
+	}
+	return a
+}
+
+// MemoryType is the type of a memory device, e.g. DDR4.
+type MemoryType uint8
+
+const (
+	// MemoryTypeLogicalNonVolatile is the memory type SMBIOS reports for
+	// NVDIMMs and other logical non-volatile devices.
+	MemoryTypeLogicalNonVolatile MemoryType = 0x1F
+)
+
+var memoryTypeString = map[MemoryType]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "DRAM",
+	0x04: "EDRAM",
+	0x05: "VRAM",
+	0x06: "SRAM",
+	0x07: "RAM",
+	0x08: "ROM",
+	0x09: "Flash",
+	0x0A: "EEPROM",
+	0x0B: "FEPROM",
+	0x0C: "EPROM",
+	0x0D: "CDRAM",
+	0x0E: "3DRAM",
+	0x0F: "SDRAM",
+	0x10: "SGRAM",
+	0x11: "RDRAM",
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x14: "DDR2 FB-DIMM",
+	0x18: "DDR3",
+	0x19: "FBD2",
+	0x1A: "DDR4",
+	0x1B: "LPDDR",
+	0x1C: "LPDDR2",
+	0x1D: "LPDDR3",
+	0x1E: "LPDDR4",
+	0x1F: "Logical non-volatile device",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
+
+func (t MemoryType) String() string {
+	if s, ok := memoryTypeString[t]; ok {
+		return s
+	}
+	return util.UNKNOWN
+}
+
+// MemoryFormFactor is the physical form factor of a memory device, e.g. DIMM.
+type MemoryFormFactor uint8
+
+var memoryFormFactorString = map[MemoryFormFactor]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "SIMM",
+	0x04: "SIP",
+	0x05: "Chip",
+	0x06: "DIP",
+	0x07: "ZIP",
+	0x08: "Proprietary Card",
+	0x09: "DIMM",
+	0x0A: "TSOP",
+	0x0B: "Row of chips",
+	0x0C: "RIMM",
+	0x0D: "SODIMM",
+	0x0E: "SRIMM",
+	0x0F: "FB-DIMM",
+	0x10: "Die",
+	0x11: "CAMM",
+}
+
+func (f MemoryFormFactor) String() string {
+	if s, ok := memoryFormFactorString[f]; ok {
+		return s
+	}
+	return util.UNKNOWN
+}
+
+// MemoryDevice describes the Memory Device (type 17) structure: a single
+// memory slot and the module, if any, installed in it.
+type MemoryDevice struct {
+	Handle uint16 `json:"handle"`
+	// ArrayHandle is the handle of the PhysicalMemoryArray this device
+	// belongs to
+	ArrayHandle uint16 `json:"array_handle"`
+	// TotalWidthBits is the total width, in bits, including any error
+	// correction bits, or 0 if unknown
+	TotalWidthBits uint16 `json:"total_width_bits"`
+	// DataWidthBits is the data width, in bits, or 0 if unknown
+	DataWidthBits uint16 `json:"data_width_bits"`
+	// Installed is false if the slot is empty
+	Installed bool `json:"installed"`
+	// SizeBytes is the size of the installed module, or 0 if the slot is
+	// empty or the size is unknown
+	SizeBytes     uint64           `json:"size_bytes"`
+	FormFactor    MemoryFormFactor `json:"form_factor"`
+	DeviceSet     uint8            `json:"device_set"`
+	DeviceLocator string           `json:"device_locator"`
+	BankLocator   string           `json:"bank_locator"`
+	Type          MemoryType       `json:"type"`
+	// TypeDetail is the raw Type Detail bit field
+	TypeDetail uint16 `json:"type_detail"`
+	// SpeedMTs is the maximum capable speed in megatransfers per second, or
+	// 0 if unknown
+	SpeedMTs     uint32 `json:"speed_mts"`
+	Manufacturer string `json:"manufacturer"`
+	SerialNumber string `json:"serial_number"`
+	AssetTag     string `json:"asset_tag"`
+	PartNumber   string `json:"part_number"`
+	// Rank is the number of ranks, or 0 if unknown
+	Rank uint8 `json:"rank"`
+	// ConfiguredSpeedMTs is the configured speed in megatransfers per second,
+	// or 0 if unknown
+	ConfiguredSpeedMTs uint32 `json:"configured_speed_mts"`
+	// ConfiguredVoltageMillivolts is the configured voltage, or 0 if unknown
+	ConfiguredVoltageMillivolts uint16 `json:"configured_voltage_millivolts"`
+}
+
+func newMemoryDevice(s *Structure) *MemoryDevice {
+	d := &MemoryDevice{
+		Handle:        s.Handle,
+		ArrayHandle:   s.Word(0x04),
+		FormFactor:    MemoryFormFactor(s.Byte(0x0E)),
+		DeviceSet:     s.Byte(0x0F),
+		DeviceLocator: s.StringAt(0x10),
+		BankLocator:   s.StringAt(0x11),
+		Type:          MemoryType(s.Byte(0x12)),
+		TypeDetail:    s.Word(0x13),
+		Manufacturer:  s.StringAt(0x17),
+		SerialNumber:  s.StringAt(0x18),
+		AssetTag:      s.StringAt(0x19),
+		PartNumber:    s.StringAt(0x1A),
+		Rank:          s.Byte(0x1B) & 0x0F,
+	}
+	if w := s.Word(0x08); w != 0xFFFF {
+		d.TotalWidthBits = w
+	}
+	if w := s.Word(0x0A); w != 0xFFFF {
+		d.DataWidthBits = w
+	}
+
+	// A size of 0 means no module is installed and 0xFFFF means the size is
+	// unknown. 0x7FFF means the size is in the Extended Size field. Otherwise
+	// bit 15 selects the granularity: 0 for MB, 1 for KB.
+	size := s.Word(0x0C)
+	switch {
+	case size == 0:
+	case size == 0xFFFF:
+		d.Installed = true
+	case size == 0x7FFF:
+		// Without the Extended Size field the size is unknown.
+		d.Installed = true
+		if s.has(0x1C, 4) {
+			d.SizeBytes = uint64(s.DWord(0x1C)&0x7FFFFFFF) * uint64(unitutil.MB)
+		}
+	case size&0x8000 != 0:
+		d.Installed = true
+		d.SizeBytes = uint64(size&0x7FFF) * uint64(unitutil.KB)
+	default:
+		d.Installed = true
+		d.SizeBytes = uint64(size) * uint64(unitutil.MB)
+	}
+
+	// Speeds of 0xFFFF mean the value is in the 32-bit extended field added
+	// in SMBIOS 3.3.
+	if speed := s.Word(0x15); speed == 0xFFFF && s.has(0x54, 4) {
+		d.SpeedMTs = s.DWord(0x54)
+	} else if speed != 0xFFFF {
+		d.SpeedMTs = uint32(speed)
+	}
+	if speed := s.Word(0x20); speed == 0xFFFF && s.has(0x58, 4) {
+		d.ConfiguredSpeedMTs = s.DWord(0x58)
+	} else if speed != 0xFFFF {
+		d.ConfiguredSpeedMTs = uint32(speed)
+	}
+	d.ConfiguredVoltageMillivolts = s.Word(0x26)
+	return d
+}
+
+// BIOS returns the decoded BIOS Information structure, or nil if the table
+// has none.
+func (t *Table) BIOS() *BIOS {
+	s := t.first(TypeBIOS)
+	if s == nil {
+		return nil
+	}
+	return newBIOS(s)
+}
+
+// System returns the decoded System Information structure, or nil if the
+// table has none.
+func (t *Table) System() *System {
+	s := t.first(TypeSystem)
+	if s == nil {
+		return nil
+	}
+	return newSystem(t.EntryPoint, s)
+}
+
+// Baseboards returns the decoded Baseboard Information structures.
+func (t *Table) Baseboards() []*Baseboard {
+	out := []*Baseboard{}
+	for _, s := range t.StructuresByType(TypeBaseboard) {
+		out = append(out, newBaseboard(s))
+	}
+	return out
+}
+
+// Chassis returns the decoded System Enclosure or Chassis structures.
+func (t *Table) Chassis() []*Chassis {
+	out := []*Chassis{}
+	for _, s := range t.StructuresByType(TypeChassis) {
+		out = append(out, newChassis(s))
+	}
+	return out
+}
+
+// Processors returns the decoded Processor Information structures, one per
+// processor socket.
+func (t *Table) Processors() []*Processor {
+	out := []*Processor{}
+	for _, s := range t.StructuresByType(TypeProcessor) {
+		out = append(out, newProcessor(s))
+	}
+	return out
+}
+
+// PortConnectors returns the decoded Port Connector Information structures.
+func (t *Table) PortConnectors() []*PortConnector {
+	out := []*PortConnector{}
+	for _, s := range t.StructuresByType(TypePortConnector) {
+		out = append(out, newPortConnector(s))
+	}
+	return out
+}
+
+// SystemSlots returns the decoded System Slots structures.
+func (t *Table) SystemSlots() []*SystemSlot {
+	out := []*SystemSlot{}
+	for _, s := range t.StructuresByType(TypeSystemSlot) {
+		out = append(out, newSystemSlot(s))
+	}
+	return out
+}
+
+// OEMStrings returns the strings from all OEM Strings structures.
+func (t *Table) OEMStrings() []string {
+	out := []string{}
+	for _, s := range t.StructuresByType(TypeOEMStrings) {
+		count := int(s.Byte(0x04))
+		for x := 1; x <= count && x <= len(s.Strings); x++ {
+			out = append(out, s.Strings[x-1])
+		}
+	}
+	return out
+}
+
+// PhysicalMemoryArrays returns the decoded Physical Memory Array structures.
+func (t *Table) PhysicalMemoryArrays() []*PhysicalMemoryArray {
+	out := []*PhysicalMemoryArray{}
+	for _, s := range t.StructuresByType(TypePhysicalMemoryArray) {
+		out = append(out, newPhysicalMemoryArray(s))
+	}
+	return out
+}
+
+// MemoryDevices returns the decoded Memory Device structures, one per memory
+// slot, including empty slots.
+func (t *Table) MemoryDevices() []*MemoryDevice {
+	out := []*MemoryDevice{}
+	for _, s := range t.StructuresByType(TypeMemoryDevice) {
+		out = append(out, newMemoryDevice(s))
+	}
+	return out
+}
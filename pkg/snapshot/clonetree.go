@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/jaypipes/ghw/internal/config"
 	"github.com/jaypipes/ghw/internal/log"
 )
 
@@ -49,6 +50,9 @@ func CloneTreeInto(
 func ExpectedCloneContent(ctx context.Context) ([]string, error) {
 	fileSpecs := ExpectedCloneStaticContent()
 	fileSpecs = append(fileSpecs, ExpectedCloneNetContent()...)
+	if config.SnapshotNetAddressing(ctx) {
+		fileSpecs = append(fileSpecs, ExpectedCloneNetAddressingContent()...)
+	}
 	fileSpecs = append(fileSpecs, ExpectedCloneUSBContent()...)
 	pciContent, err := ExpectedClonePCIContent(ctx)
 	if err != nil {
@@ -56,6 +60,9 @@ func ExpectedCloneContent(ctx context.Context) ([]string, error) {
 	}
 	fileSpecs = append(fileSpecs, pciContent...)
 	fileSpecs = append(fileSpecs, ExpectedCloneGPUContent()...)
+	fileSpecs = append(fileSpecs, ExpectedClonePMEMContent()...)
+	fileSpecs = append(fileSpecs, ExpectedCloneNVMeContent()...)
+	fileSpecs = append(fileSpecs, ExpectedCloneSCSIContent()...)
 	return fileSpecs, nil
 }
 
@@ -16,6 +16,68 @@ import (
 	"github.com/jaypipes/ghw/internal/log"
 )
 
+// ExpectedCloneNVMeContent returns a slice of glob patterns for the NVMe
+// controllers and subsystems ghw cares about. The namespace block devices
+// themselves are cloned along with the other block devices.
+func ExpectedCloneNVMeContent() []string {
+	ctrlEntries := []string{
+		"cntlid",
+		"model",
+		"serial",
+		"firmware_rev",
+		"transport",
+		"address",
+		"state",
+		"subsysnqn",
+		// the attributes of the namespaces attached to the controller
+		"nvme*n*/ana_state",
+		"nvme*n*/nsid",
+		"nvme*n*/wwid",
+		"nvme*n*/metadata_bytes",
+	}
+	// the controller links of a subsystem are enough to map the controllers
+	// to their subsystem
+	subsysEntries := []string{
+		"nvme*",
+	}
+	fileSpecs := cloneContentByClass("nvme", ctrlEntries, filterNone, filterNone)
+	return append(fileSpecs, cloneContentByClass("nvme-subsystem", subsysEntries, filterNone, filterNone)...)
+}
+
+// ExpectedCloneSCSIContent returns a slice of glob patterns for the SCSI
+// hosts, SAS expanders and enclosures ghw cares about. The SCSI devices of
+// the disks are found from the path of their block device directory.
+func ExpectedCloneSCSIContent() []string {
+	hostEntries := []string{
+		"proc_name",
+		"host_sas_address",
+	}
+	sasDeviceEntries := []string{
+		"sas_address",
+	}
+	expanderEntries := []string{
+		"vendor_id",
+		"product_id",
+		"product_rev",
+	}
+	// the device link of each slot, along with the block directory of the
+	// SCSI device it points to, maps the slot to its disk
+	enclosureEntries := []string{
+		"id",
+		"device/vendor",
+		"device/model",
+		"*/slot",
+		"*/status",
+		"*/locate",
+		"*/fault",
+		"*/device",
+	}
+	fileSpecs := cloneContentByClass("scsi_host", hostEntries, filterNone, filterNone)
+	fileSpecs = append(fileSpecs, cloneContentByClass("sas_device", sasDeviceEntries, filterNone, filterNone)...)
+	fileSpecs = append(fileSpecs, cloneContentByClass("sas_expander", expanderEntries, filterNone, filterNone)...)
+	return append(fileSpecs, cloneContentByClass("enclosure", enclosureEntries, filterNone, filterNone)...)
+}
+
 func createBlockDevices(
 	ctx context.Context,
 	buildDir string,
@@ -104,6 +166,22 @@ func createBlockDeviceDir(
 			// interested in like "subsystem"
 			continue
 		} else if fi.IsDir() {
+			switch fname {
+			case "holders", "slaves":
+				// The links of these directories build the storage stack,
+				// e.g. the members of an MD RAID array
+				if err = createLinksDir(ctx, filepath.Join(buildDeviceDir, fname), fp); err != nil {
+					return err
+				}
+				continue
+			case "dm", "md":
+				// The device-mapper and MD RAID attributes, along with the
+				// dev-$MEMBER directories of the MD RAID members
+				if err = createAttrsDir(ctx, filepath.Join(buildDeviceDir, fname), fp, true); err != nil {
+					return err
+				}
+				continue
+			}
 			if strings.HasPrefix(fname, devName) {
 				// We're interested in are the directories that begin with the
 				// block device name. These are directories with information
@@ -149,9 +227,10 @@ func createBlockDeviceDir(
 			f.Close()
 		}
 	}
-	// There is a special file $DEVICE_DIR/queue/rotational that, for some hard
-	// drives, contains a 1 or 0 indicating whether the device is a spinning
-	// disk or not
+	// The $DEVICE_DIR/queue directory contains the request queue settings
+	// and limits, e.g. queue/rotational which, for some hard drives,
+	// contains a 1 or 0 indicating whether the device is a spinning disk or
+	// not. Its iosched subdirectory holds scheduler tunables we don't need.
 	srcQueueDir := filepath.Join(
 		srcDeviceDir,
 		"queue",
@@ -160,25 +239,9 @@ func createBlockDeviceDir(
 		buildDeviceDir,
 		"queue",
 	)
-	err = os.MkdirAll(buildQueueDir, os.ModePerm)
-	if err != nil {
-		return err
-	}
-	fp := filepath.Join(srcQueueDir, "rotational")
-	buf, err := os.ReadFile(fp)
-	if err != nil {
-		return err
-	}
-	targetPath := filepath.Join(buildQueueDir, "rotational")
-	log.Debug(ctx, "creating %s", targetPath)
-	f, err := os.Create(targetPath)
-	if err != nil {
+	if err = createAttrsDir(ctx, buildQueueDir, srcQueueDir, false); err != nil {
 		return err
 	}
-	if _, err = f.Write(buf); err != nil {
-		return err
-	}
-	f.Close()
 
 	return nil
 }
@@ -206,7 +269,12 @@ func createPartitionDir(
 			// point to information we aren't interested in like "subsystem"
 			continue
 		} else if fi.IsDir() {
-			// The subdirectories in the partition directory are not
+			if fname == "holders" {
+				if err = createLinksDir(ctx, filepath.Join(buildPartitionDir, fname), fp); err != nil {
+					return err
+				}
+			}
+			// The other subdirectories in the partition directory are not
 			// interesting for us. They have information about power events and
 			// traces
 			continue
@@ -232,3 +300,78 @@ func createPartitionDir(
 	}
 	return nil
 }
+
+// createLinksDir recreates in the build filesystem the symlinks of a holders
+// or slaves directory. Only the link names matter, they are the names of the
+// devices.
+func createLinksDir(
+	ctx context.Context,
+	buildDir string,
+	srcDir string,
+) error {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(buildDir, os.ModePerm); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		dest, err := os.Readlink(filepath.Join(srcDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		linkPath := filepath.Join(buildDir, entry.Name())
+		log.Debug(ctx, "linking %s to %s", linkPath, dest)
+		if err = os.Symlink(dest, linkPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createAttrsDir copies the regular files of an attribute directory into the
+// build filesystem, along with the ones of its subdirectories when recurse is
+// true. Symlinks are ignored.
+func createAttrsDir(
+	ctx context.Context,
+	buildDir string,
+	srcDir string,
+	recurse bool,
+) error {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(buildDir, os.ModePerm); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fp := filepath.Join(srcDir, entry.Name())
+		targetPath := filepath.Join(buildDir, entry.Name())
+		if entry.IsDir() {
+			if !recurse {
+				continue
+			}
+			if err = createAttrsDir(ctx, targetPath, fp, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		buf, err := os.ReadFile(fp)
+		if err != nil {
+			// some attributes are write-only, e.g. /sys/block/md0/md/new_dev,
+			// or fail to read depending on the array personality
+			log.Debug(ctx, "failed reading %q - skipped: %v", fp, err)
+			continue
+		}
+		log.Debug(ctx, "creating %s", targetPath)
+		if err = os.WriteFile(targetPath, buf, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
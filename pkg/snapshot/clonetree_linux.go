@@ -37,11 +37,37 @@ func ExpectedCloneStaticContent() []string {
 		"/proc/cpuinfo",
 		"/proc/meminfo",
 		"/proc/self/mounts",
+		"/proc/self/cgroup",
 		"/sys/devices/system/cpu/cpu*/cache/index*/*",
 		"/sys/devices/system/cpu/cpu*/topology/*",
+		"/sys/devices/system/cpu/cpu*/cpufreq",
+		"/sys/devices/system/cpu/cpu*/cpu_capacity",
+		"/sys/devices/system/cpu/cpu*/acpi_cppc/nominal_freq",
+		"/sys/devices/system/cpu/cpufreq/boost",
+		"/sys/devices/system/cpu/cpufreq/policy*/*",
+		"/sys/devices/system/cpu/intel_pstate/no_turbo",
+		"/sys/devices/system/cpu/vulnerabilities/*",
+		"/sys/devices/system/cpu/online",
+		"/sys/devices/system/cpu/offline",
+		"/sys/devices/system/cpu/possible",
+		"/sys/devices/system/cpu/present",
+		"/sys/devices/system/cpu/isolated",
+		"/sys/devices/system/cpu/nohz_full",
+		"/sys/fs/cgroup/cpuset.cpus.effective",
+		"/sys/fs/cgroup/cpuset.mems.effective",
+		"/sys/fs/cgroup/cpuset/cpuset.effective_cpus",
+		"/sys/fs/cgroup/cpuset/cpuset.effective_mems",
+		"/sys/devices/cpu_atom/cpus",
+		"/sys/devices/cpu_core/cpus",
 		"/sys/devices/system/memory/block_size_bytes",
 		"/sys/devices/system/memory/memory*/online",
 		"/sys/devices/system/memory/memory*/state",
+		"/sys/devices/system/edac/mc/mc*/*_count",
+		"/sys/devices/system/edac/mc/mc*/mc_name",
+		"/sys/devices/system/edac/mc/mc*/size_mb",
+		"/sys/devices/system/edac/mc/mc*/seconds_since_reset",
+		"/sys/devices/system/edac/mc/mc*/dimm*/*",
+		"/sys/devices/system/edac/mc/mc*/rank*/*",
 		"/sys/devices/system/node/has_*",
 		"/sys/devices/system/node/online",
 		"/sys/devices/system/node/possible",
@@ -49,12 +75,17 @@ func ExpectedCloneStaticContent() []string {
 		"/sys/devices/system/node/node*/cpu*/online",
 		"/sys/devices/system/node/node*/distance",
 		"/sys/devices/system/node/node*/meminfo",
+		"/sys/devices/system/node/node*/numastat",
+		"/sys/devices/system/node/node*/access*/initiators/*",
 		"/sys/devices/system/node/node*/memory*",
 		"/sys/devices/system/node/node*/hugepages/hugepages-*/*",
+		"/sys/devices/virtual/memory_tiering/memory_tier*/nodelist",
 		"/sys/class/watchdog/*",
 		"/sys/class/tpm/tpm*/caps",
 		"/sys/class/tpm/tpm*/tpm_version_major",
 		"/sys/class/tpm/tpm*/device/vendor",
+		"/sys/firmware/dmi/tables/smbios_entry_point",
+		"/sys/firmware/dmi/tables/DMI",
 	}
 }
 
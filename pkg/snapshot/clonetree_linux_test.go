@@ -15,6 +15,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jaypipes/ghw/internal/config"
+	"github.com/jaypipes/ghw/internal/testutil"
+	"github.com/jaypipes/ghw/pkg/net"
 	"github.com/jaypipes/ghw/pkg/snapshot"
 )
 
@@ -100,6 +103,92 @@ func TestCloneSystemTree(t *testing.T) {
 	}
 }
 
+func TestCloneNetAddressing(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	// the addresses and routes of the host are only cloned on request
+	ctx := context.TODO()
+	specs, err := snapshot.ExpectedCloneContent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range specs {
+		if strings.HasPrefix(spec, "/proc/net/") {
+			t.Fatalf("Expected no /proc/net content by default, but got %q", spec)
+		}
+	}
+
+	ctx = config.WithSnapshotNetAddressing()(ctx)
+	cloneRoot := t.TempDir()
+	if err := snapshot.CloneTreeInto(ctx, cloneRoot); err != nil {
+		t.Fatal(err)
+	}
+	tarball := filepath.Join(t.TempDir(), "snapshot.tgz")
+	if err := snapshot.PackFrom(ctx, tarball, cloneRoot); err != nil {
+		t.Fatal(err)
+	}
+	root, err := snapshot.Unpack(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	missing, err := snapshot.ValidateClonedTree(snapshot.ExpectedCloneNetAddressingContent(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) > 0 && areEntriesOnSysfs(missing) {
+		t.Fatalf("Expected content %#v missing from the snapshot %q", missing, root)
+	}
+
+	// the snapshot gives the default routes and the IPv6 addresses of the
+	// live system, and the IPv4 addresses it can attribute to a NIC
+	live, err := net.New(config.WithDisableTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := net.New(config.WithChroot(root), config.WithDisableTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]*net.NIC{}
+	for _, nic := range live.NICs {
+		byName[nic.Name] = nic
+	}
+	split := func(addrs []*net.NICAddress) (map[string]bool, []*net.NICAddress) {
+		ipv4 := map[string]bool{}
+		ipv6 := []*net.NICAddress{}
+		for _, a := range addrs {
+			if strings.Contains(a.IP, ":") {
+				ipv6 = append(ipv6, a)
+			} else {
+				ipv4[a.String()] = true
+			}
+		}
+		return ipv4, ipv6
+	}
+	for _, nic := range snap.NICs {
+		liveNIC, ok := byName[nic.Name]
+		if !ok {
+			continue
+		}
+		if nic.IPv4DefaultRoute != liveNIC.IPv4DefaultRoute || nic.IPv6DefaultRoute != liveNIC.IPv6DefaultRoute {
+			t.Fatalf("Expected the default routes of %s to be %+v, but got %+v", nic.Name, liveNIC, nic)
+		}
+		liveIPv4, liveIPv6 := split(liveNIC.Addresses)
+		ipv4, ipv6 := split(nic.Addresses)
+		if !reflect.DeepEqual(ipv6, liveIPv6) {
+			t.Fatalf("Expected the IPv6 addresses of %s to be %v, but got %v", nic.Name, liveIPv6, ipv6)
+		}
+		for a := range ipv4 {
+			if !liveIPv4[a] {
+				t.Fatalf("Expected the IPv4 addresses of %s in %v, but got %s", nic.Name, liveIPv4, a)
+			}
+		}
+	}
+}
+
 func areEntriesOnSysfs(sysfsEntries []string) bool {
 	// turns out some ISA bridges do not actually expose the driver entry. The reason is not clear.
 	// So let's check if we actually have the entry we were looking for on sysfs. If so, we
@@ -136,3 +225,50 @@ func includedInto(s string, items []string) bool {
 	}
 	return false
 }
+
+func TestExpectedCloneNVMeContent(t *testing.T) {
+	root := t.TempDir()
+	ctrlDir := filepath.Join("sys", "devices", "pci0000:00", "0000:00:1d.0", "0000:3d:00.0", "nvme", "nvme0")
+	files := map[string]string{
+		"cntlid":                 "1",
+		"model":                  "Samsung SSD 980 PRO 1TB",
+		"transport":              "pcie",
+		"nvme0n1/nsid":           "1",
+		"nvme0n1/wwid":           "eui.002538b311b0a1e2",
+		"nvme0n1/metadata_bytes": "0",
+	}
+	testutil.WriteFiles(t, filepath.Join(root, ctrlDir), files)
+	classDir := filepath.Join(root, "sys", "class", "nvme")
+	if err := os.MkdirAll(classDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", "..", "..", ctrlDir), filepath.Join(classDir, "nvme0")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the clone specs are relative to the root of the filesystem to clone
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	cloneRoot := t.TempDir()
+	if err := snapshot.CopyFilesInto(context.TODO(), snapshot.ExpectedCloneNVMeContent(), cloneRoot, nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(cloneRoot, ctrlDir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be cloned, but got %v", name, err)
+		}
+		if strings.TrimSpace(string(data)) != content {
+			t.Fatalf("Expected %s to contain %q, but got %q", name, content, data)
+		}
+	}
+}
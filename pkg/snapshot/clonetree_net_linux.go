@@ -18,6 +18,18 @@ func ExpectedCloneNetContent() []string {
 	ifaceEntries := []string{
 		"addr_assign_type",
 		// intentionally avoid to clone "address" to avoid to leak any host-idenfifiable data.
+		// For the same reason, the IP addresses and routes in /proc/net are only cloned on
+		// request, see ExpectedCloneNetAddressingContent.
+		"carrier",
+		"compat/devlink/mode",
+		// the link to the backing device, whose SR-IOV attributes the PCI
+		// content holds
+		"device",
+		"ifindex",
+		"master",
+		"mtu",
+		"operstate",
+		"statistics/*",
 	}
 
 	filterLink := func(linkDest string) bool {
@@ -26,3 +38,16 @@ func ExpectedCloneNetContent() []string {
 
 	return cloneContentByClass("net", ifaceEntries, filterNone, filterLink)
 }
+
+// ExpectedCloneNetAddressingContent returns a slice of strings pertaining to the IP addresses
+// and routes of the host, which ghw reads the addressing of the NICs from when examining a
+// snapshot. They identify the host, hence they are only cloned when requested with
+// the WithSnapshotNetAddressing option.
+func ExpectedCloneNetAddressingContent() []string {
+	return []string{
+		"/proc/net/fib_trie",
+		"/proc/net/if_inet6",
+		"/proc/net/route",
+		"/proc/net/ipv6_route",
+	}
+}
@@ -75,8 +75,12 @@ func scanPCIDeviceRoot(
 		"local_cpulist",
 		"modalias",
 		"numa_node",
+		"physfn",
 		"revision",
+		"sriov_numvfs",
+		"sriov_totalvfs",
 		"vendor",
+		"virtfn*",
 	}
 	entries, err := os.ReadDir(root)
 	if err != nil {
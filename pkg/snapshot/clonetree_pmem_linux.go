@@ -0,0 +1,83 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ExpectedClonePMEMContent returns a slice of glob patterns for the
+// persistent memory and CXL devices ghw cares about. Like for the device
+// classes, the entries of /sys/bus/{nd,cxl}/devices are symbolic links to the
+// actual device directories, whose attributes we clone explicitly: globbing
+// all the files of a device would also try to read write-only attributes.
+func ExpectedClonePMEMContent() []string {
+	ndEntries := []string{
+		"devtype",
+		"size",
+		"available_size",
+		"persistence_domain",
+		"numa_node",
+		"target_node",
+		"mappings",
+		"mapping*",
+		"mode",
+		"uuid",
+		"holder",
+		"state",
+		"nfit/vendor",
+		"nfit/serial",
+		"nfit/flags",
+		"block/*/dev",
+		"dax*.*/dev",
+	}
+	cxlEntries := []string{
+		"devtype",
+		"serial",
+		"firmware_version",
+		"numa_node",
+		"ram/size",
+		"pmem/size",
+		"uport",
+		"dport*",
+		"start",
+		"size",
+		"interleave_ways",
+		"interleave_granularity",
+		"target_list",
+		"mode",
+		"region",
+	}
+	fileSpecs := cloneContentByBus("nd", ndEntries)
+	return append(fileSpecs, cloneContentByBus("cxl", cxlEntries)...)
+}
+
+// cloneContentByBus is the /sys/bus/$BUS/devices counterpart of
+// cloneContentByClass, without filtering.
+func cloneContentByBus(bus string, subEntries []string) []string {
+	var fileSpecs []string
+
+	sysBus := filepath.Join("sys", "bus", bus, "devices")
+	entries, err := os.ReadDir(sysBus)
+	if err != nil {
+		return fileSpecs
+	}
+	for _, entry := range entries {
+		devPath := filepath.Join(sysBus, entry.Name())
+		dest, err := os.Readlink(devPath)
+		if err != nil {
+			continue
+		}
+		fileSpecs = append(fileSpecs, devPath)
+		devData := filepath.Clean(filepath.Join(sysBus, dest))
+		for _, subEntry := range subEntries {
+			fileSpecs = append(fileSpecs, filepath.Join(devData, subEntry))
+		}
+	}
+	return fileSpecs
+}
@@ -27,6 +27,10 @@ func ExpectedCloneNetContent() []string {
 	return []string{}
 }
 
+func ExpectedCloneNetAddressingContent() []string {
+	return []string{}
+}
+
 func ExpectedClonePCIContent(ctx context.Context) ([]string, error) {
 	return nil, nil
 }
@@ -34,3 +38,15 @@ func ExpectedClonePCIContent(ctx context.Context) ([]string, error) {
 func ExpectedCloneUSBContent() []string {
 	return []string{}
 }
+
+func ExpectedClonePMEMContent() []string {
+	return []string{}
+}
+
+func ExpectedCloneNVMeContent() []string {
+	return []string{}
+}
+
+func ExpectedCloneSCSIContent() []string {
+	return []string{}
+}
@@ -94,9 +94,94 @@ type Node struct {
 	Caches    []*memory.Cache      `json:"caches"`
 	Distances []int                `json:"distances"`
 	Memory    *memory.Area         `json:"memory"`
+	// MemoryStats contains the current memory usage of the node, or nil if
+	// unknown
+	MemoryStats *NodeMemoryStats `json:"memory_stats,omitempty"`
+	// NUMAStats contains the node's NUMA allocation counters, or nil if
+	// unknown
+	NUMAStats *NodeNUMAStats `json:"numa_stats,omitempty"`
+	// MemoryOnly is true for nodes having memory but no processors, like
+	// the nodes of CXL memory expanders, of persistent memory used as system
+	// RAM or of high-bandwidth memory (HBM)
+	MemoryOnly bool `json:"memory_only"`
+	// MemoryTier is the ID of the memory tier the node's memory belongs to,
+	// or -1 if unknown, e.g. when the kernel does not support memory tiering
+	// or on Windows. Lower tiers are faster.
+	MemoryTier int `json:"memory_tier"`
+	// MemoryAccess contains the performance of accesses to the node's memory
+	// as reported by the firmware's Heterogeneous Memory Attribute Table
+	// (HMAT), one entry per access class
+	MemoryAccess []*NodeMemoryAccess `json:"memory_access,omitempty"`
+}
+
+// NodeMemoryStats describes the memory usage of a node, in bytes
+type NodeMemoryStats struct {
+	TotalBytes  uint64 `json:"total_bytes"`
+	FreeBytes   uint64 `json:"free_bytes"`
+	UsedBytes   uint64 `json:"used_bytes"`
+	ActiveBytes uint64 `json:"active_bytes"`
+	// InactiveBytes is memory which has not been used recently and is a
+	// candidate for reclaim
+	InactiveBytes uint64 `json:"inactive_bytes"`
+	// FileBytes is the page cache
+	FileBytes uint64 `json:"file_bytes"`
+	// AnonBytes is memory not backed by a file, e.g. process heaps
+	AnonBytes  uint64 `json:"anon_bytes"`
+	ShmemBytes uint64 `json:"shmem_bytes"`
+	DirtyBytes uint64 `json:"dirty_bytes"`
+	// SlabBytes is memory used by the kernel's slab allocator, the sum of
+	// SlabReclaimableBytes and SlabUnreclaimableBytes
+	SlabBytes              uint64 `json:"slab_bytes"`
+	SlabReclaimableBytes   uint64 `json:"slab_reclaimable_bytes"`
+	SlabUnreclaimableBytes uint64 `json:"slab_unreclaimable_bytes"`
+}
+
+// NodeNUMAStats contains the page allocation counters of a node, as found in
+// numastat
+type NodeNUMAStats struct {
+	// Hit is the number of pages allocated on the node as intended
+	Hit uint64 `json:"numa_hit"`
+	// Miss is the number of pages allocated on the node although another
+	// node was preferred
+	Miss uint64 `json:"numa_miss"`
+	// Foreign is the number of pages intended for the node but allocated on
+	// another node
+	Foreign       uint64 `json:"numa_foreign"`
+	InterleaveHit uint64 `json:"interleave_hit"`
+	// LocalNode is the number of pages allocated on the node while a process
+	// was running on it
+	LocalNode uint64 `json:"local_node"`
+	// OtherNode is the number of pages allocated on the node while a process
+	// was running on another node
+	OtherNode uint64 `json:"other_node"`
+}
+
+// NodeMemoryAccess describes the performance of accesses to a node's memory
+// from its best performing initiators, i.e. the nodes whose processors or
+// devices access the memory
+type NodeMemoryAccess struct {
+	// Class is the access class: 0 considers all initiators, 1 only those
+	// having processors
+	Class            int   `json:"class"`
+	InitiatorNodeIDs []int `json:"initiator_node_ids"`
+	// Bandwidths are in MB/s and latencies in nanoseconds. 0 means unknown.
+	ReadBandwidthMBps  uint64 `json:"read_bandwidth_mbps"`
+	WriteBandwidthMBps uint64 `json:"write_bandwidth_mbps"`
+	ReadLatencyNs      uint64 `json:"read_latency_ns"`
+	WriteLatencyNs     uint64 `json:"write_latency_ns"`
+}
+
+// MemoryTier groups the nodes whose memory has similar performance. Lower
+// tiers are faster; DRAM is usually in tier 4.
+type MemoryTier struct {
+	ID      int   `json:"id"`
+	NodeIDs []int `json:"node_ids"`
 }
 
 func (n *Node) String() string {
+	if n.MemoryOnly {
+		return fmt.Sprintf("node #%d (memory only)", n.ID)
+	}
 	return fmt.Sprintf(
 		"node #%d (%d cores)",
 		n.ID,
@@ -108,6 +193,13 @@ func (n *Node) String() string {
 type Info struct {
 	Architecture Architecture `json:"architecture"`
 	Nodes        []*Node      `json:"nodes"`
+	// Caches is the deduplicated list of all memory caches on the host. A
+	// cache shared by the logical processors of several nodes appears in the
+	// Caches of each of those nodes but only once here.
+	Caches []*memory.Cache `json:"caches,omitempty"`
+	// MemoryTiers contains the memory tiers of the host, ordered from the
+	// fastest to the slowest, when the kernel supports memory tiering
+	MemoryTiers []*MemoryTier `json:"memory_tiers,omitempty"`
 }
 
 // New returns a pointer to an Info struct that contains information about the
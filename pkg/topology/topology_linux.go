@@ -26,6 +26,13 @@ func (i *Info) load(ctx context.Context) error {
 	} else {
 		i.Architecture = ArchitectureNUMA
 	}
+	i.MemoryTiers = memoryTiers(linuxpath.New(ctx))
+	setNodeMemoryTiers(i.Nodes, i.MemoryTiers)
+	caches, err := memory.Caches(ctx)
+	if err != nil {
+		log.Warn(ctx, "failed to determine host caches: %s\n", err)
+	}
+	i.Caches = caches
 	return nil
 }
 
@@ -76,6 +83,10 @@ func topologyNodes(ctx context.Context) []*Node {
 			return nodes
 		}
 		node.Memory = area
+		node.MemoryStats = nodeMemoryStats(paths, nodeID)
+		node.NUMAStats = nodeNUMAStats(paths, nodeID)
+		node.MemoryAccess = nodeMemoryAccess(paths, nodeID)
+		node.MemoryOnly = len(cores) == 0 && area.TotalUsableBytes > 0
 
 		nodes = append(nodes, node)
 	}
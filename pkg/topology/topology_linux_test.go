@@ -8,10 +8,13 @@ package topology_test
 
 import (
 	"encoding/json"
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/internal/testutil"
 	"github.com/jaypipes/ghw/pkg/memory"
 	"github.com/jaypipes/ghw/pkg/snapshot"
 	"github.com/jaypipes/ghw/pkg/topology"
@@ -151,3 +154,149 @@ func TestTopologyPerNUMAMemory(t *testing.T) {
 		}
 	}
 }
+
+func TestTopologyHostCaches(t *testing.T) {
+	testdataPath, err := testdata.SnapshotsDirectory()
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+
+	multiNumaSnapshot := filepath.Join(testdataPath, "linux-amd64-intel-xeon-L5640.tar.gz")
+	unpackDir := t.TempDir()
+	err = snapshot.UnpackInto(multiNumaSnapshot, unpackDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := topology.New(ghw.WithChroot(unpackDir))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+
+	// Each of the two Xeon L5640 packages has its own L3 cache
+	l3s := []*memory.Cache{}
+	for _, c := range info.Caches {
+		if c.Level == 3 {
+			l3s = append(l3s, c)
+		}
+	}
+	if len(l3s) != 2 {
+		t.Fatalf("Expected 2 L3 caches, but got %d", len(l3s))
+	}
+	for _, c := range l3s {
+		if len(c.NodeIDs) != 1 {
+			t.Errorf("Expected L3 cache to be shared by a single node, but got %v", c.NodeIDs)
+		}
+		if len(c.LogicalProcessors) != 12 {
+			t.Errorf("Expected L3 cache to be shared by 12 logical processors, but got %v", c.LogicalProcessors)
+		}
+	}
+	if l3s[0].NodeIDs[0] == l3s[1].NodeIDs[0] {
+		t.Errorf("Expected L3 caches in different nodes, but got %v", l3s[0].NodeIDs)
+	}
+}
+
+// TestTopologyMemoryTiers uses a host with a DRAM node having a processor and
+// a CPU-less CXL memory expander node
+func TestTopologyMemoryTiers(t *testing.T) {
+	root := t.TempDir()
+	node0 := "sys/devices/system/node/node0/"
+	node1 := "sys/devices/system/node/node1/"
+	testutil.WriteFiles(t, root, map[string]string{
+		"proc/meminfo": "MemTotal:       65536000 kB\nHugetlb:               0 kB\nHugepagesize:       2048 kB\n",
+		node0 + "meminfo": "Node 0 MemTotal:       32768000 kB\n" +
+			"Node 0 MemFree:        16384000 kB\n" +
+			"Node 0 MemUsed:        16384000 kB\n" +
+			"Node 0 FilePages:       8192000 kB\n" +
+			"Node 0 AnonPages:       4096000 kB\n" +
+			"Node 0 Slab:            1024000 kB\n" +
+			"Node 0 SReclaimable:     768000 kB\n" +
+			"Node 0 SUnreclaim:       256000 kB\n" +
+			"Node 0 HugePages_Total:     0\n",
+		node0 + "numastat": "numa_hit 1000\nnuma_miss 10\nnuma_foreign 20\n" +
+			"interleave_hit 5\nlocal_node 990\nother_node 30\n",
+		node0 + "distance":                                          "10 20",
+		node0 + "cpu0/topology/core_id":                             "0",
+		node0 + "access0/initiators/read_bandwidth":                 "204800",
+		node0 + "access0/initiators/write_bandwidth":                "204800",
+		node0 + "access0/initiators/read_latency":                   "80",
+		node0 + "access0/initiators/write_latency":                  "80",
+		node1 + "meminfo":                                           "Node 1 MemTotal:       32768000 kB\nNode 1 MemFree:        32768000 kB\n",
+		node1 + "distance":                                          "20 10",
+		node1 + "access0/initiators/read_bandwidth":                 "25600",
+		node1 + "access0/initiators/write_bandwidth":                "25600",
+		node1 + "access0/initiators/read_latency":                   "250",
+		node1 + "access0/initiators/write_latency":                  "300",
+		node1 + "access1/initiators/read_latency":                   "260",
+		"sys/devices/virtual/memory_tiering/memory_tier4/nodelist":  "0\n",
+		"sys/devices/virtual/memory_tiering/memory_tier22/nodelist": "1\n",
+	})
+	for _, node := range []string{node0, node1} {
+		if err := os.MkdirAll(filepath.Join(root, node, "hugepages"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, link := range []string{node0 + "access0/initiators/node0", node1 + "access0/initiators/node0", node1 + "access1/initiators/node0"} {
+		if err := os.Symlink("../../../node0", filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := topology.New(ghw.WithChroot(root))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, but got %d", len(info.Nodes))
+	}
+	dram, cxl := info.Nodes[0], info.Nodes[1]
+
+	if dram.MemoryOnly || !cxl.MemoryOnly {
+		t.Errorf("Expected only node 1 to be memory-only, but got %v and %v", dram.MemoryOnly, cxl.MemoryOnly)
+	}
+	if dram.MemoryTier != 4 || cxl.MemoryTier != 22 {
+		t.Errorf("Expected memory tiers 4 and 22, but got %d and %d", dram.MemoryTier, cxl.MemoryTier)
+	}
+	if len(info.MemoryTiers) != 2 || info.MemoryTiers[0].ID != 4 {
+		t.Errorf("Expected memory tiers ordered by ID, but got %+v", info.MemoryTiers)
+	}
+
+	wantStats := &topology.NodeMemoryStats{
+		TotalBytes:             32768000 * 1024,
+		FreeBytes:              16384000 * 1024,
+		UsedBytes:              16384000 * 1024,
+		FileBytes:              8192000 * 1024,
+		AnonBytes:              4096000 * 1024,
+		SlabBytes:              1024000 * 1024,
+		SlabReclaimableBytes:   768000 * 1024,
+		SlabUnreclaimableBytes: 256000 * 1024,
+	}
+	if !reflect.DeepEqual(dram.MemoryStats, wantStats) {
+		t.Errorf("Expected memory stats %+v, but got %+v", wantStats, dram.MemoryStats)
+	}
+	wantNUMA := &topology.NodeNUMAStats{Hit: 1000, Miss: 10, Foreign: 20, InterleaveHit: 5, LocalNode: 990, OtherNode: 30}
+	if !reflect.DeepEqual(dram.NUMAStats, wantNUMA) {
+		t.Errorf("Expected NUMA stats %+v, but got %+v", wantNUMA, dram.NUMAStats)
+	}
+	if cxl.NUMAStats != nil {
+		t.Errorf("Expected nil NUMA stats, but got %+v", cxl.NUMAStats)
+	}
+
+	if len(cxl.MemoryAccess) != 2 {
+		t.Fatalf("Expected 2 access classes, but got %d", len(cxl.MemoryAccess))
+	}
+	wantAccess := &topology.NodeMemoryAccess{
+		Class:              0,
+		InitiatorNodeIDs:   []int{0},
+		ReadBandwidthMBps:  25600,
+		WriteBandwidthMBps: 25600,
+		ReadLatencyNs:      250,
+		WriteLatencyNs:     300,
+	}
+	if !reflect.DeepEqual(cxl.MemoryAccess[0], wantAccess) {
+		t.Errorf("Expected memory access %+v, but got %+v", wantAccess, cxl.MemoryAccess[0])
+	}
+	if cxl.MemoryAccess[1].Class != 1 || cxl.MemoryAccess[1].ReadLatencyNs != 260 {
+		t.Errorf("unexpected memory access: %+v", cxl.MemoryAccess[1])
+	}
+}
@@ -0,0 +1,205 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package topology
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/linuxpath"
+	"github.com/jaypipes/ghw/pkg/unitutil"
+	"github.com/jaypipes/ghw/pkg/util"
+)
+
+// nodeMemoryStats returns the memory usage of a node, read from
+// /sys/devices/system/node/nodeX/meminfo, whose lines look like:
+//
+//	Node 0 MemTotal:       32706328 kB
+//	Node 0 HugePages_Total:     0
+func nodeMemoryStats(paths *linuxpath.Paths, nodeID int) *NodeMemoryStats {
+	path := filepath.Join(paths.SysDevicesSystemNode, fmt.Sprintf("node%d", nodeID), "meminfo")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer util.SafeClose(f)
+
+	stats := &NodeMemoryStats{}
+	fields := map[string]*uint64{
+		"MemTotal":     &stats.TotalBytes,
+		"MemFree":      &stats.FreeBytes,
+		"MemUsed":      &stats.UsedBytes,
+		"Active":       &stats.ActiveBytes,
+		"Inactive":     &stats.InactiveBytes,
+		"FilePages":    &stats.FileBytes,
+		"AnonPages":    &stats.AnonBytes,
+		"Shmem":        &stats.ShmemBytes,
+		"Dirty":        &stats.DirtyBytes,
+		"Slab":         &stats.SlabBytes,
+		"SReclaimable": &stats.SlabReclaimableBytes,
+		"SUnreclaim":   &stats.SlabUnreclaimableBytes,
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 5 || parts[4] != "kB" {
+			continue
+		}
+		dest, ok := fields[strings.TrimSuffix(parts[2], ":")]
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(parts[3], 10, 64); err == nil {
+			*dest = v * uint64(unitutil.KB)
+		}
+	}
+	return stats
+}
+
+// nodeNUMAStats returns the page allocation counters of a node, read from
+// /sys/devices/system/node/nodeX/numastat, whose lines look like:
+//
+//	numa_hit 1093458372
+func nodeNUMAStats(paths *linuxpath.Paths, nodeID int) *NodeNUMAStats {
+	path := filepath.Join(paths.SysDevicesSystemNode, fmt.Sprintf("node%d", nodeID), "numastat")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer util.SafeClose(f)
+
+	stats := &NodeNUMAStats{}
+	fields := map[string]*uint64{
+		"numa_hit":       &stats.Hit,
+		"numa_miss":      &stats.Miss,
+		"numa_foreign":   &stats.Foreign,
+		"interleave_hit": &stats.InterleaveHit,
+		"local_node":     &stats.LocalNode,
+		"other_node":     &stats.OtherNode,
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 2 {
+			continue
+		}
+		if dest, ok := fields[parts[0]]; ok {
+			*dest, _ = strconv.ParseUint(parts[1], 10, 64)
+		}
+	}
+	return stats
+}
+
+// nodeMemoryAccess returns the HMAT performance attributes of a node's
+// memory. For each access class N, the
+// /sys/devices/system/node/nodeX/accessN/initiators directory contains links
+// to the best performing initiator nodes along with the read_bandwidth,
+// write_bandwidth (MB/s), read_latency and write_latency (ns) files.
+func nodeMemoryAccess(paths *linuxpath.Paths, nodeID int) []*NodeMemoryAccess {
+	nodePath := filepath.Join(paths.SysDevicesSystemNode, fmt.Sprintf("node%d", nodeID))
+	files, err := os.ReadDir(nodePath)
+	if err != nil {
+		return nil
+	}
+	var accesses []*NodeMemoryAccess
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "access") {
+			continue
+		}
+		class, err := strconv.Atoi(file.Name()[6:])
+		if err != nil {
+			continue
+		}
+		initPath := filepath.Join(nodePath, file.Name(), "initiators")
+		entries, err := os.ReadDir(initPath)
+		if err != nil {
+			continue
+		}
+		access := &NodeMemoryAccess{
+			Class:            class,
+			InitiatorNodeIDs: []int{},
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), "node") {
+				continue
+			}
+			if id, err := strconv.Atoi(entry.Name()[4:]); err == nil {
+				access.InitiatorNodeIDs = append(access.InitiatorNodeIDs, id)
+			}
+		}
+		sort.Ints(access.InitiatorNodeIDs)
+		for name, dest := range map[string]*uint64{
+			"read_bandwidth":  &access.ReadBandwidthMBps,
+			"write_bandwidth": &access.WriteBandwidthMBps,
+			"read_latency":    &access.ReadLatencyNs,
+			"write_latency":   &access.WriteLatencyNs,
+		} {
+			if data, err := os.ReadFile(filepath.Join(initPath, name)); err == nil {
+				*dest, _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+			}
+		}
+		accesses = append(accesses, access)
+	}
+	sort.Slice(accesses, func(i, j int) bool {
+		return accesses[i].Class < accesses[j].Class
+	})
+	return accesses
+}
+
+// memoryTiers returns the memory tiers found in
+// /sys/devices/virtual/memory_tiering. Each memory_tierN directory, where N is
+// the tier ID, contains a nodelist file listing the tier's nodes.
+func memoryTiers(paths *linuxpath.Paths) []*MemoryTier {
+	files, err := os.ReadDir(paths.SysDevicesVirtualMemoryTiering)
+	if err != nil {
+		return nil
+	}
+	var tiers []*MemoryTier
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "memory_tier") {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(file.Name(), "memory_tier"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(paths.SysDevicesVirtualMemoryTiering, file.Name(), "nodelist"))
+		if err != nil {
+			continue
+		}
+		nodeIDs, err := util.ParseCPUList(string(data))
+		if err != nil {
+			continue
+		}
+		tiers = append(tiers, &MemoryTier{ID: id, NodeIDs: nodeIDs})
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].ID < tiers[j].ID
+	})
+	return tiers
+}
+
+// setNodeMemoryTiers sets the memory tier of each node, or -1 for nodes in no
+// tier
+func setNodeMemoryTiers(nodes []*Node, tiers []*MemoryTier) {
+	tierByNode := map[int]int{}
+	for _, tier := range tiers {
+		for _, nodeID := range tier.NodeIDs {
+			tierByNode[nodeID] = tier.ID
+		}
+	}
+	for _, node := range nodes {
+		node.MemoryTier = -1
+		if id, ok := tierByNode[node.ID]; ok {
+			node.MemoryTier = id
+		}
+	}
+}
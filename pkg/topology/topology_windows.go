@@ -49,7 +49,8 @@ func topologyNodes() ([]*Node, error) {
 		switch lpi.relationship {
 		case relationNUMANode:
 			nodes = append(nodes, &Node{
-				ID: lpi.numaNodeID(),
+				ID:         lpi.numaNodeID(),
+				MemoryTier: -1,
 			})
 		case relationProcessorCore:
 			// TODO(jaypipes): associated LP to processor core
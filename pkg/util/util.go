@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -58,6 +59,26 @@ func ConcatStrings(items ...string) string {
 	return strings.Join(items, "")
 }
 
+// StringOrUnknown returns the supplied string, or UNKNOWN if it is empty,
+// e.g. for an SMBIOS string the firmware left unset
+func StringOrUnknown(s string) string {
+	if s == "" {
+		return UNKNOWN
+	}
+	return s
+}
+
+// CounterDelta returns the increase of a counter the kernel keeps between two
+// samples, or zero if the counter went backwards, e.g. because it wrapped
+// around or the device was removed and added again in between. All the rates
+// ghw computes from two samples of counters follow this rule.
+func CounterDelta(prev uint64, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
 // Convert strings to bool using strconv.ParseBool() when recognized, otherwise
 // use map lookup to convert strings like "Yes" "No" "On" "Off" to bool
 // `ethtool` uses on, off, yes, no (upper and lower case) rather than true and
@@ -83,3 +104,38 @@ func ParseBool(str string) (bool, error) {
 		}
 	}
 }
+
+// ParseCPUList parses a list of CPU (or NUMA node) IDs in the "list format"
+// the kernel prints in sysfs and cgroupfs files such as
+// /sys/devices/system/cpu/online or cpuset.cpus, e.g. "0-3,8,10-11", and
+// returns the sorted IDs. An empty or whitespace-only string is a valid,
+// empty list.
+func ParseCPUList(list string) ([]int, error) {
+	out := []int{}
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return out, nil
+	}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		startStr, endStr, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(startStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(endStr); err != nil {
+				return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid range %q in cpu list %q", part, list)
+			}
+		}
+		for id := start; id <= end; id++ {
+			out = append(out, id)
+		}
+	}
+	sort.Ints(out)
+	return out, nil
+}
@@ -7,6 +7,7 @@
 package util_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/jaypipes/ghw/pkg/util"
@@ -56,6 +57,25 @@ func TestConcatStrings(t *testing.T) {
 	}
 }
 
+func TestStringOrUnknown(t *testing.T) {
+	if got := util.StringOrUnknown(""); got != util.UNKNOWN {
+		t.Errorf("expected %q got %q", util.UNKNOWN, got)
+	}
+	if got := util.StringOrUnknown("Dell Inc."); got != "Dell Inc." {
+		t.Errorf("expected %q got %q", "Dell Inc.", got)
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	if got := util.CounterDelta(100, 150); got != 50 {
+		t.Errorf("expected %d got %d", 50, got)
+	}
+	// a counter which went backwards, e.g. reset, counts as zero
+	if got := util.CounterDelta(150, 20); got != 0 {
+		t.Errorf("expected %d got %d", 0, got)
+	}
+}
+
 func TestParseBool(t *testing.T) {
 	type testCase struct {
 		item     string
@@ -109,3 +129,38 @@ func TestParseBool(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCPUList(t *testing.T) {
+	testCases := []struct {
+		list     string
+		expected []int
+		err      bool
+	}{
+		{list: "", expected: []int{}},
+		{list: "\n", expected: []int{}},
+		{list: "0", expected: []int{0}},
+		{list: "0-3\n", expected: []int{0, 1, 2, 3}},
+		{list: "8,0-2,10-11", expected: []int{0, 1, 2, 8, 10, 11}},
+		{list: "3-1", err: true},
+		{list: "a-b", err: true},
+		{list: "0-15:2/4", err: true},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.list, func(t *testing.T) {
+			got, err := util.ParseCPUList(tCase.list)
+			if tCase.err {
+				if err == nil {
+					t.Fatalf("expected error parsing %q, got %v", tCase.list, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tCase.expected) {
+				t.Errorf("expected %v got %v", tCase.expected, got)
+			}
+		})
+	}
+}